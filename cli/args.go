@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -8,12 +9,17 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/pflag"
 
 	"github.com/chaimleib/hebcalfmt/config"
 	"github.com/chaimleib/hebcalfmt/daterange"
+	"github.com/chaimleib/hebcalfmt/fsys"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/warning"
 )
 
 var (
@@ -41,29 +47,41 @@ func NewFlags() *pflag.FlagSet {
 	fs.Bool("version", false,
 		"show version number")
 	fs.StringP("config", "c", "",
-		"select a JSON config file (default $HOME/.config/hebcalfmt/config.json)")
+		"select a JSON, YAML or TOML config file (default $HOME/.config/hebcalfmt/config.json)")
 	fs.StringP(
 		"info",
 		"i",
 		"",
-		"show data from the internal databases or compiled values. Available options: cities, default-city, languages",
+		"show data from the internal databases or compiled values. Available options: "+
+			strings.Join(InfoKeys, ", "),
 	)
+	fs.String("format", "",
+		"print events in a built-in format instead of executing a template. Available options: "+
+			strings.Join(Formats, ", "))
+	fs.StringP("expr", "e", "",
+		"run this template text instead of a template file, like -e '{{hdateFromTime $.now}}'")
+	fs.StringArray("param", nil,
+		"set a parameter declared by the template, like --param city=Jerusalem (repeatable)")
+	fs.Duration("ics-alarm", templating.DefaultCandleAlarm,
+		"with --format ics, how long before candle-lighting to set a reminder (0 disables)")
+	AddConfigFlags(fs)
 
 	return fs
 }
 
 // processFlags produces a [config.Config],
 // using just the hyphenated options and flags in args.
-// NOTE: Other args like the template file path and the date range spec
-// are processed by processArgs.
+// It also returns the template to run; see [getTemplateSource].
+// NOTE: The date range spec in the other args is processed separately,
+// by processDateRangeArgs.
 func processFlags(
 	files fs.FS,
 	flagSet *pflag.FlagSet,
 	args []string,
 	w io.Writer,
-) (*config.Config, error) {
+) (*config.Config, *templateSource, error) {
 	if err := flagSet.Parse(args); err != nil {
-		return nil, fmt.Errorf("%w: %w", ErrUsage, err)
+		return nil, nil, fmt.Errorf("%w: %w", ErrUsage, err)
 	}
 
 	// pflag would return an error if
@@ -76,49 +94,137 @@ func processFlags(
 	help, err := flagSet.GetBool("help")
 	if err != nil {
 		slog.Error("failed to get --help flag", "error", err)
-		return nil, fmt.
+		return nil, nil, fmt.
 			Errorf("%w: get --help: %w", ErrUnreachable, err)
 	}
+	src, err := getTemplateSource(files, flagSet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if help && src != nil {
+		text, err := templateHelp(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		fmt.Fprintln(w, text)
+		return nil, nil, ErrDone
+	}
 	if help {
 		fmt.Fprintln(w, usage(flagSet.FlagUsages()))
-		return nil, ErrDone
+		return nil, nil, ErrDone
 	}
 
 	version, err := flagSet.GetBool("version")
 	if err != nil {
 		slog.Error("failed to get --version flag", "error", err)
-		return nil, fmt.Errorf("%w: get --version: %w", ErrUnreachable, err)
+		return nil, nil, fmt.Errorf("%w: get --version: %w", ErrUnreachable, err)
 	}
 	if version {
 		fmt.Fprintln(w, versionMessage())
-		return nil, ErrDone
+		return nil, nil, ErrDone
 	}
 
 	key, err := flagSet.GetString("info")
 	if err != nil {
 		slog.Error("failed to get --info option", "error", err)
-		return nil, fmt.Errorf("%w: get --info: %w", ErrUnreachable, err)
+		return nil, nil, fmt.Errorf("%w: get --info: %w", ErrUnreachable, err)
 	}
 	if key != "" {
 		info, err := infoString(key)
 		if err != nil {
 			log.Println(usage(flagSet.FlagUsages()))
-			return nil, err
+			return nil, nil, err
 		}
 		fmt.Fprintln(w, info)
-		return nil, ErrDone
+		return nil, nil, ErrDone
+	}
+
+	cfg, err := loadConfigFromFlags(files, flagSet, src, projectDir(flagSet, src))
+	if err != nil {
+		return nil, nil, err
 	}
+	return cfg, src, nil
+}
 
-	return loadConfigFromFlags(files, flagSet)
+// projectDir returns the directory to look for [ProjectConfigName] in:
+// the directory of the template file, or else the working directory
+// for inline templates, or if a --format was requested instead.
+// Named templates, like @today, have no project directory.
+func projectDir(flagSet *pflag.FlagSet, src *templateSource) string {
+	switch {
+	case src == nil:
+		if f := flagSet.Lookup("format"); f != nil && f.Value.String() != "" {
+			return "."
+		}
+		return ""
+	case src.Inline:
+		return "."
+	case strings.HasPrefix(src.Arg, TemplatePrefix):
+		return ""
+	}
+	return filepath.Dir(src.Arg)
 }
 
-// loadConfigFromFlags reads the --config flag option
-// and loads the config file specified.
-// Otherwise, it loads the default config.
+// SystemConfigPath is the site-wide config file,
+// which is the lowest-precedence layer of config.
+// It may be replaced for testing or packaging.
+var SystemConfigPath = "/etc/hebcalfmt/config.json"
+
+// ProjectConfigName is the name of the per-project config file,
+// which is looked for next to the template.
+const ProjectConfigName = "hebcalfmt.json"
+
+// findConfigFile returns fpath if it exists,
+// or else the first existing file named like fpath
+// with one of [config.Extensions] in place of its own.
+// For example, config.yaml is found in place of config.json.
+// If none of them exist, fpath is returned.
+func findConfigFile(files fs.FS, fpath string) string {
+	base := strings.TrimSuffix(fpath, filepath.Ext(fpath))
+	for _, candidate := range append([]string{fpath}, config.Extensions...) {
+		if candidate != fpath {
+			candidate = base + candidate
+		}
+		if _, err := fs.Stat(files, candidate); err == nil {
+			return candidate
+		}
+	}
+	return fpath
+}
+
+// loadConfigFromFlags builds the config from its layers,
+// from lowest to highest precedence:
+//
+//  1. [config.Default]
+//  2. the system config file at [SystemConfigPath]
+//  3. the config defaults in the front matter of the template src,
+//     see [mergeTemplateConfig]
+//  4. the file from the --config flag,
+//     or else the user config file at [DefaultConfigPath]
+//  5. the project config file, [ProjectConfigName] in projectDir
+//  6. the selected profile and the profiles it extends,
+//     see [applyProfile]
+//  7. environment variables starting with [config.EnvPrefix]
+//  8. the config override flags, see [AddConfigFlags]
+//
+// The optional files may also be in another [config.Format];
+// see [findConfigFile].
+// Paths to secondary files, like `events_file`,
+// are relative to the layer which set them.
+// Only the --config file must exist; the other files are optional.
+// If src is nil, there are no template defaults,
+// and if projectDir is empty, no project file is loaded.
 // Then it calls Normalize on the result.
+//
+// Unknown keys in the config files, out-of-range values and
+// conflicting settings in the result are logged as warnings.
+// See [config.ValidateKeys] and [config.Config.Check].
 func loadConfigFromFlags(
 	files fs.FS,
 	flagSet *pflag.FlagSet,
+	src *templateSource,
+	projectDir string,
 ) (*config.Config, error) {
 	fpath, err := flagSet.GetString("config")
 	if err != nil {
@@ -126,17 +232,69 @@ func loadConfigFromFlags(
 		return nil, fmt.Errorf("%w: get --config: %w", ErrUnreachable, err)
 	}
 
-	cfg, err := loadConfigOrDefault(files, fpath)
+	var warns warning.Warnings
+	cfg := config.Default
+	if err := mergeOptionalConfig(&cfg, files, SystemConfigPath, &warns); err != nil {
+		return nil, err
+	}
+
+	if src != nil {
+		if err := mergeTemplateConfig(&cfg, src); err != nil {
+			return nil, err
+		}
+	}
+
+	if fpath != "" {
+		if err := cfg.MergeFile(files, fpath); err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		warns = append(warns, configFileWarnings(files, fpath)...)
+	} else if err := mergeOptionalConfig(&cfg, files, DefaultConfigPath(), &warns); err != nil {
+		return nil, err
+	}
+
+	if projectDir != "" {
+		projectPath := filepath.Join(projectDir, ProjectConfigName)
+		if err := mergeOptionalConfig(&cfg, files, projectPath, &warns); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyProfile(&cfg, flagSet); err != nil {
+		return nil, err
+	}
+
+	envWarns, err := cfg.MergeEnv(os.Environ())
 	if err != nil {
 		return nil, err
 	}
+	warns = append(warns, envWarns...)
 
-	cfg, err = cfg.Normalize()
+	if err := applyConfigFlags(&cfg, flagSet); err != nil {
+		return nil, err
+	}
+
+	// Secondary files named outside of config files
+	// are relative to the working directory.
+	cfg.SetLayerFS(config.SourceEnv, files)
+	cfg.SetLayerFS(config.SourceFlags, files)
+
+	normalized, err := cfg.Normalize()
 	if err != nil {
 		return nil, err
 	}
 
-	return cfg, nil
+	// Unknown values, like an unknown city, are reported as errors later.
+	for _, warn := range normalized.Check() {
+		if errors.Is(warn, config.ErrOutOfRange) || errors.Is(warn, config.ErrConflict) {
+			warns.Append(warn)
+		}
+	}
+	if warn := warns.Build(); warn != nil {
+		log.Println(warn)
+	}
+
+	return normalized, nil
 }
 
 func DefaultConfigPath() string {
@@ -147,70 +305,92 @@ func DefaultConfigPath() string {
 	return filepath.Join(home, ".config", ProgName, "config.json")
 }
 
-// loadConfigOrDefault reads fpath if not empty.
-// It will error if fpath does not exist.
-//
-// Otherwise, if fpath is empty and HOME is set,
-// default to loading from ~/.config/hebcalfmt/config.json.
-//
-// Otherwise return config.Default with no error.
+// UserTemplateDir is the directory of the user's own templates,
+// next to the file at [DefaultConfigPath].
+// Templates may import files from it; see [templating.ImportFuncs].
+func UserTemplateDir() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return ""
+	}
+	return filepath.Join(home, ".config", ProgName, "templates")
+}
+
+// mergeOptionalConfig merges the config file at fpath into cfg,
+// if fpath is not empty and the file exists.
+// Problems with its keys are added to warns.
 //
 // Paths of secondary files referenced inside the config file
 // will be resolved relative to the [filepath.Dir] of the config file itself.
-// While the current [config.Default] does not reference other files,
-// if that is returned, the FS will resolve relative paths
-// using the default behavior of the FS.
-// Typically that means relative to the current working directory.
-func loadConfigOrDefault(files fs.FS, fpath string) (*config.Config, error) {
-	var suppressMissingConfigErr bool
-
-	// Try to configure a default configPath if one was not provided
+// See [config.Config.MergeFile].
+func mergeOptionalConfig(
+	cfg *config.Config,
+	files fs.FS,
+	fpath string,
+	warns *warning.Warnings,
+) error {
 	if fpath == "" {
-		if defaultConfigPath := DefaultConfigPath(); defaultConfigPath != "" {
-			suppressMissingConfigErr = true
-			fpath = defaultConfigPath
-		} else {
-			defaultCfg := config.Default
-			return &defaultCfg, nil
-		}
+		return nil
+	}
+	fpath = findConfigFile(files, fpath)
+	err := cfg.MergeFile(files, fpath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
 	}
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	*warns = append(*warns, configFileWarnings(files, fpath)...)
+	return nil
+}
 
-	cfg, err := config.FromFile(files, fpath)
-	if suppressMissingConfigErr && errors.Is(err, os.ErrNotExist) {
-		defaultCfg := config.Default
-		return &defaultCfg, nil
+// mergeTemplateConfig merges the config defaults
+// from the front matter of the template src into cfg,
+// as the layer named by src.Arg.
+// Paths to secondary files, like `events_file`,
+// are relative to the template.
+//
+// A missing template is not an error here;
+// it is reported when the template is parsed.
+func mergeTemplateConfig(cfg *config.Config, src *templateSource) error {
+	fm, err := src.frontMatter()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+	if len(fm.Config) == 0 {
+		return nil
 	}
 
-	return cfg, nil
+	if err := cfg.MergeJSON(bytes.NewReader(fm.Config), src.Arg); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.SetLayerFS(src.Arg, fsys.WrapFS{FS: src.Files, BaseDir: path.Dir(src.Path)})
+	return nil
 }
 
-// processArgs takes the `args` remaining after flags have been removed,
-// reads certain fields in `cfg`,
-// and returns the resulting template path.
-// It sets cfg.DateRange.
-//
-// If the is a problem with arguments provided,
-// a wrapped [ErrUsage] will be returned.
-//
-// The following fields are read from `cfg`:
-//   - IsHebrewYear
-//   - Now
-//   - Today
-func processArgs(
-	args []string,
-	cfg *config.Config,
-) (tmplPath string, err error) {
-	if len(args) == 0 {
-		return "", fmt.Errorf("%w: missing a template file argument", ErrUsage)
+// configFileWarnings reports the unknown keys and mistyped values
+// in the config file at fpath, which has already been merged successfully.
+func configFileWarnings(files fs.FS, fpath string) warning.Warnings {
+	data, err := fs.ReadFile(files, fpath)
+	if err != nil {
+		return warning.Warnings{err}
+	}
+	warns, err := config.ValidateKeys(data, fpath)
+	if err != nil {
+		return warning.Warnings{err}
 	}
-	tmplPath = args[0]
+	return warns
+}
 
-	dr, err := daterange.FromArgs(args[1:], cfg.IsHebrewYear, cfg.Now)
+// processDateRangeArgs parses the date range spec in `args`
+// and sets cfg.DateRange.
+func processDateRangeArgs(args []string, cfg *config.Config) error {
+	dr, err := daterange.FromArgs(args, cfg.IsHebrewYear, cfg.Now)
 	if err != nil {
-		return "", fmt.Errorf("%w: %w", ErrUsage, err)
+		return fmt.Errorf("%w: %w", ErrUsage, err)
 	}
 	cfg.DateRange = dr
 
@@ -218,5 +398,5 @@ func processArgs(
 		cfg.DateRange = daterange.FromTime(cfg.Now)
 	}
 
-	return tmplPath, nil
+	return nil
 }
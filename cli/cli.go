@@ -2,6 +2,7 @@ package cli
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
 	"log"
@@ -47,6 +48,7 @@ var InitLogging = func() {
 // Specifically, it configures logging settings,
 // pulls CLI arguments to parse from [os.Args],
 // ensures that filesystem requests get forwarded to [os.Open],
+// lets templates import files from the [UserTemplateDir],
 // sets the current time from [time.Now],
 // and sets the output writer to [os.Stdout].
 //
@@ -59,6 +61,9 @@ func Run() int {
 		slog.Error("failed to initialize DefaultFS", "error", err)
 		return 1
 	}
+	if dir := UserTemplateDir(); dir != "" {
+		templating.UserTemplates = fsys.WrapFS{FS: files, BaseDir: dir}
+	}
 
 	err = RunInEnvironment(
 		os.Args[1:], files, time.Now(), templating.BuildData, os.Stdout)
@@ -105,8 +110,24 @@ func RunInEnvironment(
 	) (*template.Template, map[string]any, error),
 	w io.Writer,
 ) error {
+	if len(args) != 0 && args[0] == "serve" {
+		return runServe(args[1:], files, buildData, w)
+	}
+	if len(args) != 0 && args[0] == "config" {
+		return runConfig(args[1:], files, w)
+	}
+	if len(args) != 0 && args[0] == "convert" {
+		return runConvert(args[1:], files, w)
+	}
+	if len(args) != 0 && args[0] == "repl" {
+		return runREPL(args[1:], files, now, w)
+	}
+	if len(args) != 0 && args[0] == "lint" {
+		return runLint(args[1:], files, w)
+	}
+
 	flagSet := NewFlags()
-	cfg, err := processFlags(files, flagSet, args, w)
+	cfg, src, err := processFlags(files, flagSet, args, w)
 	if err != nil {
 		if errors.Is(err, ErrDone) {
 			return nil
@@ -119,7 +140,35 @@ func RunInEnvironment(
 
 	cfg.Now = now
 
-	tmplPath, err := processArgs(flagSet.Args(), cfg)
+	format, err := getFormat(flagSet)
+	if err != nil {
+		if errors.Is(err, ErrUsage) {
+			log.Println(usage(flagSet.FlagUsages()))
+		}
+		return err
+	}
+	if format != "" {
+		if err := processDateRangeArgs(flagSet.Args(), cfg); err != nil {
+			if errors.Is(err, ErrUsage) {
+				log.Println(usage(flagSet.FlagUsages()))
+			}
+			return err
+		}
+		return runFormat(flagSet, cfg, format, w)
+	}
+
+	if src == nil {
+		log.Println(usage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: missing a template file argument", ErrUsage)
+	}
+	if err := processDateRangeArgs(src.DateArgs, cfg); err != nil {
+		if errors.Is(err, ErrUsage) {
+			log.Println(usage(flagSet.FlagUsages()))
+		}
+		return err
+	}
+
+	cfg.Params, err = getParams(flagSet)
 	if err != nil {
 		if errors.Is(err, ErrUsage) {
 			log.Println(usage(flagSet.FlagUsages()))
@@ -127,7 +176,13 @@ func RunInEnvironment(
 		return err
 	}
 
-	tmpl, tmplData, err := templating.BuildData(cfg, files, tmplPath)
+	var tmpl *template.Template
+	var tmplData map[string]any
+	if src.Inline {
+		tmpl, tmplData, err = templating.BuildDataText(cfg, src.Files, src.Path, src.Text)
+	} else {
+		tmpl, tmplData, err = templating.BuildData(cfg, src.Files, src.Path)
+	}
 	if err != nil {
 		return err
 	}
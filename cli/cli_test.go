@@ -2,6 +2,7 @@ package cli_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -102,14 +103,20 @@ func TestRunInEnvironment(t *testing.T) {
 		return &fstest.MapFile{Data: []byte(s)}
 	}
 	files := fstest.MapFS{
+		"candles.json":         fdata(`{"candle_lighting": true}`),
 		"date.tmpl":            fdata(`{{$.dateRange.StartOrToday false}}`),
 		"executeError.tmpl":    fdata(`{{printf $.tz "INVALID FORMAT"}}`),
 		"invalid.json":         fdata(`{INVALID JSON`),
 		"invalid.tmpl":         fdata(`{{INVALID`),
 		"invalidCity.json":     fdata(`{"city": "Invalid City"}`),
 		"invalidLanguage.json": fdata(`{"language": "Invalid Language"}`),
-		"stub.tmpl":            fdata(`ok`),
-		"today.json":           fdata(`{"today": true}`),
+		"overrides.tmpl": fdata(
+			`{{$.config.City}} {{$.config.IL}} {{$.config.CandleLighting}} ` +
+				`{{$.config.CandleLightingMins}} {{$.location.Name}} {{$.tz}}`,
+		),
+		"stub.tmpl":       fdata(`ok`),
+		"threeYears.json": fdata(`{"num_years": 3}`),
+		"today.json":      fdata(`{"today": true}`),
 	}
 
 	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
@@ -179,6 +186,10 @@ func TestRunInEnvironment(t *testing.T) {
 			Want:     "\n" + config.DefaultCity + "\n",
 			WantMode: test.WantContains,
 		},
+		{
+			Args: "--info config-schema",
+			Want: string(config.SchemaJSON),
+		},
 		{
 			Args:     "--info languages",
 			Want:     "\nashkenazi_standard\n",
@@ -242,6 +253,153 @@ To show the available languages, run
 			Args: "--config today.json date.tmpl",
 			Want: "1 Tevet 5786",
 		},
+		{
+			Args: "overrides.tmpl",
+			Want: " false false 18 New York America/New_York",
+		},
+		{
+			Args: "-C Jerusalem --il -b 40 overrides.tmpl",
+			Want: "Jerusalem true false 40 Jerusalem Asia/Jerusalem",
+		},
+		{
+			Args: "--city Jerusalem --il --candle-lighting-mins 40 overrides.tmpl",
+			Want: "Jerusalem true false 40 Jerusalem Asia/Jerusalem",
+		},
+		{
+			Args: "-l 31.778,35.235 -z Asia/Jerusalem -C Home overrides.tmpl",
+			Want: "Home false false 18 Home Asia/Jerusalem",
+		},
+		{
+			Args: "-c candles.json --set candle_lighting=false --set city=Phoenix overrides.tmpl",
+			Want: "Phoenix false false 18 Phoenix America/Phoenix",
+		},
+		{
+			Args: "-c candles.json --candle-lighting=false --set candle_lighting=true overrides.tmpl",
+			Want: " false true 18 New York America/New_York",
+		},
+		{
+			Args:        "--set INVALID=1 stub.tmpl",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: --set: unknown config key: "INVALID"`,
+		},
+		{
+			Args:        "--set city stub.tmpl",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: --set expects key=value, got "city"`,
+		},
+		{
+			Args:        "-b INVALID stub.tmpl",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: --candle-lighting-mins: invalid value for config key "candle_lighting_mins": strconv.Atoi: parsing "INVALID": invalid syntax`,
+		},
+		{
+			Args:        "-C INVALID stub.tmpl",
+			WantLog:     `unknown city: "INVALID"` + "\n",
+			WantLogMode: test.WantPrefix,
+			Err:         `failed to build hebcal options from flags: failed to resolve place configs: unknown city: "INVALID"`,
+		},
+		{
+			Args:        "--format INVALID",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: unrecognized key for --format flag: "INVALID"`,
+		},
+		{
+			Args:        "--format ics 3 2 INVALIDYEAR",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: invalid year: strconv.Atoi: parsing "INVALIDYEAR": invalid syntax`,
+		},
+		{
+			Args:        "--config invalidCity.json --format ics",
+			WantLog:     `unknown city: "Invalid City"` + "\n",
+			WantLogMode: test.WantPrefix,
+			Err:         `failed to build hebcal options from invalidCity.json: failed to resolve place configs: unknown city: "Invalid City"`,
+		},
+		{
+			Args: "--format json 12 19 2025",
+			Want: `[
+  {
+    "date": "2025-12-19",
+    "hdate": {
+      "hy": 5786,
+      "hm": "Kislev",
+      "hd": 29
+    },
+    "desc": "29th of Kislev, 5786",
+    "title": "29th of Kislev, 5786",
+    "flags": [
+      "HEBREW_DATE"
+    ]
+  },
+...
+]
+`,
+			WantMode: test.WantEllipsis,
+		},
+		{
+			Args: "-c candles.json --format ndjson 12 19 2025",
+			Want: `{"date":"2025-12-19",...,"flags":["HEBREW_DATE"]}
+{"date":"2025-12-19",...,"flags":["CHANUKAH_CANDLES","MINOR_HOLIDAY"],"time":"2025-12-19T16:12:00-05:00","tz":"America/New_York"}
+{"date":"2025-12-19",...,"desc":"Candle lighting",...}
+`,
+			WantMode: test.WantEllipsis,
+		},
+		{
+			Args: "-c threeYears.json --format ndjson 2025",
+			Want: `{"date":"2025-01-01",...}
+...
+{"date":"2027-12-31",...,"desc":"Rosh Chodesh Tevet",...}
+`,
+			WantMode: test.WantEllipsis,
+		},
+		{
+			Args: "-c candles.json --format ics --ics-alarm 15m 12 19 2025",
+			Want: strings.ReplaceAll(`BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//chaimleib//hebcalfmt//EN
+CALSCALE:GREGORIAN
+X-WR-CALNAME:Hebcal New York
+X-WR-TIMEZONE:America/New_York
+BEGIN:VTIMEZONE
+TZID:America/New_York
+BEGIN:STANDARD
+DTSTART:20251217T000000
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0500
+TZNAME:EST
+END:STANDARD
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:...@hebcalfmt
+DTSTAMP:20251221T000000Z
+DTSTART;VALUE=DATE:20251219
+DTEND;VALUE=DATE:20251220
+SUMMARY:29th of Kislev\, 5786
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:...@hebcalfmt
+DTSTAMP:20251221T000000Z
+DTSTART;TZID=America/New_York:20251219T161200
+SUMMARY:Chanukah: 6 Candles
+TRANSP:TRANSPARENT
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Chanukah: 6 Candles
+TRIGGER:-PT15M
+END:VALARM
+END:VEVENT
+...
+SUMMARY:Candle lighting
+...
+END:VCALENDAR
+`, "\n", "\r\n"),
+			WantMode: test.WantEllipsis,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.Args, func(t *testing.T) {
@@ -259,3 +417,343 @@ To show the available languages, run
 		})
 	}
 }
+
+func TestRunInEnvironment_ndjsonMatchesJSON(t *testing.T) {
+	files := fstest.MapFS{
+		"events.txt": &fstest.MapFile{Data: []byte("Elul 29 Last day\nTishrei 1 First day\n")},
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	for _, args := range []string{
+		"-H --set num_years=2 5786",
+		"-H --set num_years=3 --set events_file=events.txt 5786",
+		"--set num_years=2 --candle-lighting 2025",
+		"-H 5786",
+	} {
+		t.Run(args, func(t *testing.T) {
+			test.Logger(t)
+			run := func(format string) string {
+				var buf bytes.Buffer
+				fields := append([]string{"--format", format}, strings.Fields(args)...)
+				err := cli.RunInEnvironment(fields, files, now, templating.BuildData, &buf)
+				test.CheckErr(t, err, "")
+				return buf.String()
+			}
+
+			var events []json.RawMessage
+			if err := json.Unmarshal([]byte(run("json")), &events); err != nil {
+				t.Fatal(err)
+			}
+			var want []string
+			for _, ev := range events {
+				var b bytes.Buffer
+				if err := json.Compact(&b, ev); err != nil {
+					t.Fatal(err)
+				}
+				want = append(want, b.String())
+			}
+
+			got := strings.Split(strings.TrimSuffix(run("ndjson"), "\n"), "\n")
+			test.CheckSlice(t, "events", want, got)
+		})
+	}
+}
+
+func TestRunInEnvironment_layers(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	const sources = `{{$.configSource}}|` +
+		`city={{$.config.City}}@{{$.configSource.Of "city"}} ` +
+		`omer={{$.config.Omer}}@{{$.configSource.Of "omer"}} ` +
+		`mins={{$.config.CandleLightingMins}}@{{$.configSource.Of "candle_lighting_mins"}} ` +
+		`lat={{with $.config.Geo}}{{.Lat}}{{end}}@{{$.configSource.Of "geo.lat"}}`
+	files := fstest.MapFS{
+		"etc/config.json": fdata(`{"city": "Phoenix", "omer": true, "candle_lighting_mins": 20}`),
+		"user.json":       fdata(`{"city": "Jerusalem"}`),
+		"invalid.json":    fdata(`{INVALID`),
+		"profiles.json": fdata(`{"profile": "israel", "profiles": {
+			"base": {"omer": true},
+			"israel": {"extends": "base", "city": "Jerusalem"},
+			"phoenix": {"extends": "base", "city": "Phoenix"}
+		}}`),
+		"badProfiles.json": fdata(`{"profiles": {"broken": {"extends": "INVALID"}}}`),
+		"proj/hebcalfmt.json": fdata(
+			`{"candle_lighting_mins": 30, "geo": {"lat": 31.778, "lon": 35.235}, "timezone": "Asia/Jerusalem"}`,
+		),
+		"proj/sources.tmpl":  fdata(sources),
+		"sources.tmpl":       fdata(sources),
+		"bad/hebcalfmt.json": fdata(`{INVALID`),
+		"bad/stub.tmpl":      fdata(`ok`),
+		"user.yaml":          fdata("# Ops-managed\ncity: Jerusalem\nomer: true\n"),
+		"badType.yaml":       fdata("city: Jerusalem\nomer: sometimes\n"),
+		"comments.json":      fdata("{\n  // why 40\n  \"candle_lighting_mins\": 40,\n}"),
+		"toml/hebcalfmt.toml": fdata(
+			"candle_lighting_mins = 30 # minhag\n\n[geo]\nlat = 31.778\nlon = 35.235\n",
+		),
+		"toml/sources.tmpl": fdata(sources),
+		"tmpl/defaults.tmpl": fdata("{{- /*---\nconfig:\n  city: Tel Aviv\n" +
+			"  candle_lighting_mins: 25\n  events_file: events.txt\n---*/ -}}\n" + sources),
+		"tmpl/events.txt":   fdata("Kislev 6 Shul kiddush\n"),
+		"tmpl/invalid.tmpl": fdata("{{- /*---\nconfig:\n  citty: Tel Aviv\n---*/ -}}\n" + sources),
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name   string
+		Args   string
+		System string
+		Env    map[string]string
+		Want   string
+		Log    string
+		Err    string
+	}{
+		{
+			Name: "defaults",
+			Args: "sources.tmpl",
+			Want: "|city=@default omer=false@default mins=18@default lat=@default",
+		},
+		{
+			Name:   "system",
+			Args:   "sources.tmpl",
+			System: "etc/config.json",
+			Want:   "etc/config.json|city=Phoenix@etc/config.json omer=true@etc/config.json mins=20@etc/config.json lat=@default",
+		},
+		{
+			Name:   "system and --config",
+			Args:   "-c user.json sources.tmpl",
+			System: "etc/config.json",
+			Want:   "etc/config.json, user.json|city=Jerusalem@user.json omer=true@etc/config.json mins=20@etc/config.json lat=@default",
+		},
+		{
+			Name:   "project",
+			Args:   "-c user.json proj/sources.tmpl",
+			System: "etc/config.json",
+			Want: "etc/config.json, user.json, proj/hebcalfmt.json|" +
+				"city=Jerusalem@user.json omer=true@etc/config.json " +
+				"mins=30@proj/hebcalfmt.json lat=31.778@proj/hebcalfmt.json",
+		},
+		{
+			Name:   "env",
+			Args:   "-c user.json proj/sources.tmpl",
+			System: "etc/config.json",
+			Env: map[string]string{
+				"HEBCALFMT_OMER":     "false",
+				"HEBCALFMT_GEO__LAT": "32.08",
+				"HEBCALFMT_TIMEZONE": "Asia/Jerusalem",
+				"OTHER_CITY":         "ignored",
+			},
+			Want: "etc/config.json, user.json, proj/hebcalfmt.json, env|" +
+				"city=Jerusalem@user.json omer=false@env " +
+				"mins=30@proj/hebcalfmt.json lat=32.08@env",
+		},
+		{
+			Name:   "flags",
+			Args:   "-c user.json -C Phoenix -b 40 proj/sources.tmpl",
+			System: "etc/config.json",
+			Env:    map[string]string{"HEBCALFMT_CITY": "Tel Aviv"},
+			Want: "etc/config.json, user.json, proj/hebcalfmt.json, env, flags|" +
+				"city=Phoenix@flags omer=true@etc/config.json " +
+				"mins=40@flags lat=31.778@proj/hebcalfmt.json",
+		},
+		{
+			Name:   "template defaults",
+			Args:   "tmpl/defaults.tmpl",
+			System: "etc/config.json",
+			Want: "etc/config.json, tmpl/defaults.tmpl|" +
+				"city=Tel Aviv@tmpl/defaults.tmpl omer=true@etc/config.json " +
+				"mins=25@tmpl/defaults.tmpl lat=@default",
+		},
+		{
+			Name:   "template defaults beneath --config",
+			Args:   "-c user.json tmpl/defaults.tmpl",
+			System: "etc/config.json",
+			Want: "etc/config.json, tmpl/defaults.tmpl, user.json|" +
+				"city=Jerusalem@user.json omer=true@etc/config.json " +
+				"mins=25@tmpl/defaults.tmpl lat=@default",
+		},
+		{
+			Name: "invalid template defaults",
+			Args: "tmpl/invalid.tmpl",
+			Err:  `front matter of tmpl/invalid.tmpl: config: json: unknown field "citty"`,
+		},
+		{
+			Name: "yaml",
+			Args: "-c user.yaml sources.tmpl",
+			Want: "user.yaml|city=Jerusalem@user.yaml omer=true@user.yaml mins=18@default lat=@default",
+		},
+		{
+			Name: "yaml type error",
+			Args: "-c badType.yaml sources.tmpl",
+			Err:  `failed to load config: failed to parse config from "badType.yaml": badType.yaml:2:1: invalid value for "omer": expected true or false, got "sometimes"`,
+		},
+		{
+			Name: "json with comments",
+			Args: "-c comments.json sources.tmpl",
+			Want: "comments.json|city=@default omer=false@default mins=40@comments.json lat=@default",
+		},
+		{
+			Name: "toml project",
+			Args: "--timezone Asia/Jerusalem toml/sources.tmpl",
+			Want: "toml/hebcalfmt.toml, flags|" +
+				"city=@default omer=false@default " +
+				"mins=30@toml/hebcalfmt.toml lat=31.778@toml/hebcalfmt.toml",
+		},
+		{
+			Name: "profile from file",
+			Args: "-c profiles.json sources.tmpl",
+			Want: "profiles.json, profile:base, profile:israel|" +
+				"city=Jerusalem@profile:israel omer=true@profile:base mins=18@default lat=@default",
+		},
+		{
+			Name: "profile from flag",
+			Args: "-c profiles.json --profile phoenix sources.tmpl",
+			Env:  map[string]string{"HEBCALFMT_PROFILE": "israel"},
+			Want: "profiles.json, profile:base, profile:phoenix, env|" +
+				"city=Phoenix@profile:phoenix omer=true@profile:base mins=18@default lat=@default",
+		},
+		{
+			Name: "profile from env",
+			Args: "-c profiles.json sources.tmpl",
+			Env:  map[string]string{"HEBCALFMT_PROFILE": "phoenix"},
+			Want: "profiles.json, profile:base, profile:phoenix, env|" +
+				"city=Phoenix@profile:phoenix omer=true@profile:base mins=18@default lat=@default",
+		},
+		{
+			Name: "env overrides profile",
+			Args: "-c profiles.json sources.tmpl",
+			Env:  map[string]string{"HEBCALFMT_CITY": "Tel Aviv"},
+			Want: "profiles.json, profile:base, profile:israel, env|" +
+				"city=Tel Aviv@env omer=true@profile:base mins=18@default lat=@default",
+		},
+		{
+			Name: "unknown profile",
+			Args: "-c profiles.json --profile INVALID sources.tmpl",
+			Err:  `unknown profile: "INVALID"`,
+		},
+		{
+			Name: "broken unselected profile",
+			Args: "-c badProfiles.json sources.tmpl",
+			Err:  `invalid profile "broken": profile "broken" extends unknown profile: "INVALID"`,
+		},
+		{
+			Name:   "invalid system",
+			Args:   "sources.tmpl",
+			System: "invalid.json",
+			Err:    `failed to load config: failed to parse config from "invalid.json": invalid character 'I' looking for beginning of object key string`,
+		},
+		{
+			Name: "invalid project",
+			Args: "bad/stub.tmpl",
+			Err:  `failed to load config: failed to parse config from "bad/hebcalfmt.json": invalid character 'I' looking for beginning of object key string`,
+		},
+		{
+			Name: "unknown env",
+			Args: "sources.tmpl",
+			Env:  map[string]string{"HEBCALFMT_CTY": "Tel Aviv"},
+			Want: "|city=@default omer=false@default mins=18@default lat=@default",
+			Log: `warn: environment variable HEBCALFMT_CTY: unknown config key: "cty"; ` +
+				`did you mean "city"?` + "\n",
+		},
+		{
+			Name: "invalid env",
+			Args: "sources.tmpl",
+			Env:  map[string]string{"HEBCALFMT_OMER": "maybe"},
+			Err: `invalid environment variable HEBCALFMT_OMER: invalid value for config key "omer": ` +
+				`strconv.ParseBool: parsing "maybe": invalid syntax`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			orig := cli.SystemConfigPath
+			t.Cleanup(func() { cli.SystemConfigPath = orig })
+			cli.SystemConfigPath = c.System
+			for k, v := range c.Env {
+				t.Setenv(k, v)
+			}
+
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckString(t, "output", c.Want, buf.String())
+			if c.Err == "" {
+				test.CheckString(t, "logs", c.Log, logBuf.String())
+			}
+		})
+	}
+}
+
+func TestRunInEnvironment_fileLayers(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"cfg/c.json": fdata(`{"events_file": "ev.txt", "template_path": ["lib"]}`),
+		"cfg/ev.txt": fdata("Kislev 6 Shul kiddush\n"),
+		"cfg/lib/line.tmpl": fdata(`{{define "line"}}` +
+			`{{.GetDate.Gregorian.Format "1/2 "}}{{.Render "en"}} ({{eventSource .}})` +
+			"\n{{end}}"),
+		"cfg/profiles.json": fdata(`{"profile": "shul", "profiles": {"shul": {"events_file": "ev.txt"}}}`),
+		"t.tmpl": fdata(`{{import "line.tmpl"}}` +
+			`{{range hebcal}}{{if eventSource .}}{{template "line" .}}{{end}}{{end}}`),
+		"ev2.txt": fdata("Kislev 8 Board meeting\n"),
+		"y.txt":   fdata("11 27 1990 Yahrzeit - Joe Shmo\n"),
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name string
+		Args string
+		Env  map[string]string
+		Want string
+		Err  string
+	}{
+		{
+			Name: "config file",
+			Args: "-c cfg/c.json t.tmpl 11 2025",
+			Want: "11/26 Shul kiddush (ev.txt)\n",
+		},
+		{
+			Name: "yahrzeits from flags",
+			Args: "-c cfg/c.json --set yahrzeits_file=y.txt t.tmpl 11 2025",
+			Want: "11/26 Shul kiddush (ev.txt)\n11/30 Yahrzeit - Joe Shmo (y.txt)\n",
+		},
+		{
+			Name: "empty yahrzeits from flags",
+			Args: "-c cfg/c.json --set yahrzeits_file= t.tmpl 11 2025",
+			Want: "11/26 Shul kiddush (ev.txt)\n",
+		},
+		{
+			Name: "template_path from flags",
+			Args: "-c cfg/c.json --set template_path=cfg/lib t.tmpl 11 2025",
+			Want: "11/26 Shul kiddush (ev.txt)\n",
+		},
+		{
+			Name: "events from env",
+			Args: "-c cfg/c.json t.tmpl 11 2025",
+			Env:  map[string]string{"HEBCALFMT_EVENTS_FILE": "ev2.txt"},
+			Want: "11/28 Board meeting (ev2.txt)\n",
+		},
+		{
+			Name: "events from profile",
+			Args: "-c cfg/profiles.json --set template_path=cfg/lib t.tmpl 11 2025",
+			Want: "11/26 Shul kiddush (ev.txt)\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			for k, v := range c.Env {
+				t.Setenv(k, v)
+			}
+
+			var buf bytes.Buffer
+			test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckString(t, "output", c.Want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/config"
+)
+
+// ErrConfigCheck means that `hebcalfmt config check` found problems.
+var ErrConfigCheck = errors.New("config check failed")
+
+// NewConfigFlags returns a [pflag.FlagSet] configured with the flags
+// used by the config subcommand.
+func NewConfigFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(ProgName+" config", pflag.ContinueOnError)
+
+	fs.BoolP("help", "h", false,
+		"print this help text")
+
+	return fs
+}
+
+func configUsage(flagUsages string) string {
+	return strings.Join(
+		[]string{
+			"usage:",
+			fmt.Sprintf("  %s config check [ config.json ... ]", ProgName),
+			"",
+			"Checks config files for unknown keys, values of the wrong type,",
+			"out-of-range numbers and conflicting settings.",
+			"Without arguments, it checks the system, user and project config files",
+			"which exist.",
+			"",
+			"OPTIONS:",
+			flagUsages,
+		},
+		"\n",
+	)
+}
+
+// runConfig handles the config subcommand.
+// args should not include the subcommand name itself.
+func runConfig(args []string, files fs.FS, w io.Writer) error {
+	flagSet := NewConfigFlags()
+	if err := flagSet.Parse(args); err != nil {
+		log.Println(configUsage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	help, err := flagSet.GetBool("help")
+	if err != nil {
+		slog.Error("failed to get --help flag", "error", err)
+		return fmt.Errorf("%w: get --help: %w", ErrUnreachable, err)
+	}
+	if help {
+		fmt.Fprintln(w, configUsage(flagSet.FlagUsages()))
+		return nil
+	}
+
+	if flagSet.NArg() == 0 || flagSet.Arg(0) != "check" {
+		log.Println(configUsage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: expected config check, got %q", ErrUsage, flagSet.Args())
+	}
+
+	paths := flagSet.Args()[1:]
+	if len(paths) == 0 {
+		paths = existingConfigPaths(files)
+		if len(paths) == 0 {
+			fmt.Fprintln(w, "no config files found")
+			return nil
+		}
+	}
+
+	var problems int
+	for _, fpath := range paths {
+		n, err := checkConfigFile(files, fpath, w)
+		if err != nil {
+			return err
+		}
+		problems += n
+	}
+	if problems != 0 {
+		return fmt.Errorf("%w: %d problem(s) found", ErrConfigCheck, problems)
+	}
+	return nil
+}
+
+// existingConfigPaths lists the config files which would be loaded
+// without a --config flag, from the working directory.
+func existingConfigPaths(files fs.FS) []string {
+	var paths []string
+	for _, fpath := range []string{
+		SystemConfigPath,
+		DefaultConfigPath(),
+		ProjectConfigName,
+	} {
+		if fpath == "" {
+			continue
+		}
+		fpath = findConfigFile(files, fpath)
+		if _, err := fs.Stat(files, fpath); err == nil {
+			paths = append(paths, fpath)
+		}
+	}
+	return paths
+}
+
+// checkConfigFile prints the problems found in the config file at fpath,
+// or that it is ok, and returns the number of problems.
+func checkConfigFile(files fs.FS, fpath string, w io.Writer) (int, error) {
+	data, err := fs.ReadFile(files, fpath)
+	if err != nil {
+		return 0, fmt.Errorf("config file could not be read: %w", err)
+	}
+
+	warns, err := config.Validate(data, fpath)
+	if err != nil {
+		return 0, err
+	}
+	if len(warns) == 0 {
+		fmt.Fprintf(w, "%s: ok\n", fpath)
+		return 0, nil
+	}
+	for _, warn := range warns {
+		fmt.Fprintln(w, warn)
+	}
+	return len(warns), nil
+}
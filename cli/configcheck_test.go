@@ -0,0 +1,148 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_config(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"good.json": fdata(`{"city": "Jerusalem"}`),
+		"typo.json": fdata(`{
+  "city": "Jerusalem",
+  "candle_lightning_mins": 40
+}`),
+		"conflict.json":  fdata(`{"havdalah_mins": 72, "havdalah_deg": 8.5}`),
+		"invalid.json":   fdata(`{INVALID`),
+		"hebcalfmt.json": fdata(`{"omer": true}`),
+	}
+	usagePrefix := fmt.Sprintf("usage:\n  %s config check ", cli.ProgName)
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Args        string
+		Want        string
+		WantMode    test.WantMode
+		WantLog     string
+		WantLogMode test.WantMode
+		Err         string
+	}{
+		{
+			Args:     "config -h",
+			Want:     usagePrefix,
+			WantMode: test.WantPrefix,
+		},
+		{
+			Args:        "config",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         "usage error: expected config check, got []",
+		},
+		{
+			Args:        "config INVALID",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: expected config check, got ["INVALID"]`,
+		},
+		{
+			Args:        "config --INVALID",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         "usage error: unknown flag: --INVALID",
+		},
+		{
+			Args: "config check",
+			Want: "hebcalfmt.json: ok\n",
+		},
+		{
+			Args: "config check good.json",
+			Want: "good.json: ok\n",
+		},
+		{
+			Args: "config check good.json typo.json conflict.json",
+			Want: "good.json: ok\n" +
+				`typo.json:3:3: unknown config key "candle_lightning_mins"; did you mean "candle_lighting_mins"?` + "\n" +
+				"conflict.json:1:23: conflicting settings: havdalah_mins and havdalah_deg are both set\n",
+			Err: "config check failed: 2 problem(s) found",
+		},
+		{
+			Args: "config check invalid.json",
+			Err:  `failed to parse config from "invalid.json": invalid character 'I' looking for beginning of value`,
+		},
+		{
+			Args: "config check missing.json",
+			Err:  "config file could not be read: open missing.json: file does not exist",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Args, func(t *testing.T) {
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckStringMode(t, "output", c.Want, buf.String(), c.WantMode)
+			test.CheckStringMode(t, "logs", c.WantLog, logBuf.String(), c.WantLogMode)
+		})
+	}
+
+	t.Run("no config files", func(t *testing.T) {
+		var buf bytes.Buffer
+		test.Logger(t)
+		err := cli.RunInEnvironment(
+			[]string{"config", "check"}, fstest.MapFS{}, now, templating.BuildData, &buf)
+		test.CheckErr(t, err, "")
+		test.CheckString(t, "output", "no config files found\n", buf.String())
+	})
+}
+
+func TestRunInEnvironment_configWarnings(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"typo.json": fdata(`{"candle_lightning_mins": 40}`),
+		"stub.tmpl": fdata(`ok`),
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Args    string
+		WantLog string
+	}{
+		{Args: "stub.tmpl"},
+		{
+			Args:    "-c typo.json stub.tmpl",
+			WantLog: `warn: typo.json:1:2: unknown config key "candle_lightning_mins"; did you mean "candle_lighting_mins"?` + "\n",
+		},
+		{
+			Args: "-c typo.json -b 400 --set havdalah_mins=72 --set havdalah_deg=8.5 stub.tmpl",
+			WantLog: "3 warnings:\n" +
+				`typo.json:1:2: unknown config key "candle_lightning_mins"; did you mean "candle_lighting_mins"?` + "\n" +
+				"candle_lighting_mins: out of range: expected 0 to 90 minutes, got 400\n" +
+				"havdalah_deg: conflicting settings: havdalah_mins and havdalah_deg are both set\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Args, func(t *testing.T) {
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, "")
+			test.CheckString(t, "output", "ok", buf.String())
+			test.CheckString(t, "logs", c.WantLog, logBuf.String())
+		})
+	}
+}
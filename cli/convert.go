@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+)
+
+// NewConvertFlags returns a [pflag.FlagSet] configured with the flags
+// used by the convert subcommand.
+func NewConvertFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(ProgName+" convert", pflag.ContinueOnError)
+
+	fs.BoolP("help", "h", false,
+		"print this help text")
+
+	return fs
+}
+
+func convertUsage(flagUsages string) string {
+	return strings.Join(
+		[]string{
+			"usage:",
+			fmt.Sprintf("  %s convert { events | yahrzeits } file", ProgName),
+			"",
+			"Converts an events or yahrzeits file between the line format",
+			"of events_file and yahrzeits_file, and CSV with a header row.",
+			"A file ending in .csv is converted to lines; any other file to CSV.",
+			"The result is printed.",
+			"",
+			"OPTIONS:",
+			flagUsages,
+		},
+		"\n",
+	)
+}
+
+// runConvert handles the convert subcommand.
+// args should not include the subcommand name itself.
+func runConvert(args []string, files fs.FS, w io.Writer) error {
+	flagSet := NewConvertFlags()
+	if err := flagSet.Parse(args); err != nil {
+		log.Println(convertUsage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	help, err := flagSet.GetBool("help")
+	if err != nil {
+		slog.Error("failed to get --help flag", "error", err)
+		return fmt.Errorf("%w: get --help: %w", ErrUnreachable, err)
+	}
+	if help {
+		fmt.Fprintln(w, convertUsage(flagSet.FlagUsages()))
+		return nil
+	}
+
+	if flagSet.NArg() != 2 {
+		log.Println(convertUsage(flagSet.FlagUsages()))
+		return fmt.Errorf(
+			"%w: expected a kind and a file, got %q", ErrUsage, flagSet.Args())
+	}
+	kind, fpath := flagSet.Arg(0), flagSet.Arg(1)
+	fromCSV := strings.EqualFold(path.Ext(fpath), ".csv")
+
+	switch kind {
+	case "events":
+		var events hcfiles.Events
+		if fromCSV {
+			if err := config.ParseFile(files, fpath, hcfiles.ParseEventsCSV, &events); err != nil {
+				return err
+			}
+			return hcfiles.WriteEvents(w, events.UserEvents)
+		}
+		err := config.ParseFile(files, fpath, hcfiles.EventsParser(files), &events)
+		if err != nil {
+			return err
+		}
+		if len(events.Rules) != 0 {
+			return fmt.Errorf(
+				"%s: %d entries use the extended syntax, which CSV does not support",
+				fpath, len(events.Rules))
+		}
+		return hcfiles.WriteEventsCSV(w, events.UserEvents)
+
+	case "yahrzeits":
+		var yahrzeits []hcfiles.Yahrzeit
+		if fromCSV {
+			err := config.ParseFile(files, fpath, hcfiles.ParseYahrzeitsCSV, &yahrzeits)
+			if err != nil {
+				return err
+			}
+			return hcfiles.WriteYahrzeits(w, yahrzeits)
+		}
+		err := config.ParseFile(files, fpath, hcfiles.YahrzeitsParser(files), &yahrzeits)
+		if err != nil {
+			return err
+		}
+		return hcfiles.WriteYahrzeitsCSV(w, yahrzeits)
+	}
+
+	log.Println(convertUsage(flagSet.FlagUsages()))
+	return fmt.Errorf(
+		"%w: expected events or yahrzeits, got %q", ErrUsage, kind)
+}
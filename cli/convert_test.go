@@ -0,0 +1,97 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_convert(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"events.txt":    fdata("Tishrei 2 Birthday - Ben Ploni\n#include more.txt\n"),
+		"more.txt":      fdata("Kislev 6 Shul kiddush\n"),
+		"rules.txt":     fdata("every Tuesday time=20:00 Shiur\n"),
+		"events.csv":    fdata("Month,Day,Description\nTishrei,2,Birthday - Ben Ploni\n"),
+		"yahrzeits.txt": fdata("10 8 1967 after-sunset Yahrzeit - Joe Shmo\n"),
+		"yahrzeits.CSV": fdata("name,month,day,year,after sunset\nYahrzeit - Joe Shmo,10,8,1967,x\n"),
+		"invalid.csv":   fdata("name,month,day,year\nJoe,10,32,1967\n"),
+	}
+	usagePrefix := fmt.Sprintf("usage:\n  %s convert ", cli.ProgName)
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Args        string
+		Want        string
+		WantMode    test.WantMode
+		WantLog     string
+		WantLogMode test.WantMode
+		Err         string
+	}{
+		{
+			Args:     "convert -h",
+			Want:     usagePrefix,
+			WantMode: test.WantPrefix,
+		},
+		{
+			Args:        "convert events",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: expected a kind and a file, got ["events"]`,
+		},
+		{
+			Args:        "convert birthdays events.txt",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: expected events or yahrzeits, got "birthdays"`,
+		},
+		{
+			Args: "convert events events.txt",
+			Want: "month,day,name\nTishrei,2,Birthday - Ben Ploni\nKislev,6,Shul kiddush\n",
+		},
+		{
+			Args: "convert events events.csv",
+			Want: "Tishrei 2 Birthday - Ben Ploni\n",
+		},
+		{
+			Args: "convert events rules.txt",
+			Err:  "rules.txt: 1 entries use the extended syntax, which CSV does not support",
+		},
+		{
+			Args: "convert yahrzeits yahrzeits.txt",
+			Want: "month,day,year,name,after_sunset\n10,8,1967,Yahrzeit - Joe Shmo,yes\n",
+		},
+		{
+			Args: "convert yahrzeits yahrzeits.CSV",
+			Want: "10 8 1967 after-sunset Yahrzeit - Joe Shmo\n",
+		},
+		{
+			Args: "convert yahrzeits invalid.csv",
+			Err:  "ParseYahrzeitsCSV: error at invalid.csv:2: invalid days",
+		},
+		{
+			Args: "convert yahrzeits missing.txt",
+			Err:  "open missing.txt: file does not exist",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Args, func(t *testing.T) {
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckStringMode(t, "output", c.Want, buf.String(), c.WantMode)
+			test.CheckStringMode(t, "logs", c.WantLog, logBuf.String(), c.WantLogMode)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+	"github.com/chaimleib/hebcalfmt/templating"
+)
+
+// Formats lists the built-in output formats
+// which can be selected with the --format CLI option.
+var Formats = []string{
+	"ics",
+	"json",
+	"ndjson",
+}
+
+// getFormat returns the value of the --format flag,
+// or an error if the format is unknown.
+func getFormat(flagSet *pflag.FlagSet) (string, error) {
+	format, err := flagSet.GetString("format")
+	if err != nil {
+		slog.Error("failed to get --format option", "error", err)
+		return "", fmt.Errorf("%w: get --format: %w", ErrUnreachable, err)
+	}
+	if format != "" && !slices.Contains(Formats, format) {
+		return "", fmt.Errorf(
+			"%w: unrecognized key for --format flag: %q",
+			ErrUsage,
+			format,
+		)
+	}
+	return format, nil
+}
+
+// runFormat writes the events for the configured date range to w,
+// in one of the [Formats], without using a template.
+func runFormat(
+	flagSet *pflag.FlagSet,
+	cfg *config.Config,
+	format string,
+	w io.Writer,
+) error {
+	opts, extras, err := cfg.CalOptionsWithExtras()
+	if err != nil {
+		return fmt.Errorf("failed to build hebcal options from %s: %w",
+			cfg.ConfigSource, err)
+	}
+
+	switch format {
+	case "ics":
+		events, err := templating.Hebcal(opts, &extras)()
+		if err != nil {
+			return err
+		}
+
+		alarm, err := flagSet.GetDuration("ics-alarm")
+		if err != nil {
+			slog.Error("failed to get --ics-alarm option", "error", err)
+			return fmt.Errorf("%w: get --ics-alarm: %w", ErrUnreachable, err)
+		}
+
+		o, err := templating.NewICSOptions(opts, cfg.Language, cfg.Now)
+		if err != nil {
+			return err
+		}
+		o.CandleAlarm = alarm
+
+		_, err = o.Calendar(events).WriteTo(w)
+		return err
+
+	case "json":
+		events, err := templating.Hebcal(opts, &extras)()
+		if err != nil {
+			return err
+		}
+		return templating.WriteJSON(w, cfg.Language, events)
+
+	case "ndjson":
+		// Stream one year at a time,
+		// so that long ranges start printing right away.
+		years, err := yearRanges(opts)
+		if err != nil {
+			return err
+		}
+		for _, yearOpts := range years {
+			events, err := hcfiles.HebrewCalendar(&yearOpts, extras.Rules)
+			if err != nil {
+				return err
+			}
+			err = templating.WriteNDJSON(w, cfg.Language, events)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		slog.Error("unhandled format", "format", format)
+		return fmt.Errorf("%w: unhandled format: %q", ErrUnreachable, format)
+	}
+}
+
+// yearRanges splits the date range of opts into one opts per year,
+// with explicit Start and End dates which do not overlap.
+// A Hebrew year on its own starts on Erev Rosh Hashana,
+// which is also the last day of the year before it;
+// that day belongs only to the earlier year here.
+// Ranges which are not a number of years are not split.
+func yearRanges(opts *hebcal.CalOptions) ([]hebcal.CalOptions, error) {
+	zero := hdate.HDate{}
+	if opts.NumYears <= 1 || opts.Year == 0 || opts.Month != 0 ||
+		opts.Start != zero || opts.End != zero {
+		return []hebcal.CalOptions{*opts}, nil
+	}
+
+	var result []hebcal.CalOptions
+	var prevEnd int64
+	for i := range opts.NumYears {
+		yearOpts := *opts
+		yearOpts.Year = opts.Year + i
+		yearOpts.NumYears = 1
+		start, end, err := hcfiles.CalendarRange(&yearOpts)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			start = hdate.FromRD(max(start.Abs(), prevEnd+1))
+		}
+		prevEnd = end.Abs()
+		yearOpts.Start, yearOpts.End = start, end
+		result = append(result, yearOpts)
+	}
+	return result, nil
+}
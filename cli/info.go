@@ -16,8 +16,10 @@ import (
 // InfoKeys lists types of data which can be queried with the --info CLI option.
 var InfoKeys = []string{
 	"cities",
+	"config-schema",
 	"default-city",
 	"languages",
+	"templates",
 }
 
 func infoString(key string) (string, error) {
@@ -25,12 +27,18 @@ func infoString(key string) (string, error) {
 	case "cities":
 		return strings.Join(sortedCities(), "\n"), nil
 
+	case "config-schema":
+		return strings.TrimSuffix(string(config.SchemaJSON), "\n"), nil
+
 	case "default-city":
 		return config.DefaultCity, nil
 
 	case "languages":
 		return strings.Join(sortedLanguages(), "\n"), nil
 
+	case "templates":
+		return templatesInfo(), nil
+
 	default:
 		log.Printf("unrecognized key for --info flag: %q", key)
 		log.Printf("Available options: %q", InfoKeys)
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+)
+
+// ErrLint means that `hebcalfmt lint` found problems.
+var ErrLint = errors.New("lint failed")
+
+// NewLintFlags returns a [pflag.FlagSet] configured with the flags
+// used by the lint subcommand.
+func NewLintFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(ProgName+" lint", pflag.ContinueOnError)
+
+	fs.BoolP("help", "h", false,
+		"print this help text")
+
+	return fs
+}
+
+func lintUsage(flagUsages string) string {
+	return strings.Join(
+		[]string{
+			"usage:",
+			fmt.Sprintf("  %s lint { template.tmpl | @name | - } ...", ProgName),
+			"",
+			"Checks templates without running them, for unknown functions,",
+			"the wrong number or type of arguments, unknown keys of $.",
+			"and parameters, and variables which are never used.",
+			"A template path of - reads the template from stdin.",
+			"",
+			"OPTIONS:",
+			flagUsages,
+		},
+		"\n",
+	)
+}
+
+// runLint handles the lint subcommand.
+// args should not include the subcommand name itself.
+func runLint(args []string, files fs.FS, w io.Writer) error {
+	flagSet := NewLintFlags()
+	if err := flagSet.Parse(args); err != nil {
+		log.Println(lintUsage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	help, err := flagSet.GetBool("help")
+	if err != nil {
+		slog.Error("failed to get --help flag", "error", err)
+		return fmt.Errorf("%w: get --help: %w", ErrUnreachable, err)
+	}
+	if help {
+		fmt.Fprintln(w, lintUsage(flagSet.FlagUsages()))
+		return nil
+	}
+
+	if flagSet.NArg() == 0 {
+		log.Println(lintUsage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: missing a template file argument", ErrUsage)
+	}
+
+	var problems int
+	for _, arg := range flagSet.Args() {
+		n, err := lintTemplate(files, arg, w)
+		if err != nil {
+			return err
+		}
+		problems += n
+	}
+	if problems != 0 {
+		return fmt.Errorf("%w: %d problem(s) found", ErrLint, problems)
+	}
+	return nil
+}
+
+// lintTemplate prints the problems found in the template named by arg,
+// or that it is ok, and returns the number of problems.
+func lintTemplate(files fs.FS, arg string, w io.Writer) (int, error) {
+	var text []byte
+	var err error
+	name := arg
+	if arg == StdinPath {
+		name = StdinTemplateName
+		text, err = io.ReadAll(Stdin)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read the template from stdin: %w", err)
+		}
+	} else {
+		tmplFiles, fpath, err := templateFiles(files, arg)
+		if err != nil {
+			return 0, err
+		}
+		text, err = fs.ReadFile(tmplFiles, fpath)
+		if err != nil {
+			return 0, fmt.Errorf("template could not be read: %w", err)
+		}
+	}
+
+	warns, err := templating.Lint(text, name)
+	if err != nil {
+		return 0, err
+	}
+	if len(warns) == 0 {
+		fmt.Fprintf(w, "%s: ok\n", name)
+		return 0, nil
+	}
+	for _, warn := range warns {
+		fmt.Fprintln(w, warn)
+	}
+	return len(warns), nil
+}
@@ -0,0 +1,96 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_lint(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"good.tmpl":   fdata("{{hdateFromTime $.now}}"),
+		"bad.tmpl":    fdata("{{$d := 1}}\n{{hdateFromTim $.now}}"),
+		"broken.tmpl": fdata("{{if}}"),
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+	usagePrefix := fmt.Sprintf("usage:\n  %s lint { template.tmpl | @name | - } ...", cli.ProgName)
+
+	cases := []struct {
+		Name        string
+		Args        []string
+		Stdin       string
+		Want        string
+		WantMode    test.WantMode
+		WantLog     string
+		WantLogMode test.WantMode
+		Err         string
+	}{
+		{
+			Name: "ok",
+			Args: []string{"lint", "good.tmpl", "@today"},
+			Want: "good.tmpl: ok\n@today: ok\n",
+		},
+		{
+			Name: "problems",
+			Args: []string{"lint", "bad.tmpl", "good.tmpl"},
+			Want: "bad.tmpl:1:3: $d declared and not used\n" +
+				"bad.tmpl:2:3: unknown function \"hdateFromTim\"; did you mean \"hdateFromTime\"?\n" +
+				"good.tmpl: ok\n",
+			Err: "lint failed: 2 problem(s) found",
+		},
+		{
+			Name:  "stdin",
+			Args:  []string{"lint", "-"},
+			Stdin: "{{$.nw}}",
+			Want:  "<stdin>:1:4: unknown key $.nw; did you mean \"$.now\"?\n",
+			Err:   "lint failed: 1 problem(s) found",
+		},
+		{
+			Name: "parse error",
+			Args: []string{"lint", "broken.tmpl"},
+			Err:  "template: broken.tmpl:1: missing value for if",
+		},
+		{
+			Name: "missing file",
+			Args: []string{"lint", "missing.tmpl"},
+			Err:  "template could not be read: open missing.tmpl: file does not exist",
+		},
+		{
+			Name:     "help",
+			Args:     []string{"lint", "--help"},
+			Want:     usagePrefix,
+			WantMode: test.WantPrefix,
+		},
+		{
+			Name:        "no templates",
+			Args:        []string{"lint"},
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         "usage error: missing a template file argument",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			orig := cli.Stdin
+			t.Cleanup(func() { cli.Stdin = orig })
+			cli.Stdin = strings.NewReader(c.Stdin)
+
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(c.Args, files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckStringMode(t, "output", c.Want, buf.String(), c.WantMode)
+			test.CheckStringMode(t, "logs", c.WantLog, logBuf.String(), c.WantLogMode)
+		})
+	}
+}
@@ -10,15 +10,70 @@ func usage(flagUsages string) string {
 		[]string{
 			"usage:",
 			fmt.Sprintf(
-				"  %s [{ --config | -c } config.json ] template.tmpl [[ month [ day ]] year ]",
+				"  %s [{ --config | -c } config.json ] [ config-overrides ] template.tmpl [[ month [ day ]] year ]",
 				ProgName,
 			),
 			fmt.Sprintf(
-				"  %s { --info | -i }[=]{ %s }",
+				"  %s [{ --config | -c } config.json ] [ config-overrides ] @name [[ month [ day ]] year ]",
+				ProgName,
+			),
+			fmt.Sprintf(
+				"  %s [{ --config | -c } config.json ] [ config-overrides ] { -e | --expr } template-text [[ month [ day ]] year ]",
+				ProgName,
+			),
+			fmt.Sprintf(
+				"  %s [{ --config | -c } config.json ] [ config-overrides ] - [[ month [ day ]] year ] < template.tmpl",
+				ProgName,
+			),
+			fmt.Sprintf(
+				"  %s [{ --config | -c } config.json ] [ config-overrides ] --format { %s } [[ month [ day ]] year ]",
+				ProgName,
+				strings.Join(Formats, " | "),
+			),
+			fmt.Sprintf(
+				"  %s serve [{ --config | -c } config.json ] [ --addr host:port ] [ templates-dir ]",
+				ProgName,
+			),
+			fmt.Sprintf("  %s config check [ config.json ... ]", ProgName),
+			fmt.Sprintf("  %s convert { events | yahrzeits } file", ProgName),
+			fmt.Sprintf("  %s lint { template.tmpl | @name | - } ...", ProgName),
+			fmt.Sprintf(
+				"  %s repl [{ --config | -c } config.json ] [ config-overrides ] [[ month [ day ]] year ]",
+				ProgName,
+			),
+			fmt.Sprintf(
+				"  %s --info[=]{ %s }",
 				ProgName,
 				strings.Join(InfoKeys, " | "),
 			),
 			fmt.Sprintf("  %s [ -h | --help | --version ]", ProgName),
+			fmt.Sprintf("  %s { -h | --help } { template.tmpl | @name }", ProgName),
+			"",
+			"DATE RANGES:",
+			"  [[ month [ day ]] year ]  a year, month or day, like hebcal",
+			"  day month year            a Hebrew day, like 15 Shevat 5786 or ט״ו בשבט תשפ״ו",
+			"  start..end                from the start of one of the above through the end of another",
+			"  +14d | +2w                a number of days or weeks, starting today",
+			"  this-week | next-week     Sunday through Shabbat",
+			"",
+			"CONFIG LAYERS, from lowest to highest precedence:",
+			"  /etc/hebcalfmt/config.json          site-wide defaults",
+			"  the template front matter           its config key",
+			"  --config, or else the user config   like $HOME/.config/hebcalfmt/config.json",
+			"  hebcalfmt.json                      next to the template, or in . with --format",
+			"  the selected profile                from --profile, or the profile key",
+			"  HEBCALFMT_* environment variables   like HEBCALFMT_CITY or HEBCALFMT_GEO__LAT",
+			"  config overrides                    see below",
+			"  Config files may be JSON with comments, or YAML or TOML by extension,",
+			"  like config.yaml or hebcalfmt.toml in place of the .json files above.",
+			"",
+			"CONFIG OVERRIDES:",
+			"  Flags like --city and --set key=value are applied on top of the config files.",
+			"  Short flags mirror classic hebcal, like -C city, -b mins and -o.",
+			"",
+			"TEMPLATE PARAMETERS:",
+			"  Templates may declare parameters, set like --param city=Jerusalem.",
+			"  To list them, run --help with the template.",
 			"",
 			"OPTIONS:",
 			flagUsages,
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/config"
+)
+
+// configFlag describes a flag which overrides a single config key,
+// as accepted by [config.Config.Set].
+// The long name of the flag is the key, with hyphens for underscores.
+type configFlag struct {
+	Key   string
+	Short string // mirrors classic hebcal, where it does not collide
+	Bool  bool
+	Usage string
+}
+
+var configFlags = []configFlag{
+	{Key: "city", Short: "C", Usage: "override the city, like Jerusalem"},
+	{Key: "geo", Short: "l", Usage: "override the coordinates, as lat,lon"},
+	{Key: "timezone", Short: "z", Usage: "override the timezone, like America/New_York"},
+	{Key: "language", Usage: "override the output language"},
+	// -i is --info, unlike classic hebcal.
+	{Key: "il", Bool: true, Usage: "use the Israeli holiday and sedra schedule"},
+	{Key: "candle_lighting", Bool: true, Usage: "add candle-lighting and havdalah times"},
+	{Key: "candle_lighting_mins", Short: "b", Usage: "light candles this many minutes before sundown"},
+	{Key: "havdalah_mins", Short: "m", Usage: "make havdalah this many minutes after sundown"},
+	{Key: "havdalah_deg", Usage: "make havdalah when the sun is this many degrees below the horizon"},
+	{Key: "omer", Short: "o", Bool: true, Usage: "add days of the Omer"},
+	{Key: "sedrot", Short: "s", Bool: true, Usage: "add the weekly sedra on Saturdays"},
+	{Key: "daily_sedra", Short: "S", Bool: true, Usage: "add the weekly sedra to every day"},
+	{Key: "add_hebrew_dates", Short: "d", Bool: true, Usage: "add the Hebrew date for each day"},
+	{Key: "add_hebrew_dates_for_events", Short: "D", Bool: true, Usage: "add the Hebrew date on days with some event"},
+	{Key: "hour24", Short: "E", Bool: true, Usage: "use 24-hour times"},
+	{Key: "is_hebrew_year", Short: "H", Bool: true, Usage: "use Hebrew date ranges"},
+	{Key: "molad", Short: "M", Bool: true, Usage: "add the molad on Shabbat Mevarchim"},
+	{Key: "sunrise_sunset", Short: "O", Bool: true, Usage: "add sunrise and sunset times"},
+	{Key: "today", Short: "t", Bool: true, Usage: "only show information about today"},
+	{Key: "weekly_abbreviated", Short: "W", Bool: true, Usage: "give a weekly view"},
+	{Key: "no_rosh_chodesh", Short: "x", Bool: true, Usage: "suppress Rosh Chodesh"},
+	{Key: "daily_zmanim", Short: "Z", Bool: true, Usage: "add zmanim for every day"},
+	{Key: "chag_only", Bool: true, Usage: "only show holidays when melacha is prohibited"},
+	{Key: "shiurim", Usage: "comma-separated daily learning schedules, like daf-yomi,nach-yomi"},
+	{Key: "num_years", Usage: "generate events for this many years"},
+}
+
+// AddConfigFlags adds the flags which override config keys to fs,
+// including the generic --set key=value.
+func AddConfigFlags(fs *pflag.FlagSet) {
+	for _, cf := range configFlags {
+		name := strings.ReplaceAll(cf.Key, "_", "-")
+		if cf.Bool {
+			fs.BoolP(name, cf.Short, false, cf.Usage)
+		} else {
+			fs.StringP(name, cf.Short, "", cf.Usage)
+		}
+	}
+
+	fs.StringArray("set", nil,
+		"override any config key, like --set havdalah_deg=8.5 (repeatable)")
+	fs.String("profile", "",
+		"apply a named profile from the config files")
+}
+
+// applyProfile applies the profile named by the --profile flag,
+// or else by the PROFILE environment variable with [config.EnvPrefix],
+// or else by the `profile` key of the config files.
+// If none is named, cfg is unchanged.
+//
+// This happens before the environment variables and the other flags
+// are applied, so that they take precedence over the profile.
+func applyProfile(cfg *config.Config, flagSet *pflag.FlagSet) error {
+	if name := os.Getenv(config.EnvPrefix + "PROFILE"); name != "" {
+		cfg.Profile = name
+	}
+	if f := flagSet.Lookup("profile"); f != nil && f.Changed {
+		cfg.Profile = f.Value.String()
+	}
+
+	resolved, err := cfg.ResolveProfile()
+	if err != nil {
+		return err
+	}
+	*cfg = *resolved
+	return nil
+}
+
+// applyConfigFlags overrides the fields of cfg
+// with the values of the config flags which were given in flagSet.
+// The dedicated flags are applied first, and then each --set in order,
+// so --set has the last word.
+//
+// Flags which were not added by [AddConfigFlags] are ignored.
+func applyConfigFlags(cfg *config.Config, flagSet *pflag.FlagSet) error {
+	var err error
+	flagSet.Visit(func(f *pflag.Flag) {
+		key := strings.ReplaceAll(f.Name, "-", "_")
+		isConfigFlag := slices.ContainsFunc(configFlags, func(cf configFlag) bool {
+			return cf.Key == key
+		})
+		if err != nil || !isConfigFlag {
+			return
+		}
+		if setErr := cfg.SetFrom(config.SourceFlags, key, f.Value.String()); setErr != nil {
+			err = fmt.Errorf("%w: --%s: %w", ErrUsage, f.Name, setErr)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if flagSet.Lookup("set") == nil {
+		return nil
+	}
+	sets, err := flagSet.GetStringArray("set")
+	if err != nil {
+		slog.Error("failed to get --set option", "error", err)
+		return fmt.Errorf("%w: get --set: %w", ErrUnreachable, err)
+	}
+	for _, kv := range sets {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("%w: --set expects key=value, got %q", ErrUsage, kv)
+		}
+		if err := cfg.SetFrom(config.SourceFlags, strings.TrimSpace(key), value); err != nil {
+			return fmt.Errorf("%w: --set: %w", ErrUsage, err)
+		}
+	}
+	return nil
+}
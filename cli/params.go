@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// getParams returns the values of the --param flags by name.
+// If a name is given more than once, the last value wins.
+// The values are checked against the declarations of the template
+// by [templating.BuildData].
+func getParams(flagSet *pflag.FlagSet) (map[string]string, error) {
+	kvs, err := flagSet.GetStringArray("param")
+	if err != nil {
+		slog.Error("failed to get --param option", "error", err)
+		return nil, fmt.Errorf("%w: get --param: %w", ErrUnreachable, err)
+	}
+	if len(kvs) == 0 {
+		return nil, nil
+	}
+
+	params := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: --param expects name=value, got %q", ErrUsage, kv)
+		}
+		params[strings.TrimSpace(name)] = value
+	}
+	return params, nil
+}
+
+// templateFiles returns the files holding the template at tmplPath,
+// and its path in them.
+// Named templates, like @today, are looked up with [namedTemplate];
+// other paths are in files.
+func templateFiles(files fs.FS, tmplPath string) (fs.FS, string, error) {
+	if name, ok := strings.CutPrefix(tmplPath, TemplatePrefix); ok {
+		return namedTemplate(name)
+	}
+	return files, tmplPath, nil
+}
+
+// templateHelp describes how to run the template src,
+// for `--help template.tmpl`.
+// It lists the parameters which the template declares in its front matter.
+func templateHelp(src *templateSource) (string, error) {
+	fm, err := src.frontMatter()
+	if err != nil {
+		return "", err
+	}
+
+	tmplPath := src.Arg
+	switch src.Arg {
+	case ExprTemplateName:
+		tmplPath = "--expr TEXT"
+	case StdinTemplateName:
+		tmplPath = StdinPath
+	}
+
+	paramsUsage := ""
+	if len(fm.Params) != 0 {
+		paramsUsage = " [ --param name=value ... ]"
+	}
+	lines := []string{
+		"usage:",
+		fmt.Sprintf("  %s [ options ] %s%s [[ month [ day ]] year ]",
+			ProgName, tmplPath, paramsUsage),
+	}
+	if fm.Description != "" {
+		lines = append(lines, "", fm.Description)
+	}
+	if len(fm.Params) == 0 {
+		return strings.Join(lines, "\n"), nil
+	}
+
+	names := make([]string, len(fm.Params))
+	width := 0
+	for i, p := range fm.Params {
+		typ := p.Type
+		if typ == "" {
+			typ = "string"
+		}
+		names[i] = fmt.Sprintf("%s=%s", p.Name, strings.ToUpper(typ))
+		width = max(width, len(names[i]))
+	}
+	lines = append(lines, "", "PARAMETERS:")
+	for i, p := range fm.Params {
+		desc := p.Description
+		if p.Default != nil {
+			desc = strings.TrimSpace(fmt.Sprintf("%s (default %s)", desc, p.DefaultString()))
+		}
+		lines = append(lines, strings.TrimRight(
+			fmt.Sprintf("  %-*s  %s", width, names[i], desc), " "))
+	}
+	lines = append(lines, "",
+		fmt.Sprintf("For the other options, run\n  %s --help", ProgName))
+	return strings.Join(lines, "\n"), nil
+}
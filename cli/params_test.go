@@ -0,0 +1,99 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_params(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"params.tmpl": fdata("{{- /*---\ndescription: Counts down\nparams:\n" +
+			"  - name: count\n    type: int\n    default: 3\n    description: where to start\n" +
+			"  - name: label\n  - name: loud\n    type: bool\n---*/ -}}\n" +
+			"{{with $.params.label}}{{.}}: {{end}}{{range $.params.count}}.{{end}}" +
+			"{{if $.params.loud}}!{{end}}"),
+		"stub.tmpl":    fdata("hi"),
+		"invalid.tmpl": fdata("{{- /*---\nparams:\n  - name: count\n    type: integer\n---*/ -}}"),
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+	usagePrefix := fmt.Sprintf("usage:\n  %s [{ --config | -c } config.json ]", cli.ProgName)
+
+	cases := []struct {
+		Args        string
+		Want        string
+		WantLog     string
+		WantLogMode test.WantMode
+		Err         string
+	}{
+		{Args: "params.tmpl", Want: "..."},
+		{
+			Args: "params.tmpl --param count=5 --param label=Go --param loud=true",
+			Want: "Go: .....!",
+		},
+		{Args: "--param count=1 --param count=2 params.tmpl", Want: ".."},
+		{
+			Args: "params.tmpl --param count=many",
+			Err:  `params.tmpl: invalid value for parameter count: strconv.Atoi: parsing "many": invalid syntax`,
+		},
+		{
+			Args: "stub.tmpl --param count=5",
+			Err:  "stub.tmpl: unknown parameter: count",
+		},
+		{
+			Args:        "params.tmpl --param count",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: --param expects name=value, got "count"`,
+		},
+		{
+			Args: "invalid.tmpl",
+			Err: `front matter of invalid.tmpl: parameter count: unknown type "integer"; ` +
+				`expected one of ["string" "int" "bool" "date" "hdate" "duration" "city"]`,
+		},
+		{
+			Args: "--help params.tmpl",
+			Want: fmt.Sprintf("usage:\n"+
+				"  %s [ options ] params.tmpl [ --param name=value ... ] [[ month [ day ]] year ]\n"+
+				"\n"+
+				"Counts down\n"+
+				"\n"+
+				"PARAMETERS:\n"+
+				"  count=INT     where to start (default 3)\n"+
+				"  label=STRING\n"+
+				"  loud=BOOL\n"+
+				"\n"+
+				"For the other options, run\n"+
+				"  %[1]s --help\n", cli.ProgName),
+		},
+		{
+			Args: "--help stub.tmpl",
+			Want: fmt.Sprintf("usage:\n  %s [ options ] stub.tmpl [[ month [ day ]] year ]\n", cli.ProgName),
+		},
+		{
+			Args: "--help missing.tmpl",
+			Err:  "open missing.tmpl: file does not exist",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Args, func(t *testing.T) {
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckString(t, "output", c.Want, buf.String())
+			test.CheckStringMode(t, "logs", c.WantLog, logBuf.String(), c.WantLogMode)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/repl"
+	"github.com/chaimleib/hebcalfmt/templating"
+)
+
+// NewREPLFlags returns a [pflag.FlagSet] configured with the flags
+// used by the repl subcommand.
+func NewREPLFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(ProgName+" repl", pflag.ContinueOnError)
+
+	fs.BoolP("help", "h", false,
+		"print this help text")
+	fs.StringP("config", "c", "",
+		"select a JSON, YAML or TOML config file (default $HOME/.config/hebcalfmt/config.json)")
+	AddConfigFlags(fs)
+
+	return fs
+}
+
+func replUsage(flagUsages string) string {
+	return strings.Join(
+		[]string{
+			"usage:",
+			fmt.Sprintf(
+				"  %s repl [{ --config | -c } config.json ] [ config-overrides ] [[ month [ day ]] year ]",
+				ProgName,
+			),
+			"",
+			"Evaluates template pipelines line by line, read from stdin,",
+			"with the same functions and data as a template,",
+			"and the date range given by the arguments.",
+			"",
+			repl.Help,
+			"",
+			"OPTIONS:",
+			flagUsages,
+		},
+		"\n",
+	)
+}
+
+// runREPL handles the repl subcommand.
+// args should not include the subcommand name itself.
+// Lines are read from [Stdin].
+func runREPL(args []string, files fs.FS, now time.Time, w io.Writer) error {
+	flagSet := NewREPLFlags()
+	if err := flagSet.Parse(args); err != nil {
+		log.Println(replUsage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	help, err := flagSet.GetBool("help")
+	if err != nil {
+		slog.Error("failed to get --help flag", "error", err)
+		return fmt.Errorf("%w: get --help: %w", ErrUnreachable, err)
+	}
+	if help {
+		fmt.Fprintln(w, replUsage(flagSet.FlagUsages()))
+		return nil
+	}
+
+	// The config files are read again by :reload.
+	load := func() (repl.Env, error) {
+		cfg, err := loadConfigFromFlags(files, flagSet, nil, ".")
+		if err != nil {
+			return repl.Env{}, err
+		}
+		cfg.Now = now
+		if err := processDateRangeArgs(flagSet.Args(), cfg); err != nil {
+			return repl.Env{}, err
+		}
+
+		tmpl, data, err := templating.BuildDataText(cfg, files, repl.TemplateName, "")
+		if err != nil {
+			return repl.Env{}, err
+		}
+		return repl.Env{
+			Tmpl:    tmpl,
+			Data:    data,
+			Imports: templating.NewSearchPath(cfg, files, repl.TemplateName),
+		}, nil
+	}
+
+	session, err := repl.New(load)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s repl: for help, type :help\n", ProgName)
+	return session.Run(Stdin, w)
+}
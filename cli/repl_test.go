@@ -0,0 +1,79 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_repl(t *testing.T) {
+	files := fstest.MapFS{
+		"hebcalfmt.json": &fstest.MapFile{Data: []byte(`{"city": "Jerusalem"}`)},
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+	banner := cli.ProgName + " repl: for help, type :help\n"
+	usagePrefix := fmt.Sprintf("usage:\n  %s repl [{ --config | -c } config.json ]", cli.ProgName)
+
+	cases := []struct {
+		Name        string
+		Args        []string
+		Stdin       string
+		Want        string
+		WantMode    test.WantMode
+		WantLog     string
+		WantLogMode test.WantMode
+		Err         string
+	}{
+		{
+			Name:  "project config",
+			Args:  []string{"repl"},
+			Stdin: "$.location.Name\n$d := hdateFromTime $.now\n$d.Next\n",
+			Want:  banner + "> Jerusalem\n> > 2 Tevet 5786\n> \n",
+		},
+		{
+			Name:  "overrides and date range",
+			Args:  []string{"repl", "--city", "Phoenix", "2", "2", "2026"},
+			Stdin: "$.location.Name\n$.dateRange.StartOrToday false\n:q\n",
+			Want:  banner + "> Phoenix\n> 15 Sh'vat 5786\n> ",
+		},
+		{
+			Name:     "help",
+			Args:     []string{"repl", "--help"},
+			Want:     usagePrefix,
+			WantMode: test.WantPrefix,
+		},
+		{
+			Name:        "bad flag",
+			Args:        []string{"repl", "--nosuch"},
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         "usage error: unknown flag: --nosuch",
+		},
+		{
+			Name: "missing config",
+			Args: []string{"repl", "-c", "missing.json"},
+			Err:  "failed to load config: config file could not be read: open missing.json: file does not exist",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			orig := cli.Stdin
+			t.Cleanup(func() { cli.Stdin = orig })
+			cli.Stdin = strings.NewReader(c.Stdin)
+
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(c.Args, files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckStringMode(t, "output", c.Want, buf.String(), c.WantMode)
+			test.CheckStringMode(t, "logs", c.WantLog, logBuf.String(), c.WantLogMode)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/fsys"
+	"github.com/chaimleib/hebcalfmt/server"
+)
+
+// DefaultServeAddr is where `hebcalfmt serve` listens by default.
+const DefaultServeAddr = "localhost:8080"
+
+// ListenAndServe starts the HTTP server for the serve subcommand.
+// It may be replaced for testing.
+var ListenAndServe = http.ListenAndServe
+
+// NewServeFlags returns a [pflag.FlagSet] configured with the flags
+// used by the serve subcommand.
+func NewServeFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet(ProgName+" serve", pflag.ContinueOnError)
+
+	fs.BoolP("help", "h", false,
+		"print this help text")
+	fs.StringP("config", "c", "",
+		"select a JSON, YAML or TOML config file (default $HOME/.config/hebcalfmt/config.json)")
+	fs.String("addr", DefaultServeAddr,
+		"the host:port to listen on")
+	AddConfigFlags(fs)
+
+	return fs
+}
+
+func serveUsage(flagUsages string) string {
+	return strings.Join(
+		[]string{
+			"usage:",
+			fmt.Sprintf(
+				"  %s serve [{ --config | -c } config.json ] [ config-overrides ] [ --addr host:port ] [ templates-dir ]",
+				ProgName,
+			),
+			"",
+			"Serves templates-dir (default .) over HTTP.",
+			"A request for /path/name.ext executes path/name.ext.tmpl.",
+			fmt.Sprintf(
+				"The %q query parameter holds the date range spec, like %s=12+19+2025.",
+				server.DateParam,
+				server.DateParam,
+			),
+			fmt.Sprintf(
+				"The %q query parameter selects a profile from the config.",
+				server.ProfileParam,
+			),
+			"Other query parameters override config keys, like city=Phoenix,",
+			"on top of the config file and the config-overrides flags.",
+			"",
+			"OPTIONS:",
+			flagUsages,
+		},
+		"\n",
+	)
+}
+
+// runServe handles the serve subcommand.
+// args should not include the subcommand name itself.
+func runServe(
+	args []string,
+	files fs.FS,
+	buildData func(
+		cfg *config.Config,
+		files fs.FS,
+		tmplPath string,
+	) (*template.Template, map[string]any, error),
+	w io.Writer,
+) error {
+	flagSet := NewServeFlags()
+	if err := flagSet.Parse(args); err != nil {
+		log.Println(serveUsage(flagSet.FlagUsages()))
+		return fmt.Errorf("%w: %w", ErrUsage, err)
+	}
+
+	help, err := flagSet.GetBool("help")
+	if err != nil {
+		slog.Error("failed to get --help flag", "error", err)
+		return fmt.Errorf("%w: get --help: %w", ErrUnreachable, err)
+	}
+	if help {
+		fmt.Fprintln(w, serveUsage(flagSet.FlagUsages()))
+		return nil
+	}
+
+	addr, err := flagSet.GetString("addr")
+	if err != nil {
+		slog.Error("failed to get --addr option", "error", err)
+		return fmt.Errorf("%w: get --addr: %w", ErrUnreachable, err)
+	}
+
+	dir := "."
+	switch flagSet.NArg() {
+	case 0:
+	case 1:
+		dir = flagSet.Arg(0)
+	default:
+		log.Println(serveUsage(flagSet.FlagUsages()))
+		return fmt.Errorf(
+			"%w: expected at most one templates directory, got %q",
+			ErrUsage,
+			flagSet.Args(),
+		)
+	}
+
+	cfg, err := loadConfigFromFlags(files, flagSet, nil, dir)
+	if err != nil {
+		return err
+	}
+
+	srv := &server.Server{
+		Files:     fsys.WrapFS{BaseDir: dir, FS: files},
+		Config:    cfg,
+		Now:       time.Now,
+		BuildData: buildData,
+
+		// Like on the command line, the front matter of the template
+		// overrides only the system config file.
+		BaseLayers: []string{findConfigFile(files, SystemConfigPath)},
+	}
+
+	fmt.Fprintf(w, "serving %s on http://%s/\n", dir, addr)
+	return ListenAndServe(addr, srv)
+}
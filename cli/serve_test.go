@@ -0,0 +1,188 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_serve(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"phoenix.json":          fdata(`{"city": "Phoenix"}`),
+		"site/city.txt.tmpl":    fdata(`{{$.location.Name}}`),
+		"site/invalid.txt.tmpl": fdata(`{{INVALID`),
+		"site/jerusalem.txt.tmpl": fdata("{{/*---\n" +
+			"config:\n" +
+			"  city: Jerusalem\n" +
+			"---*/}}{{$.location.Name}}"),
+		"site/greet.txt.tmpl": fdata("{{/*---\n" +
+			"params:\n" +
+			"- name: greeting\n" +
+			"---*/}}{{$.params.greeting}}"),
+	}
+	usagePrefix := fmt.Sprintf("usage:\n  %s serve ", cli.ProgName)
+	// The server reads the clock on each request, so this goes unused.
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Args        string
+		Path        string // requested from the handler, if set
+		Want        string
+		WantMode    test.WantMode
+		WantAddr    string
+		WantBody    string
+		WantLog     string
+		WantLogMode test.WantMode
+		Err         string
+	}{
+		{
+			Args:     "serve -h",
+			Want:     usagePrefix,
+			WantMode: test.WantPrefix,
+		},
+		{
+			Args:        "serve --INVALID",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         "usage error: unknown flag: --INVALID",
+		},
+		{
+			Args:        "serve a b",
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         `usage error: expected at most one templates directory, got ["a" "b"]`,
+		},
+		{
+			Args: "serve -c missing.json",
+			Err:  "failed to load config: config file could not be read: open missing.json: file does not exist",
+		},
+		{
+			Args:     "serve site",
+			Path:     "/city.txt",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "New York",
+		},
+		{
+			Args:     "serve -c phoenix.json --addr :9000 site",
+			Path:     "/city.txt?city=Jerusalem",
+			Want:     "serving site on http://:9000/\n",
+			WantAddr: ":9000",
+			WantBody: "Jerusalem",
+		},
+		{
+			Args:     "serve -c phoenix.json site",
+			Path:     "/city.txt",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "Phoenix",
+		},
+		{
+			Args:     "serve -c phoenix.json -C Jerusalem site",
+			Path:     "/city.txt",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "Jerusalem",
+		},
+		{
+			Args:     "serve site",
+			Path:     "/jerusalem.txt",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "Jerusalem",
+		},
+		{
+			Args:     "serve -c phoenix.json site",
+			Path:     "/jerusalem.txt",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "Phoenix",
+		},
+		{
+			Args:     "serve site",
+			Path:     "/jerusalem.txt?city=Phoenix",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "Phoenix",
+		},
+		{
+			Args:     "serve site",
+			Path:     "/greet.txt?greeting=yo",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "yo",
+		},
+		{
+			Args: "serve --set INVALID=1 site",
+			Err:  `usage error: --set: unknown config key: "INVALID"`,
+		},
+		{
+			Args:     "serve site",
+			Path:     "/invalid.txt",
+			Want:     "serving site on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "template: invalid.txt.tmpl:1: function \"INVALID\" not defined\n",
+			WantLog:  "GET /invalid.txt: template: invalid.txt.tmpl:1: function \"INVALID\" not defined\n",
+		},
+		{
+			Args:     "serve ..",
+			Path:     "/city.txt",
+			Want:     "serving .. on http://localhost:8080/\n",
+			WantAddr: cli.DefaultServeAddr,
+			WantBody: "open ../city.txt.tmpl: file does not exist\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Args, func(t *testing.T) {
+			var gotAddr string
+			var gotHandler http.Handler
+			orig := cli.ListenAndServe
+			t.Cleanup(func() { cli.ListenAndServe = orig })
+			cli.ListenAndServe = func(addr string, h http.Handler) error {
+				gotAddr, gotHandler = addr, h
+				return nil
+			}
+
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckStringMode(t, "output", c.Want, buf.String(), c.WantMode)
+			test.CheckString(t, "addr", c.WantAddr, gotAddr)
+
+			if c.Path != "" {
+				rec := httptest.NewRecorder()
+				gotHandler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.Path, nil))
+				test.CheckString(t, "body", c.WantBody, rec.Body.String())
+			}
+			test.CheckStringMode(t, "logs", c.WantLog, logBuf.String(), c.WantLogMode)
+		})
+	}
+
+	t.Run("listen error", func(t *testing.T) {
+		orig := cli.ListenAndServe
+		t.Cleanup(func() { cli.ListenAndServe = orig })
+		cli.ListenAndServe = func(string, http.Handler) error {
+			return errors.New("test: listen failed")
+		}
+
+		var buf bytes.Buffer
+		err := cli.RunInEnvironment(
+			[]string{"serve"}, files, now, templating.BuildData, &buf)
+		test.CheckErr(t, err, "test: listen failed")
+	})
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+)
+
+// Stdin is where a template given as [StdinPath] is read from.
+// It may be replaced for testing.
+var Stdin io.Reader = os.Stdin
+
+// StdinPath, in place of a template path, reads the template from [Stdin].
+const StdinPath = "-"
+
+// Names of the templates which are not read from files,
+// as shown in error messages and in `$.configSource`.
+const (
+	ExprTemplateName  = "<expr>"
+	StdinTemplateName = "<stdin>"
+)
+
+// templateSource is the template to run.
+type templateSource struct {
+	// Arg is the template as given on the command line,
+	// like examples/today.tmpl or @today.
+	// Inline templates go by [ExprTemplateName] or [StdinTemplateName].
+	Arg string
+
+	// Files holds the template, and the files it refers to.
+	// Path is the template in Files, or else the name of an inline template,
+	// in which case other files are relative to the working directory.
+	Files fs.FS
+	Path  string
+
+	// Inline is true for templates given by --expr or on [Stdin],
+	// whose Text is not in a file.
+	Inline bool
+	Text   string
+
+	// DateArgs are the args after the template, giving the date range.
+	DateArgs []string
+}
+
+// getTemplateSource returns the template requested in flagSet:
+// the text of the --expr flag, the text on [Stdin] for [StdinPath],
+// or else the template file named by the first argument.
+// Named templates, like @today, are found with [templateFiles].
+//
+// It returns nil if there is no template,
+// such as when a --format was requested instead.
+func getTemplateSource(files fs.FS, flagSet *pflag.FlagSet) (*templateSource, error) {
+	format, err := flagSet.GetString("format")
+	if err != nil {
+		slog.Error("failed to get --format option", "error", err)
+		return nil, fmt.Errorf("%w: get --format: %w", ErrUnreachable, err)
+	}
+	expr, err := flagSet.GetString("expr")
+	if err != nil {
+		slog.Error("failed to get --expr option", "error", err)
+		return nil, fmt.Errorf("%w: get --expr: %w", ErrUnreachable, err)
+	}
+	exprGiven := flagSet.Lookup("expr").Changed
+
+	switch {
+	case exprGiven && format != "":
+		return nil, fmt.Errorf("%w: --expr and --format cannot be used together", ErrUsage)
+
+	case exprGiven:
+		return &templateSource{
+			Arg:      ExprTemplateName,
+			Files:    files,
+			Path:     ExprTemplateName,
+			Inline:   true,
+			Text:     expr,
+			DateArgs: flagSet.Args(),
+		}, nil
+
+	case format != "" || flagSet.NArg() == 0:
+		return nil, nil
+
+	case flagSet.Arg(0) == StdinPath:
+		text, err := io.ReadAll(Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the template from stdin: %w", err)
+		}
+		return &templateSource{
+			Arg:      StdinTemplateName,
+			Files:    files,
+			Path:     StdinTemplateName,
+			Inline:   true,
+			Text:     string(text),
+			DateArgs: flagSet.Args()[1:],
+		}, nil
+	}
+
+	tmplFiles, fpath, err := templateFiles(files, flagSet.Arg(0))
+	if err != nil {
+		return nil, err
+	}
+	return &templateSource{
+		Arg:      flagSet.Arg(0),
+		Files:    tmplFiles,
+		Path:     fpath,
+		DateArgs: flagSet.Args()[1:],
+	}, nil
+}
+
+// frontMatter returns the front matter of the template.
+func (src *templateSource) frontMatter() (templating.FrontMatter, error) {
+	if src.Inline {
+		return templating.ParseFrontMatter([]byte(src.Text), src.Path)
+	}
+	return templating.ReadFrontMatter(src.Files, src.Path)
+}
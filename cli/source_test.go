@@ -0,0 +1,114 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_inlineTemplates(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"lib.tmpl":       fdata(`{{define "greet"}}Shalom, {{.}}{{end}}`),
+		"hebcalfmt.json": fdata(`{"city": "Jerusalem"}`),
+		"events.txt":     fdata("Kislev 6 Shul kiddush\n"),
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+	usagePrefix := fmt.Sprintf("usage:\n  %s [{ --config | -c } config.json ]", cli.ProgName)
+
+	cases := []struct {
+		Name        string
+		Args        []string
+		Stdin       string
+		Want        string
+		WantLog     string
+		WantLogMode test.WantMode
+		Err         string
+	}{
+		{
+			Name: "expr",
+			Args: []string{"-e", "{{hdateFromTime $.now}}"},
+			Want: "1 Tevet 5786",
+		},
+		{
+			Name: "expr with date range",
+			Args: []string{"--expr", "{{$.dateRange.StartOrToday false}}", "2", "2", "2026"},
+			Want: "15 Sh'vat 5786",
+		},
+		{
+			Name: "expr with import",
+			Args: []string{"-e", `{{import "lib.tmpl"}}{{template "greet" "world"}}`},
+			Want: "Shalom, world",
+		},
+		{
+			Name: "expr with project config",
+			Args: []string{"-e", "{{$.location.Name}} from {{$.configSource}}"},
+			Want: "Jerusalem from hebcalfmt.json",
+		},
+		{
+			Name: "empty expr",
+			Args: []string{"-e", ""},
+		},
+		{
+			Name: "expr parse error",
+			Args: []string{"-e", "{{INVALID"},
+			Err:  "template: <expr>:1: function \"INVALID\" not defined",
+		},
+		{
+			Name:        "expr with format",
+			Args:        []string{"-e", "hi", "--format", "json"},
+			WantLog:     usagePrefix,
+			WantLogMode: test.WantPrefix,
+			Err:         "usage error: --expr and --format cannot be used together",
+		},
+		{
+			Name:  "stdin",
+			Args:  []string{"-", "2026-02-02"},
+			Stdin: "{{$.dateRange.StartOrToday false}}",
+			Want:  "15 Sh'vat 5786",
+		},
+		{
+			Name: "stdin with front matter",
+			Args: []string{"-", "--param", "name=stdin"},
+			Stdin: "{{- /*---\nparams:\n  - name: name\nconfig:\n  events_file: events.txt\n---*/ -}}\n" +
+				"{{$.params.name}} {{$.configSource}}",
+			Want: "stdin <stdin>, hebcalfmt.json",
+		},
+		{
+			Name:  "stdin help",
+			Args:  []string{"--help", "-"},
+			Stdin: "{{- /*---\ndescription: From stdin\n---*/ -}}",
+			Want: fmt.Sprintf("usage:\n  %s [ options ] - [[ month [ day ]] year ]\n\nFrom stdin\n",
+				cli.ProgName),
+		},
+		{
+			Name:  "stdin execution error",
+			Args:  []string{"-"},
+			Stdin: `{{template "missing"}}`,
+			Err:   `template: <stdin>:1:11: executing "<stdin>" at <{{template "missing"}}>: template "missing" not defined`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			orig := cli.Stdin
+			t.Cleanup(func() { cli.Stdin = orig })
+			cli.Stdin = strings.NewReader(c.Stdin)
+
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(c.Args, files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckString(t, "output", c.Want, buf.String())
+			test.CheckStringMode(t, "logs", c.WantLog, logBuf.String(), c.WantLogMode)
+		})
+	}
+}
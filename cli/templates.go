@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/chaimleib/hebcalfmt/examples"
+	"github.com/chaimleib/hebcalfmt/templating"
+)
+
+// StandardTemplates holds the templates built into hebcalfmt,
+// which run by name, like `hebcalfmt @today`.
+// Templates in the [UserTemplateDir] with the same name shadow them.
+var StandardTemplates fs.FS = examples.Templates
+
+// TemplatePrefix starts the name of a template in the
+// [UserTemplateDir] or [StandardTemplates], in place of its path.
+const TemplatePrefix = "@"
+
+// namedTemplate returns the files holding the template called name,
+// and its path in them.
+// The user's templates are looked in first;
+// see [templating.UserTemplates].
+func namedTemplate(name string) (fs.FS, string, error) {
+	fpath := name + ".tmpl"
+	if !fs.ValidPath(fpath) || strings.Contains(name, "/") {
+		return nil, "", fmt.Errorf("invalid template name: %s%s", TemplatePrefix, name)
+	}
+
+	for _, files := range templateLibraries() {
+		_, err := fs.Stat(files, fpath)
+		if err == nil {
+			return files, fpath, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, "", err
+		}
+	}
+	log.Printf("To show the available templates, run\n  %s --info templates", ProgName)
+	return nil, "", fmt.Errorf("unknown template: %s%s", TemplatePrefix, name)
+}
+
+// templateLibraries returns the places where named templates are found,
+// in order of precedence.
+func templateLibraries() []fs.FS {
+	var libs []fs.FS
+	if templating.UserTemplates != nil {
+		libs = append(libs, templating.UserTemplates)
+	}
+	return append(libs, StandardTemplates)
+}
+
+// templatesInfo lists the named templates with their descriptions,
+// for `--info templates`.
+// The user's templates are marked, and shadow the standard ones.
+func templatesInfo() string {
+	type entry struct {
+		name, desc string
+		user       bool
+	}
+	var entries []entry
+	seen := make(map[string]bool)
+	for i, files := range templateLibraries() {
+		isUser := i == 0 && templating.UserTemplates != nil
+		dirEntries, err := fs.ReadDir(files, ".")
+		if err != nil {
+			// A missing user templates directory has no templates.
+			continue
+		}
+		for _, dirEntry := range dirEntries {
+			name, ok := strings.CutSuffix(dirEntry.Name(), ".tmpl")
+			if !ok || dirEntry.IsDir() || strings.HasPrefix(name, ".") || seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			desc := ""
+			fm, err := templating.ReadFrontMatter(files, dirEntry.Name())
+			if err != nil {
+				desc = fmt.Sprintf("(%v)", err)
+			} else {
+				desc = fm.Description
+			}
+			entries = append(entries, entry{name: name, desc: desc, user: isUser})
+		}
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	width := 0
+	for _, e := range entries {
+		width = max(width, len(e.name))
+	}
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		desc := e.desc
+		if e.user {
+			desc = strings.TrimSpace(desc + " [user]")
+		}
+		lines = append(lines, strings.TrimRight(
+			fmt.Sprintf("%s%-*s  %s", TemplatePrefix, width, e.name, desc), " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,132 @@
+package cli_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/cli"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestRunInEnvironment_namedTemplates(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		// A project config in the working directory does not apply.
+		"hebcalfmt.json": fdata(`{"city": "Jerusalem"}`),
+	}
+	userFiles := fstest.MapFS{
+		"today.tmpl": fdata("{{- /*---\ndescription: My own today\n---*/ -}}\n" +
+			`{{import "dates.tmpl"}}{{template "hdate" (map "hdate" (hdateFromTime $.now) "base" $)}}`),
+		"zmanim.tmpl":    fdata(`{{$.location.Name}}`),
+		"invalid.tmpl":   fdata("{{- /*---\nunknown: key\n---*/ -}}"),
+		"notes.txt":      fdata("not a template"),
+		".hidden.tmpl":   fdata("hidden"),
+		"dir/index.tmpl": fdata("in a directory"),
+	}
+	now := time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name     string
+		Args     string
+		User     bool
+		Want     string
+		WantMode test.WantMode
+		WantLog  string
+		Err      string
+	}{
+		{
+			Name: "standard",
+			Args: "@date 2026-02-02",
+			Want: "Gregorian: 2026-02-02\nHebrew: 15 Sh'vat 5786\n",
+		},
+		{
+			Name:     "standard with import",
+			Args:     "@chabad 2026-01-21",
+			Want:     "Z'monim for Wednesday, 2026-01-21 / 3 Sh'vot 5786, in Austin\n",
+			WantMode: test.WantPrefix,
+		},
+		{
+			Name: "standard with events file",
+			Args: "@chabad 2025-12-04",
+			Want: "Z'monim for Thursday, 2025-12-04 / 14 Kisleiv 5786, in Austin\n" +
+				"Anniversary of the Rebbe and Rebbetzin\n",
+			WantMode: test.WantPrefix,
+		},
+		{
+			Name: "shadowed by the user",
+			Args: "@today",
+			User: true,
+			Want: "1 Tevet 5786",
+		},
+		{
+			Name: "user",
+			Args: "@zmanim",
+			User: true,
+			Want: "New York",
+		},
+		{
+			Name: "unknown",
+			Args: "@zmanim",
+			WantLog: fmt.Sprintf(
+				"To show the available templates, run\n  %s --info templates\n", cli.ProgName),
+			Err: "unknown template: @zmanim",
+		},
+		{
+			Name: "invalid name",
+			Args: "@../today",
+			Err:  "invalid template name: @../today",
+		},
+		{
+			Name: "info",
+			Args: "--info templates",
+			Want: `@chabad         The events and zmanim of a day, as Chabad.org calculates them
+@date           Converts a date between the Gregorian and Hebrew calendars
+@hebcalClassic  Holidays and events in the format of classic hebcal
+@mincha         Mincha times for two weeks, rounded before sunset
+@monthCalendar  A Markdown table of a month, with Hebrew dates and holidays
+@thisShabbat    Candle lighting, havdalah and the parsha of this Shabbat
+@today          Today's date, in the Gregorian and Hebrew calendars
+`,
+		},
+		{
+			Name: "info with user templates",
+			Args: "--info templates",
+			User: true,
+			Want: `@chabad         The events and zmanim of a day, as Chabad.org calculates them
+@date           Converts a date between the Gregorian and Hebrew calendars
+@hebcalClassic  Holidays and events in the format of classic hebcal
+@invalid        (front matter of invalid.tmpl: json: unknown field "unknown") [user]
+@mincha         Mincha times for two weeks, rounded before sunset
+@monthCalendar  A Markdown table of a month, with Hebrew dates and holidays
+@thisShabbat    Candle lighting, havdalah and the parsha of this Shabbat
+@today          My own today [user]
+@zmanim         [user]
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			saved := templating.UserTemplates
+			t.Cleanup(func() { templating.UserTemplates = saved })
+			templating.UserTemplates = nil
+			if c.User {
+				templating.UserTemplates = userFiles
+			}
+
+			var buf bytes.Buffer
+			logBuf := test.Logger(t)
+			err := cli.RunInEnvironment(
+				strings.Fields(c.Args), files, now, templating.BuildData, &buf)
+			test.CheckErr(t, err, c.Err)
+			test.CheckStringMode(t, "output", c.Want, buf.String(), c.WantMode)
+			test.CheckString(t, "logs", c.WantLog, logBuf.String())
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"io/fs"
 	"log"
 	"log/slog"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -31,8 +30,10 @@ var ErrUnreachable = errors.New("unreachable code")
 // these are annotated with `json:"-"`
 // to distinguish them from other fields expected in the JSON.
 type Config struct {
-	// ConfigSource names the file which produced this struct.
-	ConfigSource string `json:"-"`
+	// ConfigSource records which layers produced this struct,
+	// and which layer set each key.
+	// Printed, it lists the config files and other layers which were applied.
+	ConfigSource Provenance `json:"-"`
 
 	// DateRange specifies the span of the calendrical data
 	// which hebcal should produce.
@@ -49,6 +50,30 @@ type Config struct {
 	// If nil, use [os.DirFS] starting from the current working directory.
 	FS fs.FS `json:"-"`
 
+	// LayerFS maps the names of layers in ConfigSource
+	// to where the paths to secondary files which they set are resolved,
+	// so that each config file can name files relative to itself.
+	// Paths set by other layers are resolved in FS.
+	// See [Config.FilesFor].
+	LayerFS map[string]fs.FS `json:"-"`
+
+	// Params holds the values given for the parameters
+	// which the template declares in its front matter,
+	// before they are converted to their declared types.
+	// This normally gets parsed from `--param name=value` CLI arguments.
+	Params map[string]string `json:"-"`
+
+	// Profiles holds named sets of config keys,
+	// which are applied on top of the rest of the config when selected.
+	// Each profile is a JSON object like the config itself,
+	// and may also name another profile to apply first,
+	// like `"extends": "base"`.
+	Profiles map[string]json.RawMessage `json:"profiles"`
+
+	// Profile selects one of the Profiles.
+	// It may be overridden with the --profile flag.
+	Profile string `json:"profile"`
+
 	// Language sets the output language.
 	// Available options are in locales.AllLocales.
 	// Default: en
@@ -181,26 +206,53 @@ type Config struct {
 	// Default: 1
 	NumYears int `json:"num_years"`
 
-	// EventsFile is a file of user-defined events.
-	// Each line in the file has this format:
+	// EventsFile names files of user-defined events.
+	// It may be a single path or a list of paths,
+	// and each may be a directory or a glob pattern like "events/*.txt".
+	// Each line in the files has this format:
 	//
 	//   MMMM DD Description
 	//
 	// where MMMM is a string identifying the Hebrew month
 	// and DD is a day number 1 through 30.
 	// Description is a newline-terminated string.
+	// Lines may also use an extended syntax
+	// for recurring, Gregorian and multi-day events,
+	// like "every Tuesday time=20:00 Shiur"; see the README.
+	// Blank lines and lines starting with # are skipped,
+	// and "#include other.txt" reads the lines of another file.
+	// Files ending in .ics are read as iCalendar data instead,
+	// like an export of another calendar app;
+	// their events keep their Gregorian dates, and may repeat yearly.
 	// Events are shown regardless of NoHolidays.
-	EventsFile string `json:"events_file"`
+	EventsFile FileList `json:"events_file"`
 
-	// YahrzeitsFile is a file of yartzeit dates.
-	// Each line is a death-date with this format:
+	// YahrzeitsFile names files of yartzeit dates,
+	// like EventsFile.
+	// Each line is a death-date with one of these formats:
 	//
 	//   MM DD YYYY Description
+	//   MMMM DD YYYY Description
 	//
-	// Where MM, DD and YYYY are the Gregorian date of death.
+	// Where MM, DD and YYYY are the Gregorian date of death,
+	// or MMMM, DD and YYYY are the Hebrew date of death.
 	// Description is a newline-terminated string.
+	// Options may come before the Description,
+	// like "after-sunset", "time=21:15", "adar=both" or "day30=1";
+	// see the README.
+	// Blank lines, comments and includes are allowed, like in EventsFile.
 	// Events are shown regardless of NoHolidays.
-	YahrzeitsFile string `json:"yahrzeits_file"`
+	YahrzeitsFile FileList `json:"yahrzeits_file"`
+
+	// TemplatePath names directories to search for the files
+	// which templates import, like {{import "dates.tmpl"}}.
+	// Like EventsFile, it may be a single path or a list of paths,
+	// relative to the config file.
+	// They are searched after the directory of the template itself,
+	// and before the user's templates directory,
+	// like $HOME/.config/hebcalfmt/templates,
+	// and the library built into hebcalfmt.
+	TemplatePath FileList `json:"template_path"`
 }
 
 // Default holds the default values for [Config].
@@ -210,7 +262,8 @@ var Default = Config{
 	NumYears:           1,
 }
 
-// FromFile parses the file at `configPath` into a [Config] as JSON.
+// FromFile parses the file at `configPath` into a [Config],
+// in the [Format] given by its extension.
 // It sets up the FS so that file references are interpreted
 // relative to the configPath and must be local.
 // This means that file references which fail [filepath.IsLocal],
@@ -218,42 +271,27 @@ var Default = Config{
 // are not allowed.
 // For the sake of debugging,
 // it then populates `ConfigSource` with `configPath`.
+// If the file selects a `profile`, it is applied to the result.
 // NOTE: This does not populate DateRange.
 func FromFile(files fs.FS, configPath string) (*Config, error) {
-	f, err := files.Open(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("config file could not be read: %w", err)
-	}
-	defer f.Close()
-
-	cfg, err := FromReader(f, configPath)
-	if err != nil {
+	cfg := Default
+	if err := cfg.MergeFile(files, configPath); err != nil {
 		return nil, err
 	}
-
-	baseDir := filepath.Dir(configPath)
-	cfg.FS = fsys.WrapFS{BaseDir: baseDir, FS: files}
-
-	return cfg, nil
+	return cfg.ResolveProfile()
 }
 
 // FromReader parses an [io.Reader] into a [Config] as JSON.
 // It does not set an FS.
 // For the sake of debugging,
 // it then populates `ConfigSource` with `configPath`.
+// If the JSON selects a `profile`, it is applied to the result.
 func FromReader(r io.Reader, configPath string) (*Config, error) {
 	cfg := Default
-	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
-		return nil, fmt.Errorf(
-			"failed to parse config from %q: %w",
-			configPath,
-			err,
-		)
+	if err := cfg.MergeJSON(r, configPath); err != nil {
+		return nil, err
 	}
-
-	cfg.ConfigSource = configPath
-
-	return &cfg, nil
+	return cfg.ResolveProfile()
 }
 
 // Normalize returns a version of itself with canonicalized values.
@@ -261,7 +299,32 @@ func FromReader(r io.Reader, configPath string) (*Config, error) {
 // # `Language`
 // An error gets returned if the selected `Language` is unknown.
 // Otherwise, it defaults the `Language` field if unset or lowercases it,
+//
+// # `Profiles`
+// Every profile is applied to a copy and checked the same way,
+// so that a broken profile is reported even if it is not selected.
 func (c Config) Normalize() (*Config, error) {
+	result, err := c.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range c.ProfileNames() {
+		p, err := c.WithProfile(name)
+		if err == nil {
+			_, err = p.normalize()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile %q: %w", name, err)
+		}
+	}
+
+	return result, nil
+}
+
+// normalize does the work of [Config.Normalize],
+// aside from checking the Profiles.
+func (c Config) normalize() (*Config, error) {
 	// work on a copy
 	result := c
 
@@ -279,7 +342,22 @@ func (c Config) Normalize() (*Config, error) {
 // If FS is not set, the [DefaultFS] is used
 // and file references are interpreted
 // relative to the current working directory.
+//
+// Entries of the EventsFile which need the extended syntax are dropped;
+// use [Config.CalOptionsWithExtras] to get them too.
 func (c Config) CalOptions() (*hebcal.CalOptions, error) {
+	cOpts, _, err := c.CalOptionsWithExtras()
+	return cOpts, err
+}
+
+// CalOptionsWithExtras is like [Config.CalOptions],
+// but also returns the [hcfiles.Extras] of the EventsFile and YahrzeitsFile.
+// Their Rules are generated with [hcfiles.HebrewCalendar].
+func (c Config) CalOptionsWithExtras() (
+	*hebcal.CalOptions,
+	hcfiles.Extras,
+	error,
+) {
 	cOpts := new(hebcal.CalOptions)
 
 	cOpts.NoJulian = c.NoJulian
@@ -311,7 +389,7 @@ func (c Config) CalOptions() (*hebcal.CalOptions, error) {
 	// Location
 	loc, err := c.Location()
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve place configs: %w", err)
+		return nil, hcfiles.Extras{}, fmt.Errorf("failed to resolve place configs: %w", err)
 	}
 	cOpts.Location = loc
 	if c.Geo != nil || c.City != "" {
@@ -319,12 +397,12 @@ func (c Config) CalOptions() (*hebcal.CalOptions, error) {
 	}
 
 	if err := c.SetDateRange(cOpts); err != nil {
-		return nil, err
+		return nil, hcfiles.Extras{}, err
 	}
 
 	// YerushalmiYomi, YershushalmiEdition, MishnaYomi, DafYomi, NachYomi
 	if err := SetShiurim(cOpts, c.Shiurim); err != nil {
-		return nil, err
+		return nil, hcfiles.Extras{}, err
 	}
 
 	// AddHebrewDates, Omer, IsHebrewYear
@@ -342,44 +420,73 @@ func (c Config) CalOptions() (*hebcal.CalOptions, error) {
 		cOpts.HavdalahMins = 72
 	}
 
-	// prep for accessing secondary files
-	files := c.FS
-	if c.FS == nil {
-		files, err = fsys.DefaultFS()
-		if err != nil {
-			slog.Error("failed to initialize DefaultFS", "error", err)
-			return nil, fmt.Errorf("failed to initialize DefaultFS: %w", err)
-		}
-	}
-
 	// Read secondary files
 	// UserEvents
-	if c.EventsFile != "" {
-		err = ParseFile(
-			files,
-			c.EventsFile,
-			hcfiles.ParseEvents,
-			&cOpts.UserEvents,
-		)
+	var extras hcfiles.Extras
+	files, err := c.secondaryFS("events_file")
+	if err != nil {
+		return nil, hcfiles.Extras{}, err
+	}
+	eventsFiles, err := c.EventsFile.Expand(files)
+	if err != nil {
+		return nil, hcfiles.Extras{}, fmt.Errorf("events_file: %w", err)
+	}
+	tz, err := hcfiles.TimeZone(cOpts.Location)
+	if err != nil {
+		return nil, hcfiles.Extras{}, err
+	}
+	for _, fpath := range eventsFiles {
+		var events hcfiles.Events
+		err = ParseFile(files, fpath, hcfiles.EventsFileParser(files, fpath, tz), &events)
 		if err != nil {
-			return nil, err
+			return nil, hcfiles.Extras{}, err
+		}
+		if warn := events.Warnings.Build(); warn != nil {
+			log.Println(warn)
+		}
+		cOpts.UserEvents = append(cOpts.UserEvents, events.UserEvents...)
+		extras.Sources.AddUserEvents(fpath, events.UserEvents...)
+		for _, rule := range events.Rules {
+			rule.Source = fpath
+			extras.Rules = append(extras.Rules, rule)
 		}
 	}
 
 	// Yahrzeits
-	if c.YahrzeitsFile != "" {
-		err = ParseFile(
-			files,
-			c.YahrzeitsFile,
-			hcfiles.ParseYahrzeits,
-			&cOpts.Yahrzeits,
-		)
+	files, err = c.secondaryFS("yahrzeits_file")
+	if err != nil {
+		return nil, hcfiles.Extras{}, err
+	}
+	yahrzeitsFiles, err := c.YahrzeitsFile.Expand(files)
+	if err != nil {
+		return nil, hcfiles.Extras{}, fmt.Errorf("yahrzeits_file: %w", err)
+	}
+	for _, fpath := range yahrzeitsFiles {
+		var yahrzeits []hcfiles.Yahrzeit
+		err = ParseFile(files, fpath, hcfiles.YahrzeitsParser(files), &yahrzeits)
 		if err != nil {
-			return nil, err
+			return nil, hcfiles.Extras{}, err
+		}
+		if err := extras.AddYahrzeits(cOpts, fpath, yahrzeits...); err != nil {
+			return nil, hcfiles.Extras{}, fmt.Errorf("yahrzeits_file: %w", err)
 		}
 	}
 
-	return cOpts, nil
+	return cOpts, extras, nil
+}
+
+// secondaryFS returns where the paths in key are resolved,
+// like [Config.FilesFor], or the [DefaultFS] if that is nil.
+func (c *Config) secondaryFS(key string) (fs.FS, error) {
+	if files := c.FilesFor(key); files != nil {
+		return files, nil
+	}
+	files, err := fsys.DefaultFS()
+	if err != nil {
+		slog.Error("failed to initialize DefaultFS", "error", err)
+		return nil, fmt.Errorf("failed to initialize DefaultFS: %w", err)
+	}
+	return files, nil
 }
 
 // SetDateRange validates the `DateRange` of the [Config].
@@ -470,6 +577,9 @@ func (c Config) SetDateRange(cOpts *hebcal.CalOptions) error {
 			)
 		}
 
+	case daterange.RangeTypeSpan:
+		// The ends of the span were validated when it was parsed.
+
 	default:
 		slog.Error("invalid RangeType value", "rangeType", dr.RangeType)
 		return fmt.Errorf(
@@ -492,11 +602,25 @@ func (c Config) SetDateRange(cOpts *hebcal.CalOptions) error {
 
 	case daterange.RangeTypeYear:
 		cOpts.Year = dr.Year
+
+	case daterange.RangeTypeSpan:
+		cOpts.Start = dr.Start(c.NoJulian)
+		cOpts.End = dr.End(c.NoJulian)
 	}
 
 	return nil
 }
 
+// ShiurimOptions lists the values accepted in `shiurim` by [SetShiurim].
+var ShiurimOptions = []string{
+	"daf-yomi",
+	"mishna-yomi",
+	"nach-yomi",
+	"yerushalmi",
+	"yerushalmi:schottenstein",
+	"yerushalmi:vilna",
+}
+
 // SetShiurim reads `shiurim`
 // and sets the appropriate options on the [hebcal.CalOptions].
 //
@@ -506,6 +630,8 @@ func (c Config) SetDateRange(cOpts *hebcal.CalOptions) error {
 //   - `mishna-yomi`
 //   - `daf-yomi`
 //   - `nach-yomi`
+//
+// These are also listed in [ShiurimOptions].
 func SetShiurim(cOpts *hebcal.CalOptions, shiurim []string) error {
 	var unknowns []string
 	for _, shiur := range shiurim {
@@ -1,9 +1,12 @@
 package config_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 	"io/fs"
+	"maps"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -48,6 +51,8 @@ func checkConfig(t *testing.T, want, got *config.Config) {
 		{"DateRange", want.DateRange, got.DateRange},
 		{"Now", want.Now, got.Now},
 		{"FS", want.FS, got.FS},
+		{"Profiles", want.Profiles, got.Profiles},
+		{"Profile", want.Profile, got.Profile},
 		{"Language", want.Language, got.Language},
 		{"City", want.City, got.City},
 		{"Geo", want.Geo, got.Geo},
@@ -112,6 +117,18 @@ func checkConfig(t *testing.T, want, got *config.Config) {
 		case *config.Coordinates:
 			test.CheckNilPtrThen(t, test.CheckCoordinates, field.Name, typedWant, field.Got)
 
+		case config.Provenance:
+			test.CheckProvenance(t, field.Name, typedWant, field.Got.(config.Provenance))
+
+		case map[string]json.RawMessage:
+			typedGot := field.Got.(map[string]json.RawMessage)
+			if !maps.EqualFunc(typedWant, typedGot, func(a, b json.RawMessage) bool {
+				return bytes.Equal(a, b)
+			}) {
+				t.Errorf("%s's do not match - want:\n%s\ngot:\n%s",
+					field.Name, field.Want, field.Got)
+			}
+
 		case []string:
 			typedGot := field.Got.([]string)
 			if !slices.Equal(typedWant, typedGot) {
@@ -119,6 +136,9 @@ func checkConfig(t *testing.T, want, got *config.Config) {
 					field.Name, field.Want, field.Got)
 			}
 
+		case config.FileList:
+			test.CheckSlice(t, field.Name, typedWant, field.Got.(config.FileList))
+
 		default:
 			test.CheckComparable(t, field.Name, field.Want, field.Got)
 		}
@@ -133,7 +153,7 @@ func TestFromFile(t *testing.T) {
 	}
 	baseWant := func(fpath string) *config.Config {
 		cfg := config.Default
-		cfg.ConfigSource = fpath
+		cfg.ConfigSource = config.Provenance{Layers: []string{fpath}}
 		cfg.FS = fsys.WrapFS{
 			BaseDir: filepath.Dir(fpath),
 			FS:      files,
@@ -178,6 +198,7 @@ func TestFromFile(t *testing.T) {
 			Want: func(fpath string) *config.Config {
 				cfg := baseWant(fpath)
 				cfg.Today = true
+				cfg.ConfigSource.Keys = map[string]string{"today": fpath}
 				return cfg
 			},
 		},
@@ -211,7 +232,7 @@ func TestFromReader(t *testing.T) {
 	const fpath = "testConfig.json"
 
 	baseWant := config.Default
-	baseWant.ConfigSource = fpath
+	baseWant.ConfigSource = config.Provenance{Layers: []string{fpath}}
 
 	cases := []struct {
 		Name  string
@@ -326,8 +347,13 @@ func TestCalOptions(t *testing.T) {
 		return &fstest.MapFile{Data: []byte(s)}
 	}
 	files := fstest.MapFS{
-		"events.txt":    file("Tishrei 2 Birthday - Ben Ploni"),
-		"yahrzeits.txt": file("10 8 1967 Yahrzeit - Joe Shmo"),
+		"events.txt":         file("Tishrei 2 Birthday - Ben Ploni"),
+		"yahrzeits.txt":      file("10 8 1967 Yahrzeit - Joe Shmo"),
+		"family/mother.txt":  file("3 1 1990 Yahrzeit - Sarah Shmo"),
+		"family/father.txt":  file("5 6 2001 Yahrzeit - Avi Shmo"),
+		"shul/kiddush.txt":   file("Kislev 6 Shul kiddush"),
+		"shul/readme.md.txt": file("Nisan 1 Shul dinner"),
+		"timed.txt":          file("10 8 1967 time=21:00 Yahrzeit - Joe Shmo"),
 	}
 
 	cases := []struct {
@@ -390,7 +416,7 @@ func TestCalOptions(t *testing.T) {
 			Name: "yahrzeit file",
 			Cfg: func() config.Config {
 				cfg := config.Default
-				cfg.YahrzeitsFile = "yahrzeits.txt"
+				cfg.YahrzeitsFile = config.FileList{"yahrzeits.txt"}
 				cfg.FS = files
 				return cfg
 			}(),
@@ -407,11 +433,32 @@ func TestCalOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "yahrzeit time after sunset",
+			Cfg: func() config.Config {
+				cfg := config.Default
+				cfg.YahrzeitsFile = config.FileList{"timed.txt"}
+				cfg.FS = files
+				return cfg
+			}(),
+			Want: &hebcal.CalOptions{
+				Year:               1,
+				NumYears:           1,
+				CandleLightingMins: 18,
+				Location:           nyc,
+				Yahrzeits: []hebcal.UserYahrzeit{
+					{
+						Name: "Yahrzeit - Joe Shmo",
+						Date: date(1967, time.October, 9),
+					},
+				},
+			},
+		},
 		{
 			Name: "event file",
 			Cfg: func() config.Config {
 				cfg := config.Default
-				cfg.EventsFile = "events.txt"
+				cfg.EventsFile = config.FileList{"events.txt"}
 				cfg.FS = files
 				return cfg
 			}(),
@@ -430,6 +477,33 @@ func TestCalOptions(t *testing.T) {
 			},
 		},
 
+		{
+			Name: "multiple files",
+			Cfg: func() config.Config {
+				cfg := config.Default
+				cfg.EventsFile = config.FileList{"events.txt", "shul"}
+				cfg.YahrzeitsFile = config.FileList{"yahrzeits.txt", "family/*.txt"}
+				cfg.FS = files
+				return cfg
+			}(),
+			Want: &hebcal.CalOptions{
+				Year:               1,
+				NumYears:           1,
+				CandleLightingMins: 18,
+				Location:           nyc,
+				UserEvents: []hebcal.UserEvent{
+					{Desc: "Birthday - Ben Ploni", Day: 2, Month: hdate.Tishrei},
+					{Desc: "Shul kiddush", Day: 6, Month: hdate.Kislev},
+					{Desc: "Shul dinner", Day: 1, Month: hdate.Nisan},
+				},
+				Yahrzeits: []hebcal.UserYahrzeit{
+					{Name: "Yahrzeit - Joe Shmo", Date: date(1967, time.October, 8)},
+					{Name: "Yahrzeit - Avi Shmo", Date: date(2001, time.May, 6)},
+					{Name: "Yahrzeit - Sarah Shmo", Date: date(1990, time.March, 1)},
+				},
+			},
+		},
+
 		// Errors
 		{
 			Name: "unknown city",
@@ -467,17 +541,27 @@ func TestCalOptions(t *testing.T) {
 			Name: "invalid yahrzeit file",
 			Cfg: func() config.Config {
 				cfg := config.Default
-				cfg.YahrzeitsFile = "nonexistent.txt"
+				cfg.YahrzeitsFile = config.FileList{"nonexistent.txt"}
 				cfg.FS = files
 				return cfg
 			}(),
 			Err: "open nonexistent.txt: file does not exist",
 		},
+		{
+			Name: "unmatched events pattern",
+			Cfg: func() config.Config {
+				cfg := config.Default
+				cfg.EventsFile = config.FileList{"missing/*.txt"}
+				cfg.FS = files
+				return cfg
+			}(),
+			Err: `events_file: no files match "missing/*.txt"`,
+		},
 		{
 			Name: "invalid event file",
 			Cfg: func() config.Config {
 				cfg := config.Default
-				cfg.EventsFile = "nonexistent.txt"
+				cfg.EventsFile = config.FileList{"nonexistent.txt"}
 				cfg.FS = files
 				return cfg
 			}(),
@@ -559,6 +643,35 @@ func TestSetDateRange(t *testing.T) {
 				End:   hdate.New(5790, hdate.Adar1, 25),
 			},
 		},
+		{
+			Name: "DateRange of span",
+			Cfg: &config.Config{
+				NumYears: 1,
+				DateRange: &daterange.DateRange{
+					Source: daterange.Source{
+						Args: []string{"2026-03-15..2026-04-20"},
+						Now:  now,
+					},
+					RangeType: daterange.RangeTypeSpan,
+					From: &daterange.DateRange{
+						RangeType: daterange.RangeTypeDay,
+						Year:      2026,
+						GregMonth: time.March,
+						Day:       15,
+					},
+					Until: &daterange.DateRange{
+						RangeType: daterange.RangeTypeDay,
+						Year:      2026,
+						GregMonth: time.April,
+						Day:       20,
+					},
+				},
+			},
+			Want: &hebcal.CalOptions{
+				Start: hdate.New(5786, hdate.Adar1, 26),
+				End:   hdate.New(5786, hdate.Iyyar, 3),
+			},
+		},
 		{
 			Name: "DateRange of Gregorian day",
 			Cfg: &config.Config{
@@ -1,6 +1,11 @@
 package config
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // Coordinates holds a latitude-longitude pair.
 type Coordinates struct {
@@ -22,3 +27,36 @@ func (c *Coordinates) Validate() error {
 	}
 	return nil
 }
+
+// UnmarshalText parses a `lat,lon` pair, like `31.778,35.235`.
+// This allows geo to be given as a single string,
+// as with the -l flag of classic hebcal.
+func (c *Coordinates) UnmarshalText(text []byte) error {
+	latStr, lonStr, ok := strings.Cut(string(text), ",")
+	if !ok {
+		return fmt.Errorf("expected coordinates as lat,lon, got %q", text)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return fmt.Errorf("invalid latitude: %w", err)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil {
+		return fmt.Errorf("invalid longitude: %w", err)
+	}
+	c.Lat, c.Lon = lat, lon
+	return nil
+}
+
+// UnmarshalJSON accepts either an object like `{"lat": 31.778, "lon": 35.235}`
+// or a `lat,lon` string, as parsed by [Coordinates.UnmarshalText].
+func (c *Coordinates) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		return c.UnmarshalText([]byte(s))
+	}
+
+	// The alias drops the methods, to avoid recursing.
+	type coordinates Coordinates
+	return json.Unmarshal(data, (*coordinates)(c))
+}
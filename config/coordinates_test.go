@@ -1,9 +1,11 @@
 package config_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/test"
 )
 
 func TestCoordinates_Validate(t *testing.T) {
@@ -54,3 +56,81 @@ func TestCoordinates_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestCoordinates_UnmarshalText(t *testing.T) {
+	cases := []struct {
+		Input string
+		Want  config.Coordinates
+		Err   string
+	}{
+		{Input: "31.778,35.235", Want: config.Coordinates{31.778, 35.235}},
+		{Input: " -33.9 , 18.4 ", Want: config.Coordinates{-33.9, 18.4}},
+		{
+			Input: "31.778",
+			Err:   `expected coordinates as lat,lon, got "31.778"`,
+		},
+		{
+			Input: "north,35.235",
+			Err:   `invalid latitude: strconv.ParseFloat: parsing "north": invalid syntax`,
+		},
+		{
+			Input: "31.778,east",
+			Err:   `invalid longitude: strconv.ParseFloat: parsing "east": invalid syntax`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			var got config.Coordinates
+			err := got.UnmarshalText([]byte(c.Input))
+			test.CheckErr(t, err, c.Err)
+			if err == nil && got != c.Want {
+				t.Errorf("want: %v, got: %v", c.Want, got)
+			}
+		})
+	}
+}
+
+func TestCoordinates_UnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Input string
+		Want  config.Coordinates
+		Err   string
+	}{
+		{
+			Name:  "object",
+			Input: `{"lat": 31.778, "lon": 35.235}`,
+			Want:  config.Coordinates{31.778, 35.235},
+		},
+		{
+			Name:  "partial object",
+			Input: `{"lon": 35.235}`,
+			Want:  config.Coordinates{10, 35.235},
+		},
+		{
+			Name:  "string",
+			Input: `"31.778,35.235"`,
+			Want:  config.Coordinates{31.778, 35.235},
+		},
+		{
+			Name:  "invalid string",
+			Input: `"31.778"`,
+			Err:   `expected coordinates as lat,lon, got "31.778"`,
+		},
+		{
+			Name:  "invalid type",
+			Input: `[31.778, 35.235]`,
+			Err:   `json: cannot unmarshal array into Go value of type config.coordinates`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got := config.Coordinates{Lat: 10, Lon: 20}
+			err := json.Unmarshal([]byte(c.Input), &got)
+			test.CheckErr(t, err, c.Err)
+			if err == nil && got != c.Want {
+				t.Errorf("want: %v, got: %v", c.Want, got)
+			}
+		})
+	}
+}
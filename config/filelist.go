@@ -0,0 +1,87 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+)
+
+// FileList names secondary files, like the EventsFile.
+// In JSON, it may be a single string or a list of strings.
+// Each item may be a path to a file, a directory or a glob pattern;
+// see [FileList.Expand].
+type FileList []string
+
+// UnmarshalJSON accepts either a string, like `"events.txt"`,
+// or a list of strings, like `["events.txt", "family/*.txt"]`.
+func (l *FileList) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		*l = nil
+		if s != "" {
+			*l = FileList{s}
+		}
+		return nil
+	}
+
+	// The alias drops the methods, to avoid recursing.
+	type fileList FileList
+	return json.Unmarshal(data, (*fileList)(l))
+}
+
+// Expand returns the paths of the files named by l in files, in order.
+//
+//   - A glob pattern, as understood by [path.Match],
+//     is replaced by the paths which match it, in lexical order.
+//     Like in a shell, hidden files only match patterns for hidden files.
+//     It is an error if there are no matches.
+//   - A directory is replaced by the paths of the files in it,
+//     in lexical order, skipping subdirectories and hidden files.
+//   - Other items are kept as they are,
+//     so that [ParseFile] reports any problem with opening them.
+func (l FileList) Expand(files fs.FS) ([]string, error) {
+	var paths []string
+	for _, item := range l {
+		matches := []string{item}
+		if strings.ContainsAny(item, `*?[\`) {
+			var err error
+			matches, err = fs.Glob(files, item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", item, err)
+			}
+			if !isHidden(item) {
+				matches = slices.DeleteFunc(matches, isHidden)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match %q", item)
+			}
+		}
+
+		for _, match := range matches {
+			info, err := fs.Stat(files, match)
+			if err != nil || !info.IsDir() {
+				paths = append(paths, match)
+				continue
+			}
+			entries, err := fs.ReadDir(files, match)
+			if err != nil {
+				return nil, err
+			}
+			for _, entry := range entries {
+				if entry.IsDir() || isHidden(entry.Name()) {
+					continue
+				}
+				paths = append(paths, path.Join(match, entry.Name()))
+			}
+		}
+	}
+	return paths, nil
+}
+
+// isHidden reports whether the base name of fpath starts with a dot.
+func isHidden(fpath string) bool {
+	return strings.HasPrefix(path.Base(fpath), ".")
+}
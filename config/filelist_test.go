@@ -0,0 +1,108 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestFileList_UnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Input string
+		Want  config.FileList
+		Err   string
+	}{
+		{Name: "string", Input: `"events.txt"`, Want: config.FileList{"events.txt"}},
+		{Name: "empty string", Input: `""`, Want: nil},
+		{
+			Name:  "list",
+			Input: `["events.txt", "family/*.txt"]`,
+			Want:  config.FileList{"events.txt", "family/*.txt"},
+		},
+		{
+			Name:  "number",
+			Input: `5`,
+			Err:   "json: cannot unmarshal number into Go value of type config.fileList",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var got config.FileList
+			err := json.Unmarshal([]byte(c.Input), &got)
+			test.CheckErr(t, err, c.Err)
+			test.CheckSlice(t, "FileList", c.Want, got)
+		})
+	}
+}
+
+func TestFileList_Expand(t *testing.T) {
+	file := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"shul.txt":                file(""),
+		"family/mother.txt":       file(""),
+		"family/father.txt":       file(""),
+		"family/.hidden.txt":      file(""),
+		"family/notes.md":         file(""),
+		"family/archive/old.txt":  file(""),
+		"yahrzeits/a/mother.txt":  file(""),
+		"yahrzeits/b/father.txt":  file(""),
+		"yahrzeits/b/.hidden.txt": file(""),
+	}
+
+	cases := []struct {
+		Name string
+		List config.FileList
+		Want []string
+		Err  string
+	}{
+		{Name: "empty"},
+		{
+			Name: "files in order",
+			List: config.FileList{"shul.txt", "family/mother.txt"},
+			Want: []string{"shul.txt", "family/mother.txt"},
+		},
+		{
+			Name: "directory",
+			List: config.FileList{"family"},
+			Want: []string{"family/father.txt", "family/mother.txt", "family/notes.md"},
+		},
+		{
+			Name: "glob",
+			List: config.FileList{"shul.txt", "family/*.txt"},
+			Want: []string{"shul.txt", "family/father.txt", "family/mother.txt"},
+		},
+		{
+			Name: "glob of directories",
+			List: config.FileList{"yahrzeits/*"},
+			Want: []string{"yahrzeits/a/mother.txt", "yahrzeits/b/father.txt"},
+		},
+		{
+			Name: "missing file is kept",
+			List: config.FileList{"nonexistent.txt"},
+			Want: []string{"nonexistent.txt"},
+		},
+		{
+			Name: "glob without matches",
+			List: config.FileList{"missing/*.txt"},
+			Err:  `no files match "missing/*.txt"`,
+		},
+		{
+			Name: "invalid glob",
+			List: config.FileList{"family/[.txt"},
+			Err:  `invalid pattern "family/[.txt": syntax error in pattern`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := c.List.Expand(files)
+			test.CheckErr(t, err, c.Err)
+			test.CheckSlice(t, "paths", c.Want, got)
+		})
+	}
+}
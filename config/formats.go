@@ -0,0 +1,316 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the syntax of a config file.
+// Every format is converted to JSON with [ToJSON],
+// so the keys and values are the same in all of them.
+type Format string
+
+const (
+	// FormatJSON is JSON, with `//` and `/* */` comments
+	// and trailing commas allowed.
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// Extensions lists the config file extensions recognized by [FormatOf],
+// in the order they are looked for.
+var Extensions = []string{".json", ".jsonc", ".yaml", ".yml", ".toml"}
+
+// FormatOf returns the [Format] of the config file at path,
+// from its extension.
+// Unrecognized extensions are read as JSON.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// position is a line and column in a config file, counting from 1.
+type position struct {
+	Line, Col int
+}
+
+// ToJSON converts data, the contents of a config file in format, to JSON.
+// Comments are dropped.
+func ToJSON(data []byte, format Format) ([]byte, error) {
+	result, _, err := toJSON(data, format)
+	return result, err
+}
+
+// toJSON is like [ToJSON], but also returns the positions
+// of the keys in the original data, by dotted path.
+// For JSON, the positions are nil,
+// since the offsets of the result match those of data.
+func toJSON(data []byte, format Format) ([]byte, map[string]position, error) {
+	switch format {
+	case FormatYAML:
+		return yamlToJSON(data)
+	case FormatTOML:
+		return tomlToJSON(data)
+	default:
+		return stripJSONComments(data), nil, nil
+	}
+}
+
+// stripJSONComments replaces the comments in data with spaces,
+// along with any commas which trail the last item in an object or array,
+// so that the result is plain JSON with the same offsets as data.
+// Newlines in block comments are kept, so that lines still match.
+func stripJSONComments(data []byte) []byte {
+	result := bytes.Clone(data)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if result[i] != '\n' {
+				result[i] = ' '
+			}
+		}
+	}
+
+	// Blank the comments.
+	for i := 0; i < len(result); i++ {
+		switch {
+		case result[i] == '"':
+			i = skipJSONString(result, i)
+		case bytes.HasPrefix(result[i:], []byte("//")):
+			end := bytes.IndexByte(result[i:], '\n')
+			if end < 0 {
+				end = len(result) - i
+			}
+			blank(i, i+end)
+			i += end
+		case bytes.HasPrefix(result[i:], []byte("/*")):
+			end := bytes.Index(result[i+2:], []byte("*/"))
+			if end < 0 {
+				// Leave it for the JSON decoder to report.
+				return result
+			}
+			blank(i, i+2+end+2)
+			i += 2 + end + 1
+		}
+	}
+
+	// Blank the trailing commas.
+	for i := 0; i < len(result); i++ {
+		switch result[i] {
+		case '"':
+			i = skipJSONString(result, i)
+		case ',':
+			next := skipSpace(result, i+1)
+			if next < len(result) && (result[next] == '}' || result[next] == ']') {
+				result[i] = ' '
+			}
+		}
+	}
+	return result
+}
+
+// skipJSONString returns the offset of the quote
+// which ends the string starting at data[start].
+func skipJSONString(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return len(data)
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && strings.IndexByte(" \t\r\n", data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// yamlToJSON converts a YAML document to JSON,
+// keeping the keys in their original order.
+func yamlToJSON(data []byte) ([]byte, map[string]position, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	positions := make(map[string]position)
+	if len(doc.Content) == 0 {
+		return []byte("{}"), positions, nil
+	}
+
+	var b bytes.Buffer
+	if err := writeYAMLNode(&b, doc.Content[0], "", positions); err != nil {
+		return nil, nil, err
+	}
+	return b.Bytes(), positions, nil
+}
+
+// writeYAMLNode writes n to b as JSON.
+// The positions of mapping keys are recorded by their dotted path,
+// starting with prefix.
+func writeYAMLNode(
+	b *bytes.Buffer,
+	n *yaml.Node,
+	prefix string,
+	positions map[string]position,
+) error {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return writeYAMLNode(b, n.Alias, prefix, positions)
+
+	case yaml.MappingNode:
+		b.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if key.Kind != yaml.ScalarNode {
+				return fmt.Errorf("yaml: line %d: expected a string key", key.Line)
+			}
+			path := key.Value
+			if prefix != "" {
+				path = prefix + "." + key.Value
+			}
+			positions[path] = position{Line: key.Line, Col: key.Column}
+
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			encoded, err := json.Marshal(key.Value)
+			if err != nil {
+				return err
+			}
+			b.Write(encoded)
+			b.WriteByte(':')
+			if err := writeYAMLNode(b, value, path, positions); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+		return nil
+
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeYAMLNode(b, item, prefix, positions); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+		return nil
+
+	default:
+		// JSON has no timestamps, so keep dates like 2025-12-14 as written,
+		// rather than as the time.Time which YAML decodes them to.
+		if n.ShortTag() == "!!timestamp" {
+			encoded, err := json.Marshal(n.Value)
+			if err != nil {
+				return err
+			}
+			b.Write(encoded)
+			return nil
+		}
+
+		var value any
+		if err := n.Decode(&value); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("yaml: line %d: %w", n.Line, err)
+		}
+		b.Write(encoded)
+		return nil
+	}
+}
+
+// tomlToJSON converts a TOML document to JSON.
+func tomlToJSON(data []byte) ([]byte, map[string]position, error) {
+	var obj map[string]any
+	if _, err := toml.Decode(string(data), &obj); err != nil {
+		return nil, nil, err
+	}
+	result, err := json.Marshal(obj)
+	if err != nil {
+		return nil, nil, fmt.Errorf("toml: %w", err)
+	}
+	return result, tomlPositions(data), nil
+}
+
+// tomlPositions finds the keys in a TOML document by their dotted paths,
+// including those in table headers like `[profiles.israel]`.
+// Keys inside inline tables are not found;
+// their problems are reported at the enclosing key.
+func tomlPositions(data []byte) map[string]position {
+	positions := make(map[string]position)
+	table := ""
+	inMultiline := false
+	for i, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		col := 1 + utf8.RuneCountInString(line[:len(line)-len(trimmed)])
+
+		// Multi-line strings may contain anything, so skip them.
+		if n := strings.Count(trimmed, `"""`) + strings.Count(trimmed, `'''`); n%2 == 1 {
+			inMultiline = !inMultiline
+			if !inMultiline {
+				continue
+			}
+		} else if inMultiline {
+			continue
+		}
+
+		switch {
+		case trimmed == "", strings.HasPrefix(trimmed, "#"):
+			continue
+
+		case strings.HasPrefix(trimmed, "["):
+			header := strings.Trim(trimmed, "[] \t\r")
+			if end := strings.Index(header, "]"); end >= 0 {
+				header = header[:end]
+			}
+			table = strings.Join(tomlKeyParts(header), ".")
+			positions[table] = position{Line: i + 1, Col: col}
+
+		default:
+			key, _, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				continue
+			}
+			path := strings.Join(tomlKeyParts(key), ".")
+			if table != "" {
+				path = table + "." + path
+			}
+			if _, ok := positions[path]; !ok {
+				positions[path] = position{Line: i + 1, Col: col}
+			}
+		}
+	}
+	return positions
+}
+
+// tomlKeyParts splits a dotted TOML key into its unquoted parts.
+func tomlKeyParts(key string) []string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return parts
+}
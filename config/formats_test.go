@@ -0,0 +1,124 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestFormatOf(t *testing.T) {
+	cases := []struct {
+		Path string
+		Want config.Format
+	}{
+		{Path: "config.json", Want: config.FormatJSON},
+		{Path: "config.jsonc", Want: config.FormatJSON},
+		{Path: "dir/config.yaml", Want: config.FormatYAML},
+		{Path: "config.YML", Want: config.FormatYAML},
+		{Path: "hebcalfmt.toml", Want: config.FormatTOML},
+		{Path: "config", Want: config.FormatJSON},
+	}
+	for _, c := range cases {
+		t.Run(c.Path, func(t *testing.T) {
+			test.CheckComparable(t, "FormatOf", c.Want, config.FormatOf(c.Path))
+		})
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Format config.Format
+		Input  string
+		Want   string
+		Err    string
+	}{
+		{
+			Name:   "json",
+			Format: config.FormatJSON,
+			Input:  `{"city": "Jerusalem"}`,
+			Want:   `{"city": "Jerusalem"}`,
+		},
+		{
+			Name:   "json comments keep offsets",
+			Format: config.FormatJSON,
+			Input:  "{ // line\n\"a\": \"//x\", /* b\nc */ \"d\": [1,],\n}",
+			Want:   "{        \n\"a\": \"//x\",     \n     \"d\": [1 ] \n}",
+		},
+		{
+			Name:   "json escaped quote",
+			Format: config.FormatJSON,
+			Input:  `{"a": "\"//", "b": 1,}`,
+			Want:   `{"a": "\"//", "b": 1 }`,
+		},
+		{
+			Name:   "json unterminated comment",
+			Format: config.FormatJSON,
+			Input:  `{"a": 1} /* x`,
+			Want:   `{"a": 1} /* x`,
+		},
+		{
+			Name:   "yaml",
+			Format: config.FormatYAML,
+			Input: `# comment
+city: Jerusalem
+candle_lighting_mins: 40
+shiurim: [daf-yomi, nach-yomi]
+geo: {lat: 31.778, lon: 35.235}
+base: &base
+  omer: true
+profiles:
+  a: *base
+`,
+			Want: `{"city":"Jerusalem","candle_lighting_mins":40,` +
+				`"shiurim":["daf-yomi","nach-yomi"],` +
+				`"geo":{"lat":31.778,"lon":35.235},` +
+				`"base":{"omer":true},"profiles":{"a":{"omer":true}}}`,
+		},
+		{Name: "yaml empty", Format: config.FormatYAML, Input: "", Want: `{}`},
+		{
+			Name:   "yaml dates stay strings",
+			Format: config.FormatYAML,
+			Input:  "date: 2025-12-14\ntime: 2025-12-14T08:30:00Z\nquoted: \"2025-12-14\"\n",
+			Want:   `{"date":"2025-12-14","time":"2025-12-14T08:30:00Z","quoted":"2025-12-14"}`,
+		},
+		{
+			Name:   "yaml syntax error",
+			Format: config.FormatYAML,
+			Input:  "city: Jerusalem\n  omer: true\n",
+			Err:    "yaml: line 2: mapping values are not allowed in this context",
+		},
+		{
+			Name:   "yaml complex key",
+			Format: config.FormatYAML,
+			Input:  "? [a]\n: 1\n",
+			Err:    "yaml: line 1: expected a string key",
+		},
+		{
+			Name:   "toml",
+			Format: config.FormatTOML,
+			Input: `# comment
+city = "Jerusalem"
+shiurim = ["daf-yomi"]
+
+[geo]
+lat = 31.778
+`,
+			Want: `{"city":"Jerusalem","geo":{"lat":31.778},"shiurim":["daf-yomi"]}`,
+		},
+		{
+			Name:   "toml syntax error",
+			Format: config.FormatTOML,
+			Input:  "city = Jerusalem\n",
+			Err:    `toml: line 1 (last key "city"): expected value but found "Jerusalem" instead`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := config.ToJSON([]byte(c.Input), c.Format)
+			test.CheckErr(t, err, c.Err)
+			test.CheckString(t, "ToJSON", c.Want, string(got))
+		})
+	}
+}
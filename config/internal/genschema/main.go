@@ -0,0 +1,21 @@
+// Command genschema writes schema.json in the current directory,
+// from the Config struct in the config package.
+// It is run by `go generate ./config`.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/chaimleib/hebcalfmt/config"
+)
+
+func main() {
+	data, err := config.GenerateSchema(os.DirFS("."))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("schema.json", data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
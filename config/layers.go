@@ -0,0 +1,347 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/chaimleib/hebcalfmt/fsys"
+	"github.com/chaimleib/hebcalfmt/warning"
+)
+
+// Names of the config layers which do not come from files.
+const (
+	SourceDefault = "default"
+	SourceEnv     = "env"
+	SourceFlags   = "flags"
+	SourceQuery   = "query"
+)
+
+// EnvPrefix starts the names of environment variables which set config keys,
+// like HEBCALFMT_CITY=Jerusalem.
+// Dots in nested keys are written as double underscores,
+// like HEBCALFMT_GEO__LAT=31.778.
+const EnvPrefix = "HEBCALFMT_"
+
+// fileKeys are the config keys which name secondary files.
+// These are resolved relative to the layer which set them.
+var fileKeys = []string{"events_file", "yahrzeits_file", "template_path"}
+
+// Provenance records where the values in a [Config] came from.
+// Each config file, the environment, or the CLI flags make up a layer,
+// and later layers override earlier ones.
+type Provenance struct {
+	// Layers names the layers which were applied,
+	// in order of increasing precedence.
+	Layers []string
+
+	// Keys maps each JSON key which was set, like `city` or `geo.lat`,
+	// to the name of the layer which set it last.
+	Keys map[string]string
+}
+
+// String lists the names of the layers which were applied.
+// If only one config file was loaded, this is its path.
+func (p Provenance) String() string {
+	return strings.Join(p.Layers, ", ")
+}
+
+// Of returns the name of the layer which set key,
+// or [SourceDefault] if no layer set it.
+// For a nested key like `geo.lat`,
+// a layer which set the whole `geo` object counts.
+func (p Provenance) Of(key string) string {
+	for k := key; k != ""; {
+		if src, ok := p.Keys[k]; ok {
+			return src
+		}
+		i := strings.LastIndex(k, ".")
+		if i < 0 {
+			break
+		}
+		k = k[:i]
+	}
+	return SourceDefault
+}
+
+// record notes that layer set key,
+// replacing what was recorded for any of its nested keys.
+func (p *Provenance) record(layer, key string) {
+	if p.Keys == nil {
+		p.Keys = make(map[string]string)
+	}
+	for k := range p.Keys {
+		if strings.HasPrefix(k, key+".") {
+			delete(p.Keys, k)
+		}
+	}
+	p.Keys[key] = layer
+}
+
+// addLayer appends layer to Layers, unless it is already the last one.
+func (p *Provenance) addLayer(layer string) {
+	if n := len(p.Layers); n > 0 && p.Layers[n-1] == layer {
+		return
+	}
+	p.Layers = append(p.Layers, layer)
+}
+
+// clone returns a deep copy, so that records can be added
+// without affecting other Configs sharing the original.
+func (p Provenance) clone() Provenance {
+	return Provenance{Layers: slices.Clone(p.Layers), Keys: maps.Clone(p.Keys)}
+}
+
+// SetsFiles reports whether layer was the last to set
+// any path to a secondary file, like `events_file`.
+func (p Provenance) SetsFiles(layer string) bool {
+	for _, key := range fileKeys {
+		if p.Of(key) == layer {
+			return true
+		}
+	}
+	return false
+}
+
+// FilesFor returns where the paths in key,
+// one of the keys naming secondary files like `events_file`, are resolved:
+// the entry in LayerFS for the layer which set key, or else FS.
+func (c *Config) FilesFor(key string) fs.FS {
+	if files, ok := c.LayerFS[c.ConfigSource.Of(key)]; ok {
+		return files
+	}
+	return c.FS
+}
+
+// SetLayerFS records that the paths to secondary files
+// set by layer are resolved in files.
+func (c *Config) SetLayerFS(layer string, files fs.FS) {
+	c.LayerFS = maps.Clone(c.LayerFS)
+	if c.LayerFS == nil {
+		c.LayerFS = make(map[string]fs.FS)
+	}
+	c.LayerFS[layer] = files
+}
+
+// MergeJSON applies the JSON object read from r on top of c,
+// as the layer named name.
+// Only the keys present in the JSON are changed,
+// including the keys of nested objects like `geo`.
+func (c *Config) MergeJSON(r io.Reader, name string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read config from %q: %w", name, err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&raw); err != nil {
+		return fmt.Errorf("failed to parse config from %q: %w", name, err)
+	}
+
+	// Decode into a copy, so that c is unchanged on error,
+	// and so that nested objects shared with other Configs are not modified.
+	merged := *c
+	if merged.Geo != nil {
+		geo := *merged.Geo
+		merged.Geo = &geo
+	}
+	merged.Profiles = maps.Clone(merged.Profiles)
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&merged); err != nil {
+		return fmt.Errorf("failed to parse config from %q: %w", name, err)
+	}
+
+	merged.ConfigSource = c.ConfigSource.clone()
+	merged.ConfigSource.addLayer(name)
+	for key, value := range raw {
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(value, &nested) != nil || len(nested) == 0 {
+			merged.ConfigSource.record(name, key)
+			continue
+		}
+		for sub := range nested {
+			merged.ConfigSource.record(name, key+"."+sub)
+		}
+	}
+
+	*c = merged
+	return nil
+}
+
+// MergeJSONUnder is like [Config.MergeJSON],
+// but the new layer goes beneath the layers of c
+// other than [SourceDefault] and the given base layers.
+// Keys which the higher layers set keep their values.
+//
+// This lets the front matter of a template provide defaults
+// for a config which was loaded already, as in `hebcalfmt serve`.
+func (c *Config) MergeJSONUnder(r io.Reader, name string, base ...string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read config from %q: %w", name, err)
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&raw); err != nil {
+		return fmt.Errorf("failed to parse config from %q: %w", name, err)
+	}
+
+	isBase := func(layer string) bool {
+		return layer == SourceDefault || slices.Contains(base, layer)
+	}
+
+	// Drop the keys which higher layers set,
+	// so that merging the rest on top leaves those alone.
+	for key, value := range raw {
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(value, &nested) != nil || len(nested) == 0 {
+			if !isBase(c.ConfigSource.Of(key)) {
+				delete(raw, key)
+			}
+			continue
+		}
+		for sub := range nested {
+			if !isBase(c.ConfigSource.Of(key + "." + sub)) {
+				delete(nested, sub)
+			}
+		}
+		if len(nested) == 0 {
+			delete(raw, key)
+			continue
+		}
+		if raw[key], err = json.Marshal(nested); err != nil {
+			return fmt.Errorf("failed to parse config from %q: %w", name, err)
+		}
+	}
+
+	filtered, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to parse config from %q: %w", name, err)
+	}
+	merged := *c
+	if err := merged.MergeJSON(bytes.NewReader(filtered), name); err != nil {
+		return err
+	}
+
+	// Move the new layer from the top to its place in the order.
+	layers := merged.ConfigSource.Layers
+	if n := len(layers); n > 0 && layers[n-1] == name {
+		layers = layers[:n-1]
+	}
+	i := slices.IndexFunc(layers, func(layer string) bool { return !isBase(layer) })
+	if i < 0 {
+		i = len(layers)
+	}
+	merged.ConfigSource.Layers = slices.Insert(layers, i, name)
+
+	*c = merged
+	return nil
+}
+
+// MergeFile applies the config file at configPath on top of c,
+// like [Config.MergeJSON].
+// The file may be in any [Format], given by its extension.
+//
+// Paths to secondary files which the file sets, like `events_file`,
+// are interpreted relative to configPath, like [FromFile];
+// see [Config.FilesFor].
+// If FS was not set yet, it is set to the same place.
+func (c *Config) MergeFile(files fs.FS, configPath string) error {
+	data, err := fs.ReadFile(files, configPath)
+	if err != nil {
+		return fmt.Errorf("config file could not be read: %w", err)
+	}
+
+	format := FormatOf(configPath)
+	jsonData, err := ToJSON(data, format)
+	if err != nil {
+		return fmt.Errorf("failed to parse config from %q: %w", configPath, err)
+	}
+
+	merged := *c
+	if err := merged.MergeJSON(bytes.NewReader(jsonData), configPath); err != nil {
+		// The JSON decoder cannot point into other formats,
+		// so report the first value of the wrong type at its key instead.
+		if format != FormatJSON {
+			if v, vErr := validateKeys(data, configPath); vErr == nil {
+				for _, warn := range v.Warns {
+					if errors.Is(warn, ErrInvalidValue) {
+						return fmt.Errorf("failed to parse config from %q: %w", configPath, warn)
+					}
+				}
+			}
+		}
+		return err
+	}
+
+	dir := fsys.WrapFS{BaseDir: filepath.Dir(configPath), FS: files}
+	merged.SetLayerFS(configPath, dir)
+	if merged.FS == nil {
+		merged.FS = dir
+	}
+
+	*c = merged
+	return nil
+}
+
+// SetFrom is like [Config.Set],
+// but also records that layer set key in ConfigSource.
+func (c *Config) SetFrom(layer, key, value string) error {
+	if err := c.Set(key, value); err != nil {
+		return err
+	}
+	c.ConfigSource = c.ConfigSource.clone()
+	c.ConfigSource.addLayer(layer)
+	c.ConfigSource.record(layer, key)
+	return nil
+}
+
+// MergeEnv applies the environment variables starting with [EnvPrefix]
+// on top of c, as the [SourceEnv] layer.
+// environ is in the format returned by [os.Environ].
+// Variables are applied in the order given.
+//
+// Variables which name unknown config keys are skipped,
+// and returned as warnings, like unknown keys in config files.
+// Invalid values are errors.
+func (c *Config) MergeEnv(environ []string) (warning.Warnings, error) {
+	var warns warning.Warnings
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key := strings.ToLower(strings.TrimPrefix(name, EnvPrefix))
+		key = strings.ReplaceAll(key, "__", ".")
+		err := c.SetFrom(SourceEnv, key, value)
+		if errors.Is(err, ErrUnknownKey) {
+			warns.Append(fmt.Errorf("environment variable %s: %w%s",
+				name, err, DidYouMean(key, settableKeys())))
+			continue
+		}
+		if err != nil {
+			return warns, fmt.Errorf("invalid environment variable %s: %w", name, err)
+		}
+	}
+	return warns, nil
+}
+
+// settableKeys returns the keys which [Config.Set] accepts,
+// including the nested keys of `geo`, for suggestions.
+func settableKeys() []string {
+	var keys []string
+	for key := range configKeys(reflect.TypeFor[Config]()) {
+		keys = append(keys, key)
+	}
+	for key := range configKeys(reflect.TypeFor[Coordinates]()) {
+		keys = append(keys, "geo."+key)
+	}
+	return keys
+}
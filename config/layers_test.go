@@ -0,0 +1,408 @@
+package config_test
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/fsys"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestProvenance(t *testing.T) {
+	p := config.Provenance{
+		Layers: []string{"a.json", "env"},
+		Keys: map[string]string{
+			"city":        "a.json",
+			"geo":         "a.json",
+			"events_file": "env",
+		},
+	}
+	test.CheckString(t, "String", "a.json, env", p.String())
+
+	cases := []struct {
+		Key  string
+		Want string
+	}{
+		{"city", "a.json"},
+		{"geo", "a.json"},
+		{"geo.lat", "a.json"},
+		{"events_file", "env"},
+		{"timezone", config.SourceDefault},
+		{"", config.SourceDefault},
+	}
+	for _, c := range cases {
+		t.Run(c.Key, func(t *testing.T) {
+			test.CheckString(t, "Of", c.Want, p.Of(c.Key))
+		})
+	}
+
+	test.CheckComparable(t, "SetsFiles(env)", true, p.SetsFiles("env"))
+	test.CheckComparable(t, "SetsFiles(a.json)", false, p.SetsFiles("a.json"))
+	test.CheckString(t, "zero String", "", config.Provenance{}.String())
+}
+
+func TestConfig_MergeJSON(t *testing.T) {
+	base := func() *config.Config {
+		cfg := config.Default
+		cfg.Geo = &config.Coordinates{Lat: 1, Lon: 2}
+		cfg.ConfigSource = config.Provenance{
+			Layers: []string{"base.json"},
+			Keys:   map[string]string{"geo": "base.json"},
+		}
+		return &cfg
+	}
+
+	cases := []struct {
+		Name       string
+		Input      string
+		WantSource config.Provenance
+		Check      func(t *testing.T, cfg *config.Config)
+		Err        string
+	}{
+		{
+			Name:  "empty object",
+			Input: `{}`,
+			WantSource: config.Provenance{
+				Layers: []string{"base.json", "top.json"},
+				Keys:   map[string]string{"geo": "base.json"},
+			},
+		},
+		{
+			Name:  "scalars",
+			Input: `{"city": "Jerusalem", "omer": true}`,
+			WantSource: config.Provenance{
+				Layers: []string{"base.json", "top.json"},
+				Keys: map[string]string{
+					"geo":  "base.json",
+					"city": "top.json",
+					"omer": "top.json",
+				},
+			},
+			Check: func(t *testing.T, cfg *config.Config) {
+				test.CheckString(t, "City", "Jerusalem", cfg.City)
+				test.CheckComparable(t, "Omer", true, cfg.Omer)
+				test.CheckComparable(t, "Geo", config.Coordinates{Lat: 1, Lon: 2}, *cfg.Geo)
+			},
+		},
+		{
+			Name:  "nested key",
+			Input: `{"geo": {"lat": 31.778}}`,
+			WantSource: config.Provenance{
+				Layers: []string{"base.json", "top.json"},
+				Keys: map[string]string{
+					"geo":     "base.json",
+					"geo.lat": "top.json",
+				},
+			},
+			Check: func(t *testing.T, cfg *config.Config) {
+				test.CheckComparable(t, "Geo", config.Coordinates{Lat: 31.778, Lon: 2}, *cfg.Geo)
+			},
+		},
+		{
+			Name:  "whole nested object as string",
+			Input: `{"geo": "31.778,35.235"}`,
+			WantSource: config.Provenance{
+				Layers: []string{"base.json", "top.json"},
+				Keys:   map[string]string{"geo": "top.json"},
+			},
+			Check: func(t *testing.T, cfg *config.Config) {
+				test.CheckComparable(t, "Geo", config.Coordinates{Lat: 31.778, Lon: 35.235}, *cfg.Geo)
+			},
+		},
+		{
+			Name:  "invalid JSON",
+			Input: `{INVALID`,
+			Err:   `failed to parse config from "top.json": invalid character 'I' looking for beginning of object key string`,
+		},
+		{
+			Name:  "invalid type",
+			Input: `{"omer": "yes"}`,
+			Err:   `failed to parse config from "top.json": json: cannot unmarshal string into Go struct field Config.omer of type bool`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			orig := base()
+			cfg := base()
+			err := cfg.MergeJSON(strings.NewReader(c.Input), "top.json")
+			test.CheckErr(t, err, c.Err)
+			if err != nil {
+				checkConfig(t, orig, cfg)
+				return
+			}
+			test.CheckProvenance(t, "ConfigSource", c.WantSource, cfg.ConfigSource)
+			if c.Check != nil {
+				c.Check(t, cfg)
+			}
+		})
+	}
+
+	t.Run("does not modify shared geo", func(t *testing.T) {
+		orig := base()
+		cfg := *orig
+		err := cfg.MergeJSON(strings.NewReader(`{"geo": {"lat": 3}}`), "top.json")
+		test.CheckErr(t, err, "")
+		test.CheckComparable(t, "orig.Geo", config.Coordinates{Lat: 1, Lon: 2}, *orig.Geo)
+		test.CheckProvenance(t, "orig.ConfigSource", base().ConfigSource, orig.ConfigSource)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		cfg := base()
+		r := io.MultiReader(strings.NewReader("{"), errReader{})
+		err := cfg.MergeJSON(r, "top.json")
+		test.CheckErr(t, err, `failed to read config from "top.json": read failed`)
+	})
+}
+
+func TestConfig_MergeJSONUnder(t *testing.T) {
+	base := func() *config.Config {
+		cfg := config.Default
+		cfg.City = "Phoenix"
+		cfg.Geo = &config.Coordinates{Lat: 1, Lon: 2}
+		cfg.ConfigSource = config.Provenance{
+			Layers: []string{"system.json", "user.json", "flags"},
+			Keys: map[string]string{
+				"city":    "system.json",
+				"geo.lat": "user.json",
+				"omer":    "flags",
+			},
+		}
+		return &cfg
+	}
+
+	cases := []struct {
+		Name       string
+		Input      string
+		WantSource config.Provenance
+		Check      func(t *testing.T, cfg *config.Config)
+		Err        string
+	}{
+		{
+			Name:  "overrides base layers only",
+			Input: `{"city": "Jerusalem", "omer": false, "geo": {"lat": 3, "lon": 4}}`,
+			WantSource: config.Provenance{
+				Layers: []string{"system.json", "front.tmpl", "user.json", "flags"},
+				Keys: map[string]string{
+					"city":    "front.tmpl",
+					"geo.lat": "user.json",
+					"geo.lon": "front.tmpl",
+					"omer":    "flags",
+				},
+			},
+			Check: func(t *testing.T, cfg *config.Config) {
+				test.CheckString(t, "City", "Jerusalem", cfg.City)
+				test.CheckComparable(t, "Omer", false, cfg.Omer)
+				test.CheckComparable(t, "Geo", config.Coordinates{Lat: 1, Lon: 4}, *cfg.Geo)
+			},
+		},
+		{
+			Name:  "all keys set above",
+			Input: `{"geo": {"lat": 3}, "omer": true}`,
+			WantSource: config.Provenance{
+				Layers: []string{"system.json", "front.tmpl", "user.json", "flags"},
+				Keys: map[string]string{
+					"city":    "system.json",
+					"geo.lat": "user.json",
+					"omer":    "flags",
+				},
+			},
+			Check: func(t *testing.T, cfg *config.Config) {
+				test.CheckComparable(t, "Omer", false, cfg.Omer)
+				test.CheckComparable(t, "Geo", config.Coordinates{Lat: 1, Lon: 2}, *cfg.Geo)
+			},
+		},
+		{
+			Name:  "invalid JSON",
+			Input: `{INVALID}`,
+			Err:   `failed to parse config from "front.tmpl": invalid character 'I' looking for beginning of object key string`,
+		},
+		{
+			Name:  "invalid type",
+			Input: `{"city": 1}`,
+			Err:   `failed to parse config from "front.tmpl": json: cannot unmarshal number into Go struct field Config.city of type string`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg := base()
+			err := cfg.MergeJSONUnder(strings.NewReader(c.Input), "front.tmpl", "system.json")
+			test.CheckErr(t, err, c.Err)
+			if err != nil {
+				checkConfig(t, base(), cfg)
+				return
+			}
+			test.CheckProvenance(t, "ConfigSource", c.WantSource, cfg.ConfigSource)
+			if c.Check != nil {
+				c.Check(t, cfg)
+			}
+		})
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestConfig_MergeFile(t *testing.T) {
+	file := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"a/config.json": file(`{"city": "Jerusalem"}`),
+		"b/events.json": file(`{"events_file": "events.txt"}`),
+		"b/events.txt":  file(""),
+		"c/config.yaml": file("events_file: events.txt # relative to c/\n"),
+		"c/bad.toml":    file("num_years = \"two\"\n"),
+	}
+	otherFS := fstest.MapFS{}
+
+	cases := []struct {
+		Name    string
+		FS      fs.FS
+		Path    string
+		WantDir string
+		Err     string
+	}{
+		{Name: "sets FS when unset", Path: "a/config.json", WantDir: "a"},
+		{Name: "keeps FS", FS: otherFS, Path: "a/config.json"},
+		{Name: "file keys are relative to the file", FS: otherFS, Path: "b/events.json", WantDir: "b"},
+		{Name: "yaml", FS: otherFS, Path: "c/config.yaml", WantDir: "c"},
+		{
+			Name: "toml type error",
+			Path: "c/bad.toml",
+			Err:  `failed to parse config from "c/bad.toml": c/bad.toml:1:1: invalid value for "num_years": expected a whole number, got "two"`,
+		},
+		{
+			Name: "missing",
+			Path: "missing.json",
+			Err:  "config file could not be read: open missing.json: file does not exist",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg := config.Default
+			cfg.FS = c.FS
+			err := cfg.MergeFile(files, c.Path)
+			test.CheckErr(t, err, c.Err)
+			if err != nil {
+				return
+			}
+			test.CheckSlice(t, "Layers", []string{c.Path}, cfg.ConfigSource.Layers)
+			got := cfg.FilesFor("events_file")
+			if c.WantDir == "" {
+				if _, ok := got.(fstest.MapFS); !ok {
+					t.Errorf("want FS unchanged, got %T", got)
+				}
+				return
+			}
+			wrapped, ok := got.(fsys.WrapFS)
+			if !ok {
+				t.Fatalf("want fsys.WrapFS, got %T", got)
+			}
+			test.CheckString(t, "BaseDir", c.WantDir, wrapped.BaseDir)
+		})
+	}
+}
+
+func TestConfig_SetFrom(t *testing.T) {
+	cfg := config.Default
+	test.CheckErr(t, cfg.SetFrom(config.SourceFlags, "city", "Jerusalem"), "")
+	test.CheckErr(t, cfg.SetFrom(config.SourceFlags, "omer", "true"), "")
+	test.CheckErr(t, cfg.SetFrom(config.SourceQuery, "city", "Phoenix"), "")
+	test.CheckErr(t,
+		cfg.SetFrom(config.SourceQuery, "INVALID", "1"),
+		`unknown config key: "INVALID"`)
+
+	test.CheckString(t, "City", "Phoenix", cfg.City)
+	test.CheckProvenance(t, "ConfigSource", config.Provenance{
+		Layers: []string{config.SourceFlags, config.SourceQuery},
+		Keys: map[string]string{
+			"city": config.SourceQuery,
+			"omer": config.SourceFlags,
+		},
+	}, cfg.ConfigSource)
+	test.CheckProvenance(t, "Default.ConfigSource",
+		config.Provenance{}, config.Default.ConfigSource)
+}
+
+func TestConfig_MergeEnv(t *testing.T) {
+	cases := []struct {
+		Name       string
+		Environ    []string
+		WantSource config.Provenance
+		Check      func(t *testing.T, cfg *config.Config)
+		Warns      string
+		Err        string
+	}{
+		{Name: "empty"},
+		{
+			Name:    "ignores other variables",
+			Environ: []string{"HOME=/root", "CITY=Jerusalem", "HEBCALFMT"},
+		},
+		{
+			Name: "sets keys",
+			Environ: []string{
+				"HEBCALFMT_CITY=Jerusalem",
+				"HEBCALFMT_GEO__LAT=31.778",
+				"HEBCALFMT_Candle_Lighting_Mins=40",
+			},
+			WantSource: config.Provenance{
+				Layers: []string{config.SourceEnv},
+				Keys: map[string]string{
+					"city":                 config.SourceEnv,
+					"geo.lat":              config.SourceEnv,
+					"candle_lighting_mins": config.SourceEnv,
+				},
+			},
+			Check: func(t *testing.T, cfg *config.Config) {
+				test.CheckString(t, "City", "Jerusalem", cfg.City)
+				test.CheckComparable(t, "Geo.Lat", 31.778, cfg.Geo.Lat)
+				test.CheckComparable(t, "CandleLightingMins", 40, cfg.CandleLightingMins)
+			},
+		},
+		{
+			Name: "unknown keys",
+			Environ: []string{
+				"HEBCALFMT_DEBUG=1",
+				"HEBCALFMT_CTY=Jerusalem",
+				"HEBCALFMT_GEO__LAT=31.778",
+				"HEBCALFMT_GEO__LAN=35.235",
+			},
+			WantSource: config.Provenance{
+				Layers: []string{config.SourceEnv},
+				Keys:   map[string]string{"geo.lat": config.SourceEnv},
+			},
+			Warns: "3 warnings:\n" +
+				`environment variable HEBCALFMT_DEBUG: unknown config key: "debug"` + "\n" +
+				`environment variable HEBCALFMT_CTY: unknown config key: "cty"; ` +
+				`did you mean "city"?` + "\n" +
+				`environment variable HEBCALFMT_GEO__LAN: unknown config key: "geo.lan"; ` +
+				`did you mean "geo.lat"?`,
+		},
+		{
+			Name:    "invalid value",
+			Environ: []string{"HEBCALFMT_OMER=maybe"},
+			Err:     `invalid environment variable HEBCALFMT_OMER: invalid value for config key "omer": strconv.ParseBool: parsing "maybe": invalid syntax`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg := config.Default
+			warns, err := cfg.MergeEnv(c.Environ)
+			test.CheckErr(t, err, c.Err)
+			test.CheckErr(t, warns.Build(), c.Warns)
+			if err != nil {
+				return
+			}
+			test.CheckProvenance(t, "ConfigSource", c.WantSource, cfg.ConfigSource)
+			if c.Check != nil {
+				c.Check(t, &cfg)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ExtendsKey names the profile which a profile inherits from.
+const ExtendsKey = "extends"
+
+// ProfileLayer returns the layer name used in [Provenance]
+// for the keys set by the profile called name.
+func ProfileLayer(name string) string {
+	return "profile:" + name
+}
+
+// ProfileNames returns the names of the Profiles, sorted.
+func (c Config) ProfileNames() []string {
+	return slices.Sorted(maps.Keys(c.Profiles))
+}
+
+// profileChain returns the names of the profiles which make up
+// the profile called name, starting with the one it ultimately extends,
+// and ending with name itself.
+func (c Config) profileChain(name string) ([]string, error) {
+	var chain []string
+	for name != "" {
+		if slices.Contains(chain, name) {
+			chain = append(chain, name)
+			return nil, fmt.Errorf(
+				"profile %q extends itself: %s",
+				name,
+				strings.Join(chain, " -> "),
+			)
+		}
+
+		raw, ok := c.Profiles[name]
+		if !ok {
+			if len(chain) == 0 {
+				return nil, fmt.Errorf("unknown profile: %q", name)
+			}
+			return nil, fmt.Errorf(
+				"profile %q extends unknown profile: %q",
+				chain[len(chain)-1],
+				name,
+			)
+		}
+		chain = append(chain, name)
+
+		var p struct {
+			Extends string `json:"extends"`
+		}
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return nil, fmt.Errorf("invalid profile %q: %w", name, err)
+		}
+		name = p.Extends
+	}
+	slices.Reverse(chain)
+	return chain, nil
+}
+
+// WithProfile returns a copy of c with the profile called name applied,
+// after the profiles it extends.
+// Each profile is merged like a config file, as the layer [ProfileLayer],
+// so only the keys it sets are changed.
+// Profiles may not contain `profiles` or `profile` keys of their own.
+func (c Config) WithProfile(name string) (*Config, error) {
+	chain, err := c.profileChain(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := c
+	for _, n := range chain {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(c.Profiles[n], &obj); err != nil {
+			return nil, fmt.Errorf("invalid profile %q: %w", n, err)
+		}
+		for _, key := range []string{"profiles", "profile"} {
+			if _, ok := obj[key]; ok {
+				return nil, fmt.Errorf(
+					"invalid profile %q: %q is not allowed inside a profile", n, key)
+			}
+		}
+		delete(obj, ExtendsKey)
+
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("invalid profile %q: %w", n, err)
+		}
+		if err := result.MergeJSON(bytes.NewReader(data), ProfileLayer(n)); err != nil {
+			return nil, err
+		}
+		// Paths in a profile are relative to the file which defined it.
+		if files, ok := c.LayerFS[c.ConfigSource.Of("profiles."+n)]; ok {
+			result.SetLayerFS(ProfileLayer(n), files)
+		}
+	}
+	result.Profile = name
+	return &result, nil
+}
+
+// ResolveProfile applies the profile selected by `Profile`,
+// like [Config.WithProfile].
+// If no profile is selected, it returns a copy of c.
+func (c Config) ResolveProfile() (*Config, error) {
+	if c.Profile == "" {
+		return &c, nil
+	}
+	return c.WithProfile(c.Profile)
+}
@@ -0,0 +1,235 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+const profilesJSON = `{
+  "city": "Phoenix",
+  "candle_lighting_mins": 20,
+  "profiles": {
+    "base": {"omer": true, "candle_lighting_mins": 30},
+    "israel": {"extends": "base", "city": "Jerusalem", "il": true},
+    "chag": {"extends": "israel", "chag_only": true},
+    "empty": {}
+  }
+}`
+
+func profilesConfig(t *testing.T, data string) *config.Config {
+	t.Helper()
+	cfg := config.Default
+	if err := cfg.MergeJSON(strings.NewReader(data), "profiles.json"); err != nil {
+		t.Fatal(err)
+	}
+	return &cfg
+}
+
+func TestConfig_ProfileNames(t *testing.T) {
+	cfg := profilesConfig(t, profilesJSON)
+	test.CheckSlice(t, "ProfileNames",
+		[]string{"base", "chag", "empty", "israel"}, cfg.ProfileNames())
+	test.CheckSlice(t, "Default.ProfileNames", nil, config.Default.ProfileNames())
+}
+
+func TestConfig_WithProfile(t *testing.T) {
+	cases := []struct {
+		Name        string
+		JSON        string
+		Profile     string
+		WantCity    string
+		WantMins    int
+		WantIL      bool
+		WantChag    bool
+		WantLayers  []string
+		WantCitySrc string
+		Err         string
+	}{
+		{
+			Name:        "no extends",
+			JSON:        profilesJSON,
+			Profile:     "base",
+			WantCity:    "Phoenix",
+			WantMins:    30,
+			WantLayers:  []string{"profiles.json", "profile:base"},
+			WantCitySrc: "profiles.json",
+		},
+		{
+			Name:        "extends",
+			JSON:        profilesJSON,
+			Profile:     "israel",
+			WantCity:    "Jerusalem",
+			WantMins:    30,
+			WantIL:      true,
+			WantLayers:  []string{"profiles.json", "profile:base", "profile:israel"},
+			WantCitySrc: "profile:israel",
+		},
+		{
+			Name:     "extends twice",
+			JSON:     profilesJSON,
+			Profile:  "chag",
+			WantCity: "Jerusalem",
+			WantMins: 30,
+			WantIL:   true,
+			WantChag: true,
+			WantLayers: []string{
+				"profiles.json", "profile:base", "profile:israel", "profile:chag",
+			},
+			WantCitySrc: "profile:israel",
+		},
+		{
+			Name:        "empty",
+			JSON:        profilesJSON,
+			Profile:     "empty",
+			WantCity:    "Phoenix",
+			WantMins:    20,
+			WantLayers:  []string{"profiles.json", "profile:empty"},
+			WantCitySrc: "profiles.json",
+		},
+		{
+			Name:    "unknown",
+			JSON:    profilesJSON,
+			Profile: "INVALID",
+			Err:     `unknown profile: "INVALID"`,
+		},
+		{
+			Name:    "extends unknown",
+			JSON:    `{"profiles": {"a": {"extends": "INVALID"}}}`,
+			Profile: "a",
+			Err:     `profile "a" extends unknown profile: "INVALID"`,
+		},
+		{
+			Name:    "extends itself",
+			JSON:    `{"profiles": {"a": {"extends": "a"}}}`,
+			Profile: "a",
+			Err:     `profile "a" extends itself: a -> a`,
+		},
+		{
+			Name:    "cycle",
+			JSON:    `{"profiles": {"a": {"extends": "b"}, "b": {"extends": "c"}, "c": {"extends": "b"}}}`,
+			Profile: "a",
+			Err:     `profile "b" extends itself: a -> b -> c -> b`,
+		},
+		{
+			Name:    "not an object",
+			JSON:    `{"profiles": {"a": true}}`,
+			Profile: "a",
+			Err:     `invalid profile "a": json: cannot unmarshal bool into Go value of type struct { Extends string "json:\"extends\"" }`,
+		},
+		{
+			Name:    "invalid extends",
+			JSON:    `{"profiles": {"a": {"extends": 1}}}`,
+			Profile: "a",
+			Err:     `invalid profile "a": json: cannot unmarshal number into Go struct field .extends of type string`,
+		},
+		{
+			Name:    "invalid value",
+			JSON:    `{"profiles": {"a": {"omer": "yes"}}}`,
+			Profile: "a",
+			Err:     `failed to parse config from "profile:a": json: cannot unmarshal string into Go struct field Config.omer of type bool`,
+		},
+		{
+			Name:    "nested profiles",
+			JSON:    `{"profiles": {"a": {"profiles": {}}}}`,
+			Profile: "a",
+			Err:     `invalid profile "a": "profiles" is not allowed inside a profile`,
+		},
+		{
+			Name:    "nested profile",
+			JSON:    `{"profiles": {"a": {"profile": "a"}}}`,
+			Profile: "a",
+			Err:     `invalid profile "a": "profile" is not allowed inside a profile`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg := profilesConfig(t, c.JSON)
+			got, err := cfg.WithProfile(c.Profile)
+			test.CheckErr(t, err, c.Err)
+			if err != nil {
+				return
+			}
+			test.CheckString(t, "Profile", c.Profile, got.Profile)
+			test.CheckString(t, "City", c.WantCity, got.City)
+			test.CheckComparable(t, "CandleLightingMins", c.WantMins, got.CandleLightingMins)
+			test.CheckComparable(t, "IL", c.WantIL, got.IL)
+			test.CheckComparable(t, "ChagOnly", c.WantChag, got.ChagOnly)
+			test.CheckSlice(t, "Layers", c.WantLayers, got.ConfigSource.Layers)
+			test.CheckString(t, "Of(city)", c.WantCitySrc, got.ConfigSource.Of("city"))
+			test.CheckString(t, "Of(extends)", config.SourceDefault, got.ConfigSource.Of("extends"))
+
+			// The receiver is unchanged.
+			test.CheckString(t, "cfg.Profile", "", cfg.Profile)
+			test.CheckSlice(t, "cfg.Layers", []string{"profiles.json"}, cfg.ConfigSource.Layers)
+		})
+	}
+}
+
+func TestConfig_ResolveProfile(t *testing.T) {
+	t.Run("none selected", func(t *testing.T) {
+		cfg := profilesConfig(t, profilesJSON)
+		got, err := cfg.ResolveProfile()
+		test.CheckErr(t, err, "")
+		checkConfig(t, cfg, got)
+	})
+
+	t.Run("selected", func(t *testing.T) {
+		cfg := profilesConfig(t, profilesJSON)
+		cfg.Profile = "israel"
+		got, err := cfg.ResolveProfile()
+		test.CheckErr(t, err, "")
+		test.CheckString(t, "City", "Jerusalem", got.City)
+	})
+
+	t.Run("FromReader", func(t *testing.T) {
+		got, err := config.FromReader(
+			strings.NewReader(`{"profile": "a", "profiles": {"a": {"city": "Jerusalem"}}}`),
+			"profiles.json",
+		)
+		test.CheckErr(t, err, "")
+		test.CheckString(t, "City", "Jerusalem", got.City)
+		test.CheckString(t, "configSource", "profiles.json, profile:a", got.ConfigSource.String())
+	})
+
+	t.Run("FromReader unknown", func(t *testing.T) {
+		_, err := config.FromReader(
+			strings.NewReader(`{"profile": "INVALID"}`), "profiles.json")
+		test.CheckErr(t, err, `unknown profile: "INVALID"`)
+	})
+}
+
+func TestNormalize_profiles(t *testing.T) {
+	cases := []struct {
+		Name string
+		JSON string
+		Err  string
+	}{
+		{Name: "valid", JSON: profilesJSON},
+		{
+			Name: "invalid language",
+			JSON: `{"profiles": {"a": {}, "b": {"language": "INVALID"}}}`,
+			Err:  `invalid profile "b": unknown language: "INVALID"`,
+		},
+		{
+			Name: "inherited invalid language",
+			JSON: `{"profiles": {"a": {"language": "INVALID"}, "b": {"extends": "a", "language": "he"}}}`,
+			Err:  `invalid profile "a": unknown language: "INVALID"`,
+		},
+		{
+			Name: "cycle",
+			JSON: `{"profiles": {"a": {"extends": "b"}, "b": {"extends": "a"}}}`,
+			Err:  `invalid profile "a": profile "a" extends itself: a -> b -> a`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.Logger(t)
+			cfg := profilesConfig(t, c.JSON)
+			_, err := cfg.Normalize()
+			test.CheckErr(t, err, c.Err)
+		})
+	}
+}
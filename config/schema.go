@@ -0,0 +1,286 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/hebcal/hebcal-go/locales"
+	"github.com/hebcal/hebcal-go/zmanim"
+)
+
+//go:generate go run ./internal/genschema
+
+// SchemaJSON is a JSON Schema for config files,
+// which editors can use to validate and autocomplete them.
+// It is generated by [GenerateSchema].
+//
+//go:embed schema.json
+var SchemaJSON []byte
+
+// SchemaKey is a top-level key which config files may use
+// to point editors to [SchemaJSON].
+// It is otherwise ignored.
+const SchemaKey = "$schema"
+
+// schemaSources are the files in this package
+// whose doc comments describe the fields in the schema.
+var schemaSources = []string{"config.go", "coordinates.go"}
+
+// schemaExtras holds the constraints on values
+// which cannot be derived from the Go types,
+// keyed by JSON key.
+func schemaExtras() map[string]map[string]any {
+	return map[string]map[string]any{
+		"language": {"enum": sortedStrings(locales.AllLocales)},
+		"shiurim": {"items": map[string]any{
+			"type": "string",
+			"enum": sortedStrings(ShiurimOptions),
+		}},
+		"candle_lighting_mins": {"minimum": 0, "maximum": MaxCandleLightingMins},
+		"havdalah_mins":        {"minimum": 0, "maximum": MaxHavdalahMins},
+		"havdalah_deg":         {"minimum": 0, "maximum": MaxHavdalahDeg},
+		"num_years":            {"minimum": 1},
+	}
+}
+
+func sortedStrings(s []string) []string {
+	return slices.Sorted(slices.Values(s))
+}
+
+func cityNames() []string {
+	var names []string
+	for _, loc := range zmanim.AllCities() {
+		names = append(names, loc.Name)
+	}
+	return sortedStrings(names)
+}
+
+// GenerateSchema builds [SchemaJSON] from the fields of [Config],
+// using the source files of this package in src
+// to describe each field with its doc comment.
+//
+// Run `go generate ./config` to update schema.json after changing Config.
+func GenerateSchema(src fs.FS) ([]byte, error) {
+	docs, err := parseDocs(src)
+	if err != nil {
+		return nil, err
+	}
+
+	configType := reflect.TypeFor[Config]()
+	defaults := reflect.ValueOf(Default)
+	extras := schemaExtras()
+
+	properties := map[string]any{
+		SchemaKey: map[string]any{
+			"type":        "string",
+			"description": "The location of this schema, for editors.",
+		},
+	}
+	profileProperties := map[string]any{
+		ExtendsKey: map[string]any{
+			"type":        "string",
+			"description": "The name of another profile to apply before this one.",
+		},
+	}
+	for i := range configType.NumField() {
+		f := configType.Field(i)
+		key := jsonName(f)
+		if key == "" {
+			continue
+		}
+
+		prop, err := typeSchema(f.Type, docs)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if doc := docs["Config."+f.Name]; doc != "" {
+			prop["description"] = doc
+		}
+		if def := defaults.Field(i); !def.IsZero() {
+			prop["default"] = def.Interface()
+		}
+		for k, v := range extras[key] {
+			prop[k] = v
+		}
+		properties[key] = prop
+
+		if key != "profiles" && key != "profile" {
+			profileProperties[key] = map[string]any{"$ref": "#/properties/" + key}
+		}
+	}
+
+	schema := map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "hebcalfmt config",
+		"description":          docs["Config"],
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+
+		// A city name which is not in the database is allowed with geo,
+		// as the name of the user's location.
+		"if":   map[string]any{"not": map[string]any{"required": []string{"geo"}}},
+		"then": map[string]any{"properties": map[string]any{"city": map[string]any{"enum": cityNames()}}},
+
+		"$defs": map[string]any{
+			"profile": map[string]any{
+				"type":                 "object",
+				"description":          "A named set of config keys, applied when selected.",
+				"properties":           profileProperties,
+				"additionalProperties": false,
+			},
+		},
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		slog.Error("failed to marshal schema", "error", err)
+		return nil, fmt.Errorf("%w: marshal schema: %w", ErrUnreachable, err)
+	}
+	return append(data, '\n'), nil
+}
+
+// typeSchema describes the JSON values which decode into t.
+func typeSchema(t reflect.Type, docs map[string]string) (map[string]any, error) {
+	switch t {
+	case reflect.TypeFor[*Coordinates]():
+		props := map[string]any{
+			"lat": map[string]any{"type": "number", "minimum": -90, "maximum": 90},
+			"lon": map[string]any{"type": "number", "minimum": -180, "maximum": 180},
+		}
+		for _, name := range []string{"Lat", "Lon"} {
+			if doc := docs["Coordinates."+name]; doc != "" {
+				props[strings.ToLower(name)].(map[string]any)["description"] = doc
+			}
+		}
+		return map[string]any{"oneOf": []any{
+			map[string]any{
+				"type":                 "object",
+				"description":          docs["Coordinates"],
+				"properties":           props,
+				"additionalProperties": false,
+			},
+			map[string]any{
+				"type":        "string",
+				"description": "The latitude and longitude, like 31.778,35.235.",
+				"pattern":     `^\s*-?[0-9.]+\s*,\s*-?[0-9.]+\s*$`,
+			},
+		}}, nil
+
+	case reflect.TypeFor[FileList]():
+		return map[string]any{"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			},
+		}}, nil
+
+	case reflect.TypeFor[map[string]json.RawMessage]():
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"$ref": "#/$defs/profile"},
+		}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Int:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return map[string]any{
+				"type":  "array",
+				"items": map[string]any{"type": "string"},
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("no schema for type %s", t)
+}
+
+// parseDocs reads the doc comments of the types in [schemaSources]
+// and of their fields.
+// The keys of the result are like `Config` and `Config.City`.
+func parseDocs(src fs.FS) (map[string]string, error) {
+	docs := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, fname := range schemaSources {
+		data, err := fs.ReadFile(src, fname)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, fname, data, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil {
+					doc = gd.Doc
+				}
+				docs[ts.Name.Name] = cleanDoc(doc)
+
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					for _, name := range field.Names {
+						docs[ts.Name.Name+"."+name.Name] = cleanDoc(field.Doc)
+					}
+				}
+			}
+		}
+	}
+	return docs, nil
+}
+
+// cleanDoc returns the text of a doc comment,
+// with its lines of prose joined into paragraphs.
+// List items and indented lines keep their line breaks.
+func cleanDoc(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	isProse := func(line string) bool {
+		return line != "" &&
+			!strings.HasPrefix(line, " ") &&
+			!strings.HasPrefix(line, "\t") &&
+			!strings.HasPrefix(line, "- ")
+	}
+	var b strings.Builder
+	prev := ""
+	for i, line := range strings.Split(strings.TrimSpace(cg.Text()), "\n") {
+		if i > 0 {
+			if isProse(prev) && isProse(line) {
+				b.WriteString(" ")
+			} else {
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(line)
+		prev = line
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package config_test
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestSchemaJSON_upToDate(t *testing.T) {
+	got, err := config.GenerateSchema(os.DirFS("."))
+	test.CheckErr(t, err, "")
+	if string(got) != string(config.SchemaJSON) {
+		t.Error("schema.json is out of date with the Config struct; run `go generate ./config`")
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Type        string   `json:"type"`
+			Description string   `json:"description"`
+			Enum        []string `json:"enum"`
+			Default     any      `json:"default"`
+			Items       struct {
+				Enum []string `json:"enum"`
+			} `json:"items"`
+		} `json:"properties"`
+		AdditionalProperties bool `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(config.SchemaJSON, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every key accepted in a config file is described.
+	for _, key := range []string{
+		"$schema", "city", "geo", "language", "shiurim",
+		"candle_lighting_mins", "num_years", "profiles", "profile",
+	} {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("missing property %q", key)
+		}
+	}
+	if _, ok := schema.Properties["ConfigSource"]; ok {
+		t.Error("unexpected property for an untagged field")
+	}
+
+	test.CheckString(t, "city.type", "string", schema.Properties["city"].Type)
+	if schema.Properties["city"].Description == "" {
+		t.Error("want a description for city")
+	}
+	test.CheckComparable[any](t, "candle_lighting_mins.default",
+		float64(config.Default.CandleLightingMins),
+		schema.Properties["candle_lighting_mins"].Default)
+	if !slices.Contains(schema.Properties["language"].Enum, "he") {
+		t.Error("want he among the languages")
+	}
+
+	// Every shiur in the schema is accepted.
+	shiurim := schema.Properties["shiurim"].Items.Enum
+	test.CheckSlice(t, "shiurim", config.ShiurimOptions, shiurim)
+	for _, shiur := range shiurim {
+		if err := config.SetShiurim(new(hebcal.CalOptions), []string{shiur}); err != nil {
+			t.Errorf("shiur %q: %v", shiur, err)
+		}
+	}
+}
+
+func TestGenerateSchema_missingSource(t *testing.T) {
+	_, err := config.GenerateSchema(fstest.MapFS{})
+	test.CheckErr(t, err, "open config.go: file does not exist")
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Set assigns value to the field whose JSON key is key.
+// Fields of nested objects are addressed with dots, like `geo.lat`.
+//
+// The value is parsed according to the type of the field:
+// strings are taken as-is, bools and numbers are parsed with [strconv],
+// and lists are split on commas.
+// Objects which implement [encoding.TextUnmarshaler] may be set whole,
+// like `geo=31.778,35.235`.
+//
+// Nested objects which are shared with other Configs are copied
+// before they are modified,
+// so it is safe to call Set on a shallow copy of another Config.
+func (c *Config) Set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if v.Kind() == reflect.Pointer {
+			// Copy on write, so that the original is not modified.
+			elem := reflect.New(v.Type().Elem())
+			if !v.IsNil() {
+				elem.Elem().Set(v.Elem())
+			}
+			v.Set(elem)
+			v = elem.Elem()
+		}
+
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf(
+				"%w: %q: %q is not an object",
+				ErrUnknownKey,
+				key,
+				strings.Join(parts[:i], "."),
+			)
+		}
+
+		field, ok := fieldByJSONKey(v, part)
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownKey, key)
+		}
+		v = field
+	}
+
+	if err := setValue(v, value); err != nil {
+		return fmt.Errorf("invalid value for config key %q: %w", key, err)
+	}
+	return nil
+}
+
+// jsonName returns the JSON object key for f,
+// or the empty string if it is not serialized.
+func jsonName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+func fieldByJSONKey(v reflect.Value, key string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := range t.NumField() {
+		if name := jsonName(t.Field(i)); name != "" && name == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Pointer {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", v.Type())
+		}
+		var items []string
+		if value != "" {
+			items = strings.Split(value, ",")
+			for i, item := range items {
+				items[i] = strings.TrimSpace(item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+
+	default:
+		return fmt.Errorf("cannot set a value of type %s", v.Type())
+	}
+	return nil
+}
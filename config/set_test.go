@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestConfig_Set(t *testing.T) {
+	cases := []struct {
+		Key, Value string
+		Want       func(c *config.Config)
+		Err        string
+	}{
+		{
+			Key:   "city",
+			Value: "Phoenix",
+			Want:  func(c *config.Config) { c.City = "Phoenix" },
+		},
+		{
+			Key:   "candle_lighting",
+			Value: "true",
+			Want:  func(c *config.Config) { c.CandleLighting = true },
+		},
+		{
+			Key:   "candle_lighting_mins",
+			Value: "40",
+			Want:  func(c *config.Config) { c.CandleLightingMins = 40 },
+		},
+		{
+			Key:   "havdalah_deg",
+			Value: "8.5",
+			Want:  func(c *config.Config) { c.HavdalahDeg = 8.5 },
+		},
+		{
+			Key:   "shiurim",
+			Value: "daf-yomi, nach-yomi",
+			Want:  func(c *config.Config) { c.Shiurim = []string{"daf-yomi", "nach-yomi"} },
+		},
+		{
+			Key:   "shiurim",
+			Value: "",
+			Want:  func(c *config.Config) { c.Shiurim = nil },
+		},
+		{
+			Key:   "geo.lat",
+			Value: "31.77",
+			Want: func(c *config.Config) {
+				c.Geo = &config.Coordinates{Lat: 31.77, Lon: 35.21}
+			},
+		},
+		{
+			Key:   "geo",
+			Value: "40.7128, -74.006",
+			Want: func(c *config.Config) {
+				c.Geo = &config.Coordinates{Lat: 40.7128, Lon: -74.006}
+			},
+		},
+		{
+			Key:   "geo",
+			Value: "40.7128",
+			Err:   `invalid value for config key "geo": expected coordinates as lat,lon, got "40.7128"`,
+		},
+		{Key: "INVALID", Err: `unknown config key: "INVALID"`},
+		{Key: "", Err: `unknown config key: ""`},
+		{Key: "geo.INVALID", Err: `unknown config key: "geo.INVALID"`},
+		{
+			Key: "city.name",
+			Err: `unknown config key: "city.name": "city" is not an object`,
+		},
+		{
+			Key:   "omer",
+			Value: "INVALID",
+			Err:   `invalid value for config key "omer": strconv.ParseBool: parsing "INVALID": invalid syntax`,
+		},
+		{
+			Key:   "num_years",
+			Value: "INVALID",
+			Err:   `invalid value for config key "num_years": strconv.Atoi: parsing "INVALID": invalid syntax`,
+		},
+		{
+			Key:   "havdalah_deg",
+			Value: "INVALID",
+			Err:   `invalid value for config key "havdalah_deg": strconv.ParseFloat: parsing "INVALID": invalid syntax`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Key+"="+c.Value, func(t *testing.T) {
+			orig := config.Default
+			orig.Shiurim = []string{"mishna-yomi"}
+			orig.Geo = &config.Coordinates{Lat: 1, Lon: 35.21}
+			origGeo := *orig.Geo
+
+			got := orig
+			err := got.Set(c.Key, c.Value)
+			test.CheckErr(t, err, c.Err)
+			if c.Err != "" {
+				return
+			}
+
+			want := orig
+			c.Want(&want)
+			checkConfig(t, &want, &got)
+
+			// The original should be unchanged.
+			test.CheckComparable(t, "orig.Geo", origGeo, *orig.Geo)
+			test.CheckSlice(t, "orig.Shiurim", []string{"mishna-yomi"}, orig.Shiurim)
+		})
+	}
+}
@@ -0,0 +1,550 @@
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/locales"
+	"github.com/hebcal/hebcal-go/zmanim"
+
+	"github.com/chaimleib/hebcalfmt/daterange"
+	"github.com/chaimleib/hebcalfmt/warning"
+)
+
+// Kinds of problems reported by [Validate] and [Config.Check].
+var (
+	ErrUnknownKey   = errors.New("unknown config key")
+	ErrInvalidValue = errors.New("invalid value")
+	ErrOutOfRange   = errors.New("out of range")
+	ErrConflict     = errors.New("conflicting settings")
+)
+
+// Limits on numeric settings, checked by [Config.Check].
+const (
+	MaxCandleLightingMins = 90
+	MaxHavdalahMins       = 180
+
+	// MaxHavdalahDeg is astronomical twilight.
+	// The sky is fully dark by the time the sun is this far below the horizon.
+	MaxHavdalahDeg = 18
+)
+
+// ValidationError is a problem found in a config file,
+// at the position of the key it concerns.
+type ValidationError struct {
+	FileName string
+	Line     int
+	Col      int
+
+	// Key is the dotted path to the key, like `geo.lat`
+	// or `profiles.israel.city`.
+	// It is empty if the problem concerns the whole file.
+	Key string
+
+	Err error
+}
+
+var _ error = ValidationError{}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.FileName, e.Line, e.Col, e.Err)
+}
+
+func (e ValidationError) Unwrap() error { return e.Err }
+
+// settingError is a problem found by [Config.Check],
+// which may be positioned by [Validate].
+type settingError struct {
+	Key string
+	Err error
+}
+
+// Check reports settings which are out of range or conflict with each other,
+// or which name unknown values like a misspelled city.
+// These are problems which the JSON decoder does not catch,
+// and which might otherwise be ignored or only be detected
+// when the settings are used.
+//
+// Check does not look at the Profiles; see [Validate] for those.
+func (c Config) Check() warning.Warnings {
+	var warns warning.Warnings
+	for _, se := range c.check() {
+		warns.Append(fmt.Errorf("%s: %w", se.Key, se.Err))
+	}
+	return warns
+}
+
+func (c Config) check() []settingError {
+	var errs []settingError
+	add := func(key string, err error, format string, args ...any) {
+		errs = append(errs, settingError{
+			Key: key,
+			Err: fmt.Errorf("%w: "+format, append([]any{err}, args...)...),
+		})
+	}
+
+	if c.Language != "" && !slices.ContainsFunc(locales.AllLocales, func(l string) bool {
+		return strings.EqualFold(l, c.Language)
+	}) {
+		add("language", ErrInvalidValue, "unknown language %q", c.Language)
+	}
+
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			add("timezone", ErrInvalidValue, "unknown timezone %q", c.Timezone)
+		}
+	}
+
+	if c.Geo == nil && c.City != "" && zmanim.LookupCity(c.City) == nil {
+		cities := make([]string, 0, len(zmanim.AllCities()))
+		for _, loc := range zmanim.AllCities() {
+			cities = append(cities, loc.Name)
+		}
+		add("city", ErrInvalidValue, "unknown city %q%s",
+			c.City, DidYouMean(c.City, cities))
+	}
+
+	if c.Geo != nil {
+		if err := c.Geo.Validate(); err != nil {
+			add("geo", ErrOutOfRange, "%v", err)
+		}
+		if c.Timezone == "" {
+			add("geo", ErrConflict, "geo is set, but timezone is missing")
+		}
+	}
+
+	if err := SetShiurim(new(hebcal.CalOptions), c.Shiurim); err != nil {
+		add("shiurim", ErrInvalidValue, "%v", err)
+	}
+
+	if c.CandleLightingMins < 0 || c.CandleLightingMins > MaxCandleLightingMins {
+		add("candle_lighting_mins", ErrOutOfRange,
+			"expected 0 to %d minutes, got %d",
+			MaxCandleLightingMins, c.CandleLightingMins)
+	}
+	if c.HavdalahMins < 0 || c.HavdalahMins > MaxHavdalahMins {
+		add("havdalah_mins", ErrOutOfRange,
+			"expected 0 to %d minutes, got %d",
+			MaxHavdalahMins, c.HavdalahMins)
+	}
+	if c.HavdalahDeg < 0 || c.HavdalahDeg > MaxHavdalahDeg {
+		add("havdalah_deg", ErrOutOfRange,
+			"expected 0 to %d degrees, got %g",
+			MaxHavdalahDeg, c.HavdalahDeg)
+	}
+	if c.HavdalahMins != 0 && c.HavdalahDeg != 0 {
+		add("havdalah_deg", ErrConflict,
+			"havdalah_mins and havdalah_deg are both set")
+	}
+
+	if c.NumYears < 1 {
+		add("num_years", ErrOutOfRange, "expected at least 1, got %d", c.NumYears)
+	}
+
+	if c.Today {
+		if c.NumYears > 1 {
+			add("today", ErrConflict,
+				"today shows a single day, but num_years is %d", c.NumYears)
+		}
+		if dr := c.DateRange; dr != nil &&
+			dr.RangeType != daterange.RangeTypeDay &&
+			dr.RangeType != daterange.RangeTypeToday {
+			add("today", ErrConflict,
+				"today shows a single day, but the date range spec was %s", dr)
+		}
+	}
+
+	return errs
+}
+
+// jsonEntry is a member of a JSON object, with the positions needed
+// to report problems with it.
+type jsonEntry struct {
+	Key         string
+	Offset      int // of the key
+	Value       json.RawMessage
+	ValueOffset int
+}
+
+// skipSeparators returns the offset of the next token in data at or after i.
+func skipSeparators(data []byte, i int) int {
+	for i < len(data) && strings.IndexByte(" \t\r\n,:", data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// readObject reads the members of the JSON object which starts at offset.
+func readObject(data []byte, offset int) ([]jsonEntry, error) {
+	offset = skipSeparators(data, offset)
+	dec := json.NewDecoder(bytes.NewReader(data[offset:]))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("expected a JSON object, got %v", tok)
+	}
+
+	var entries []jsonEntry
+	for dec.More() {
+		var e jsonEntry
+		e.Offset = skipSeparators(data, offset+int(dec.InputOffset()))
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		e.Key, _ = tok.(string)
+		e.ValueOffset = skipSeparators(data, offset+int(dec.InputOffset()))
+		if err := dec.Decode(&e.Value); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// validator collects [ValidationError]s for a single config file.
+type validator struct {
+	Data     []byte
+	FileName string
+	Warns    warning.Warnings
+
+	// Offsets maps the dotted paths of the keys found to their offsets.
+	Offsets map[string]int
+
+	// Invalid holds the dotted paths of the keys with values of the wrong type,
+	// so that they are not reported again by [Config.Check].
+	Invalid map[string]bool
+
+	// Positions holds the positions of the keys in the original file,
+	// if it was converted to JSON from another [Format].
+	// Then problems are reported at the key they concern,
+	// instead of at an offset into Data.
+	Positions map[string]position
+}
+
+func (v *validator) warn(offset int, key string, err error) {
+	var pos position
+	if v.Positions != nil {
+		pos = v.positionOf(key)
+	} else {
+		pos.Line = 1 + bytes.Count(v.Data[:offset], []byte("\n"))
+		lineStart := bytes.LastIndexByte(v.Data[:offset], '\n') + 1
+		pos.Col = 1 + utf8.RuneCount(v.Data[lineStart:offset])
+	}
+	v.Warns.Append(ValidationError{
+		FileName: v.FileName,
+		Line:     pos.Line,
+		Col:      pos.Col,
+		Key:      key,
+		Err:      err,
+	})
+}
+
+// positionOf returns the position of the key at path in Positions,
+// or else of the nearest enclosing key which was found.
+func (v *validator) positionOf(path string) position {
+	for p := path; p != ""; {
+		if pos, ok := v.Positions[p]; ok {
+			return pos
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return position{Line: 1, Col: 1}
+}
+
+// configKeys lists the JSON keys of the fields of t.
+func configKeys(t reflect.Type) map[string]reflect.Type {
+	keys := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		if name := jsonName(t.Field(i)); name != "" {
+			keys[name] = t.Field(i).Type
+		}
+	}
+	return keys
+}
+
+// describeType names the kind of JSON value which decodes into t.
+func describeType(t reflect.Type) string {
+	switch t {
+	case reflect.TypeFor[*Coordinates]():
+		return `an object or a "lat,lon" string`
+	case reflect.TypeFor[FileList]():
+		return "a path or a list of paths"
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.String:
+		return "a string"
+	case reflect.Int:
+		return "a whole number"
+	case reflect.Float64:
+		return "a number"
+	case reflect.Slice:
+		return "a list of strings"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return t.String()
+}
+
+// checkKeys checks the keys and value types of the object in entries.
+// prefix is prepended to the keys to form their dotted paths.
+// Inside a profile, `extends` is allowed,
+// but `profiles` and `profile` are not.
+func (v *validator) checkKeys(entries []jsonEntry, prefix string, inProfile bool) {
+	known := configKeys(reflect.TypeFor[Config]())
+	names := make([]string, 0, len(known)+1)
+	for name := range known {
+		names = append(names, name)
+	}
+	if inProfile {
+		names = append(names, ExtendsKey)
+	}
+
+	for _, e := range entries {
+		path := prefix + e.Key
+		v.Offsets[path] = e.Offset
+		if !inProfile && e.Key == SchemaKey {
+			continue
+		}
+
+		if inProfile {
+			switch e.Key {
+			case ExtendsKey:
+				var s string
+				if json.Unmarshal(e.Value, &s) != nil {
+					v.warn(e.ValueOffset, path, fmt.Errorf(
+						"%w for %q: expected a profile name", ErrInvalidValue, path))
+				}
+				continue
+			case "profiles", "profile":
+				v.warn(e.Offset, path, fmt.Errorf(
+					"%w: %q is not allowed inside a profile", ErrUnknownKey, e.Key))
+				continue
+			}
+		}
+
+		t, ok := known[e.Key]
+		if !ok {
+			v.warn(e.Offset, path, fmt.Errorf(
+				"%w %q%s", ErrUnknownKey, path, DidYouMean(e.Key, names)))
+			continue
+		}
+
+		if json.Unmarshal(e.Value, reflect.New(t).Interface()) != nil {
+			v.Invalid[path] = true
+			v.warn(e.ValueOffset, path, fmt.Errorf(
+				"%w for %q: expected %s, got %s",
+				ErrInvalidValue, path, describeType(t), e.Value))
+			continue
+		}
+
+		switch e.Key {
+		case "geo":
+			sub, err := readObject(v.Data, e.ValueOffset)
+			if err != nil {
+				continue // a "lat,lon" string
+			}
+			geoKeys := configKeys(reflect.TypeFor[Coordinates]())
+			for _, g := range sub {
+				v.Offsets[path+"."+g.Key] = g.Offset
+				if _, ok := geoKeys[g.Key]; !ok {
+					v.warn(g.Offset, path+"."+g.Key, fmt.Errorf(
+						"%w %q%s", ErrUnknownKey, path+"."+g.Key,
+						DidYouMean(g.Key, slices.Sorted(maps.Keys(geoKeys)))))
+				}
+			}
+
+		case "profiles":
+			profiles, _ := readObject(v.Data, e.ValueOffset)
+			for _, p := range profiles {
+				pPath := path + "." + p.Key
+				v.Offsets[pPath] = p.Offset
+				sub, err := readObject(v.Data, p.ValueOffset)
+				if err != nil {
+					v.warn(p.ValueOffset, pPath, fmt.Errorf(
+						"%w for %q: expected an object", ErrInvalidValue, pPath))
+					continue
+				}
+				v.checkKeys(sub, pPath+".", true)
+			}
+		}
+	}
+}
+
+// offsetOf returns the offset of the key at path,
+// or else of the nearest enclosing key which was found.
+func (v *validator) offsetOf(path string) int {
+	for p := path; p != ""; {
+		if offset, ok := v.Offsets[p]; ok {
+			return offset
+		}
+		i := strings.LastIndex(p, ".")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return skipSeparators(v.Data, 0)
+}
+
+// ValidateKeys checks data, the contents of a config file called name,
+// for unknown keys and for values of the wrong type,
+// including inside `geo` and each of the `profiles`.
+// Unknown keys come with a suggestion if a known key is spelled similarly.
+//
+// Each problem is returned as a [ValidationError] in the warnings.
+// The [Format] of data is given by the extension of name; see [FormatOf].
+// An error is returned if data cannot be parsed or is not an object.
+func ValidateKeys(data []byte, name string) (warning.Warnings, error) {
+	v, err := validateKeys(data, name)
+	if err != nil {
+		return nil, err
+	}
+	return v.Warns, nil
+}
+
+func validateKeys(data []byte, name string) (*validator, error) {
+	data, positions, err := toJSON(data, FormatOf(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config from %q: %w", name, err)
+	}
+	entries, err := readObject(data, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config from %q: %w", name, err)
+	}
+	v := &validator{
+		Data:      data,
+		FileName:  name,
+		Offsets:   make(map[string]int),
+		Invalid:   make(map[string]bool),
+		Positions: positions,
+	}
+	v.checkKeys(entries, "", false)
+	return v, nil
+}
+
+// Validate checks data, the contents of a config file called name,
+// as a complete config on top of [Default].
+// Besides the problems found by [ValidateKeys],
+// it reports the problems found by [Config.Check],
+// positioned at the keys which caused them.
+//
+// Each of the `profiles` is also applied and checked.
+// A profile is only blamed for problems
+// which the file does not have without it.
+//
+// The problems are ordered by their line and column in the file.
+func Validate(data []byte, name string) (warning.Warnings, error) {
+	v, err := validateKeys(data, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Type errors were reported above; decode what can be decoded.
+	cfg := Default
+	_ = json.Unmarshal(v.Data, &cfg)
+
+	base := cfg.check()
+	seen := make(map[string]bool)
+	for _, se := range base {
+		seen[se.Err.Error()] = true
+		if v.Invalid[se.Key] {
+			continue
+		}
+		v.warn(v.offsetOf(se.Key), se.Key, se.Err)
+	}
+
+	for _, pName := range cfg.ProfileNames() {
+		prefix := "profiles." + pName
+		withProfile, err := cfg.WithProfile(pName)
+		if err != nil {
+			v.warn(v.offsetOf(prefix), prefix, fmt.Errorf("%w: %w", ErrInvalidValue, err))
+			continue
+		}
+		for _, se := range withProfile.check() {
+			if seen[se.Err.Error()] || v.Invalid[prefix+"."+se.Key] {
+				continue
+			}
+			v.warn(v.offsetOf(prefix+"."+se.Key), prefix+"."+se.Key,
+				fmt.Errorf("profile %q: %w", pName, se.Err))
+		}
+	}
+
+	if cfg.Profile != "" {
+		if _, ok := cfg.Profiles[cfg.Profile]; !ok {
+			v.warn(v.offsetOf("profile"), "profile", fmt.Errorf(
+				"%w: unknown profile %q%s",
+				ErrInvalidValue, cfg.Profile,
+				DidYouMean(cfg.Profile, cfg.ProfileNames())))
+		}
+	}
+
+	slices.SortStableFunc(v.Warns, func(a, b error) int {
+		var ea, eb ValidationError
+		errors.As(a, &ea)
+		errors.As(b, &eb)
+		return cmp.Or(cmp.Compare(ea.Line, eb.Line), cmp.Compare(ea.Col, eb.Col))
+	})
+	return v.Warns, nil
+}
+
+// DidYouMean returns a suggestion like `; did you mean "city"?`
+// if one of the options is spelled similarly to s,
+// or else the empty string.
+func DidYouMean(s string, options []string) string {
+	best, bestDist := "", -1
+	folded := strings.ToLower(s)
+	for _, option := range options {
+		d := editDistance(folded, strings.ToLower(option))
+		if bestDist < 0 || d < bestDist || (d == bestDist && option < best) {
+			best, bestDist = option, d
+		}
+	}
+	if bestDist < 0 || bestDist > max(2, utf8.RuneCountInString(s)/3) {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean %q?", best)
+}
+
+// editDistance is the Levenshtein distance between a and b, in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,341 @@
+package config_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/daterange"
+	"github.com/chaimleib/hebcalfmt/test"
+	"github.com/chaimleib/hebcalfmt/warning"
+)
+
+func warnStrings(warns warning.Warnings) []string {
+	var result []string
+	for _, w := range warns {
+		result = append(result, w.Error())
+	}
+	return result
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Input string
+		Want  []string
+		Err   string
+	}{
+		{Name: "empty object", Input: `{}`},
+		{
+			Name: "valid",
+			Input: `{
+  "city": "Jerusalem",
+  "il": true,
+  "candle_lighting_mins": 40,
+  "havdalah_deg": 8.5,
+  "shiurim": ["daf-yomi"],
+  "geo": {"lat": 31.778, "lon": 35.235},
+  "timezone": "Asia/Jerusalem"
+}`,
+		},
+		{
+			Name: "misspelled key",
+			Input: `{
+  "city": "Jerusalem",
+  "candle_lightning_mins": 40
+}`,
+			Want: []string{
+				`test.json:3:3: unknown config key "candle_lightning_mins"; did you mean "candle_lighting_mins"?`,
+			},
+		},
+		{
+			Name:  "schema",
+			Input: `{"$schema": "https://example.com/schema.json", "profiles": {"a": {"$schema": ""}}}`,
+			Want:  []string{`test.json:1:67: unknown config key "profiles.a.$schema"`},
+		},
+		{
+			Name:  "unknown key without suggestion",
+			Input: `{"INVALID": 1}`,
+			Want:  []string{`test.json:1:2: unknown config key "INVALID"`},
+		},
+		{
+			Name:  "misspelled geo key",
+			Input: `{"geo": {"lat": 1, "long": 2}, "timezone": "UTC"}`,
+			Want: []string{
+				`test.json:1:20: unknown config key "geo.long"; did you mean "lon"?`,
+			},
+		},
+		{
+			Name: "wrong types",
+			Input: `{
+	"omer": "yes",
+	"candle_lighting_mins": 18.5,
+	"shiurim": "daf-yomi",
+	"geo": 5
+}`,
+			Want: []string{
+				`test.json:2:10: invalid value for "omer": expected true or false, got "yes"`,
+				`test.json:3:26: invalid value for "candle_lighting_mins": expected a whole number, got 18.5`,
+				`test.json:4:13: invalid value for "shiurim": expected a list of strings, got "daf-yomi"`,
+				`test.json:5:9: invalid value for "geo": expected an object or a "lat,lon" string, got 5`,
+			},
+		},
+		{
+			Name: "out of range",
+			Input: `{
+  "candle_lighting_mins": 400,
+  "havdalah_mins": -1,
+  "havdalah_deg": 85,
+  "num_years": 0
+}`,
+			Want: []string{
+				`test.json:2:3: out of range: expected 0 to 90 minutes, got 400`,
+				`test.json:3:3: out of range: expected 0 to 180 minutes, got -1`,
+				`test.json:4:3: out of range: expected 0 to 18 degrees, got 85`,
+				`test.json:4:3: conflicting settings: havdalah_mins and havdalah_deg are both set`,
+				`test.json:5:3: out of range: expected at least 1, got 0`,
+			},
+		},
+		{
+			Name: "conflicts",
+			Input: `{
+  "today": true,
+  "num_years": 2,
+  "geo": "91,0"
+}`,
+			Want: []string{
+				`test.json:2:3: conflicting settings: today shows a single day, but num_years is 2`,
+				`test.json:4:3: out of range: invalid latitude: 91.000000`,
+				`test.json:4:3: conflicting settings: geo is set, but timezone is missing`,
+			},
+		},
+		{
+			Name:  "unknown values",
+			Input: `{"city": "Jerusalm", "language": "xx", "timezone": "Mars/Olympus", "shiurim": ["daf"]}`,
+			Want: []string{
+				`test.json:1:2: invalid value: unknown city "Jerusalm"; did you mean "Jerusalem"?`,
+				`test.json:1:22: invalid value: unknown language "xx"`,
+				`test.json:1:40: invalid value: unknown timezone "Mars/Olympus"`,
+				`test.json:1:68: invalid value: unrecognized item(s) in shiurim: ["daf"]`,
+			},
+		},
+		{
+			Name: "malformed profiles",
+			Input: `{
+  "havdalah_mins": 50,
+  "profile": "israle",
+  "profiles": {
+    "israel": {"city": "Jerusalem", "il": ture},
+    "chag": {"extends": "israel", "chag_onyl": true, "havdalah_deg": 8.5},
+    "loop": {"extends": "loop"},
+    "nested": {"profile": "chag", "extends": 5},
+    "list": []
+  }
+}`,
+			Err: `failed to parse config from "test.json": invalid character 'u' in literal true (expecting 'r')`,
+		},
+		{
+			Name: "profiles",
+			Input: `{
+  "havdalah_mins": 50,
+  "profile": "israle",
+  "profiles": {
+    "israel": {"city": "Jerusalem", "il": true},
+    "chag": {"extends": "israel", "chag_onyl": true, "havdalah_deg": 8.5},
+    "loop": {"extends": "loop"},
+    "nested": {"profile": "chag", "extends": 5},
+    "list": []
+  }
+}`,
+			Want: []string{
+				`test.json:3:3: invalid value: unknown profile "israle"; did you mean "israel"?`,
+				`test.json:6:35: unknown config key "profiles.chag.chag_onyl"; did you mean "chag_only"?`,
+				`test.json:6:54: profile "chag": conflicting settings: havdalah_mins and havdalah_deg are both set`,
+				`test.json:7:5: invalid value: profile "loop" extends itself: loop -> loop`,
+				`test.json:8:5: invalid value: invalid profile "nested": json: cannot unmarshal number into Go struct field .extends of type string`,
+				`test.json:8:16: unknown config key: "profile" is not allowed inside a profile`,
+				`test.json:8:46: invalid value for "profiles.nested.extends": expected a profile name`,
+				`test.json:9:5: invalid value: invalid profile "list": json: cannot unmarshal array into Go value of type struct { Extends string "json:\"extends\"" }`,
+				`test.json:9:13: invalid value for "profiles.list": expected an object`,
+			},
+		},
+		{
+			Name:  "not an object",
+			Input: `[]`,
+			Err:   `failed to parse config from "test.json": expected a JSON object, got [`,
+		},
+		{
+			Name:  "truncated",
+			Input: `{"city": `,
+			Err:   `failed to parse config from "test.json": unexpected EOF`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			warns, err := config.Validate([]byte(c.Input), "test.json")
+			test.CheckErr(t, err, c.Err)
+			test.CheckSlice(t, "warnings", c.Want, warnStrings(warns))
+		})
+	}
+}
+
+func TestValidateKeys(t *testing.T) {
+	input := `{"candle_lightning_mins": 40, "havdalah_deg": 85}`
+	warns, err := config.ValidateKeys([]byte(input), "test.json")
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "warnings", []string{
+		`test.json:1:2: unknown config key "candle_lightning_mins"; did you mean "candle_lighting_mins"?`,
+	}, warnStrings(warns))
+
+	var ve config.ValidationError
+	if !errors.As(warns[0], &ve) {
+		t.Fatalf("want a ValidationError, got %T", warns[0])
+	}
+	test.CheckString(t, "Key", "candle_lightning_mins", ve.Key)
+	if !errors.Is(ve, config.ErrUnknownKey) {
+		t.Error("want ErrUnknownKey")
+	}
+
+	_, err = config.ValidateKeys([]byte(`"INVALID"`), "test.json")
+	test.CheckErr(t, err,
+		`failed to parse config from "test.json": expected a JSON object, got INVALID`)
+}
+
+func TestConfig_Check(t *testing.T) {
+	dateRange := func(t *testing.T, spec string) *daterange.DateRange {
+		t.Helper()
+		dr, err := daterange.FromArgs(strings.Fields(spec), false, date(2025, 12, 14))
+		test.CheckErr(t, err, "")
+		return dr
+	}
+
+	cases := []struct {
+		Name   string
+		Modify func(t *testing.T, c *config.Config)
+		Want   []string
+	}{
+		{Name: "default", Modify: func(t *testing.T, c *config.Config) {}},
+		{
+			Name: "today with a month",
+			Modify: func(t *testing.T, c *config.Config) {
+				c.Today = true
+				c.DateRange = dateRange(t, "12 2025")
+			},
+			Want: []string{
+				`today: conflicting settings: today shows a single day, but the date range spec was DateRange<December 2025>`,
+			},
+		},
+		{
+			Name: "today with a day",
+			Modify: func(t *testing.T, c *config.Config) {
+				c.Today = true
+				c.DateRange = dateRange(t, "12 19 2025")
+			},
+		},
+		{
+			Name: "geo without timezone",
+			Modify: func(t *testing.T, c *config.Config) {
+				c.Geo = &config.Coordinates{Lat: 1, Lon: 2}
+			},
+			Want: []string{
+				`geo: conflicting settings: geo is set, but timezone is missing`,
+			},
+		},
+		{
+			Name: "geo with unknown city name",
+			Modify: func(t *testing.T, c *config.Config) {
+				c.City = "Home"
+				c.Geo = &config.Coordinates{Lat: 1, Lon: 2}
+				c.Timezone = "UTC"
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			cfg := config.Default
+			c.Modify(t, &cfg)
+			test.CheckSlice(t, "warnings", c.Want, warnStrings(cfg.Check()))
+		})
+	}
+}
+
+func TestValidate_formats(t *testing.T) {
+	cases := []struct {
+		Name  string
+		File  string
+		Input string
+		Want  []string
+		Err   string
+	}{
+		{
+			Name: "json with comments",
+			File: "test.jsonc",
+			Input: `{
+  // Ops: keep in sync with the shul calendar.
+  "candle_lightning_mins": 40, /* typo */
+}`,
+			Want: []string{
+				`test.jsonc:3:3: unknown config key "candle_lightning_mins"; did you mean "candle_lighting_mins"?`,
+			},
+		},
+		{
+			Name: "yaml",
+			File: "test.yaml",
+			Input: `# Ops-managed config
+city: Jerusalem
+havdalah_mins: 50
+omer: sometimes
+geo:
+  lat: 31.778
+  long: 35.235
+profiles:
+  chag:
+    chag_onyl: true
+    havdalah_deg: 8.5
+`,
+			Want: []string{
+				`test.yaml:4:1: invalid value for "omer": expected true or false, got "sometimes"`,
+				`test.yaml:5:1: conflicting settings: geo is set, but timezone is missing`,
+				`test.yaml:7:3: unknown config key "geo.long"; did you mean "lon"?`,
+				`test.yaml:10:5: unknown config key "profiles.chag.chag_onyl"; did you mean "chag_only"?`,
+				`test.yaml:11:5: profile "chag": conflicting settings: havdalah_mins and havdalah_deg are both set`,
+			},
+		},
+		{
+			Name: "toml",
+			File: "test.toml",
+			Input: `city = "Jerusalem"
+  candle_lightning_mins = 40
+num_years = 0
+
+[profiles.chag]
+chag_onyl = true
+`,
+			Want: []string{
+				`test.toml:2:3: unknown config key "candle_lightning_mins"; did you mean "candle_lighting_mins"?`,
+				`test.toml:3:1: out of range: expected at least 1, got 0`,
+				`test.toml:6:1: unknown config key "profiles.chag.chag_onyl"; did you mean "chag_only"?`,
+			},
+		},
+		{
+			Name:  "yaml not an object",
+			File:  "test.yml",
+			Input: "- a\n",
+			Err:   `failed to parse config from "test.yml": expected a JSON object, got [`,
+		},
+		{
+			Name:  "toml syntax error",
+			File:  "test.toml",
+			Input: "city = \n",
+			Err:   `failed to parse config from "test.toml": toml: line 1 (last key "city"): expected value but found '\n' instead`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			warns, err := config.Validate([]byte(c.Input), c.File)
+			test.CheckErr(t, err, c.Err)
+			test.CheckSlice(t, "warnings", c.Want, warnStrings(warns))
+		})
+	}
+}
@@ -4,13 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hebcal/greg"
 	"github.com/hebcal/hdate"
+
+	"github.com/chaimleib/hebcalfmt/xhdate"
 )
 
 // ErrUnreachable means that there is a coding defect if returned.
@@ -30,8 +31,16 @@ const (
 	// We provide it to interoperate with hebcal classic,
 	// but its purpose is unknown.
 	RangeTypeToday
+
+	// RangeTypeSpan covers from the start of the `From` range
+	// through the end of the `Until` range.
+	RangeTypeSpan
 )
 
+// SpanSeparator splits the start and end of a date range spec,
+// like `2026-03-15..2026-04-20`.
+const SpanSeparator = ".."
+
 func (t RangeType) String() string {
 	switch t {
 	case RangeTypeYear:
@@ -42,6 +51,8 @@ func (t RangeType) String() string {
 		return "DAY"
 	case RangeTypeToday:
 		return "TODAY"
+	case RangeTypeSpan:
+		return "SPAN"
 	default:
 		return fmt.Sprintf("UNKNOWN(%d)", t)
 	}
@@ -72,6 +83,10 @@ type DateRange struct {
 	HebMonth     hdate.HMonth
 	Year         int
 	IsHebrewDate bool
+
+	// From and Until are set if the RangeType is RangeTypeSpan.
+	// The other date fields are left empty.
+	From, Until *DateRange
 }
 
 // FromTime takes a time.Time and converts it to a single-day DateRange.
@@ -93,8 +108,23 @@ func FromTime(t time.Time) *DateRange {
 // The `args` slice should be in the sequence `[[ month [ day ]] year]`,
 // where `day` and `year` are numeric.
 // `month` must be numeric for Gregorian months,
-// or the name of a Hebrew month.
-// For Adar 1 and 2, do not include any spaces.
+// or the name of a Hebrew month, in any spelling accepted by [xhdate.ParseMonth].
+// For a Hebrew month, the day may also come first, like `15 Shevat 5786`,
+// and the day and year may be written in gematriya, like `ט״ו בשבט תשפ״ו`.
+// Args are split on spaces, so a whole date may be passed as a single arg.
+// For a whole month of Adar 1 or 2, do not include any spaces,
+// since `Adar 2 5787` means the 2nd of Adar.
+//
+// Two such specs joined by [SpanSeparator] make a [RangeTypeSpan],
+// from the start of the first through the end of the second,
+// like `2026-03-15..2026-04-20` or `Nisan 1 5786 .. Nisan 20 5786`.
+// If either side is left empty, it means today.
+//
+// A single arg may also be relative to today:
+//   - `+14d` means 14 days, starting today.
+//   - `+2w` means 2 weeks, starting today.
+//   - `this-week` means Sunday through Shabbat of the current week.
+//   - `next-week` means Sunday through Shabbat of the following week.
 //
 // Even if `isHebrewDate` is false,
 // the result's `IsHebrewDate` will be forced true
@@ -115,6 +145,17 @@ func FromArgs(
 		)
 	}
 
+	spec := strings.Join(args, " ")
+	if before, after, ok := strings.Cut(spec, SpanSeparator); ok {
+		return spanFromArgs(args, before, after, isHebrewDate, now)
+	}
+
+	if len(args) == 1 {
+		if dr, err := relativeFromArg(args, now); dr != nil || err != nil {
+			return dr, err
+		}
+	}
+
 	dr := new(DateRange)
 	dr.Source = Source{
 		Args:         args,
@@ -123,13 +164,11 @@ func FromArgs(
 	}
 	dr.IsHebrewDate = isHebrewDate
 
-	// TrimSpace all args
-	args = slices.Clone(args)
-	for i, arg := range args {
-		args[i] = strings.TrimSpace(arg)
-	}
+	// Split on spaces within args too, so that a whole date may be quoted,
+	// like "ט״ו בשבט תשפ״ו".
+	fields := strings.Fields(strings.Join(args, " "))
 
-	switch len(args) {
+	switch len(fields) {
 	case 0:
 		if isHebrewDate {
 			hd := hdate.FromGregorian(now.Year(), now.Month(), now.Day())
@@ -139,12 +178,18 @@ func FromArgs(
 		}
 
 	case 1:
-		arg0 := args[0]
+		arg0 := fields[0]
 		yy, err := strconv.Atoi(arg0)
 		if err == nil {
 			dr.Year = yy /* just year specified */
 			break
 		}
+		if xhdate.HasHebrewLetters(arg0) {
+			if err := dr.parseYear(arg0); err != nil {
+				return nil, err
+			}
+			break
+		}
 
 		// Use custom date format,
 		// since time.DateOnly requires leading zeroes for month and day.
@@ -159,30 +204,36 @@ func FromArgs(
 		dr.RangeType = RangeTypeDay
 
 	case 2:
-		yy, err := strconv.Atoi(args[1])
-		if err != nil {
-			return nil, fmt.Errorf("invalid year: %w", err)
+		if err := dr.parseYear(fields[1]); err != nil {
+			return nil, err
 		}
-		dr.Year = yy
-		if err := dr.parseGregOrHebMonth(args[0]); err != nil {
+		if err := dr.parseGregOrHebMonth(fields[0]); err != nil {
 			return nil, err
 		}
 		dr.RangeType = RangeTypeMonth
 
-	case 3:
-		dd, err := strconv.Atoi(args[1])
+	case 3, 4: // 4 in case of a two-word month, like Adar II
+		// Gregorian dates are always month first, like hebcal,
+		// but Hebrew dates may put the day first, like `15 Shevat 5786`.
+		last := len(fields) - 1
+		day, month := fields[last-1], strings.Join(fields[:last-1], " ")
+		if rest := strings.Join(fields[1:last], " "); xhdate.IsMonthName(rest) {
+			day, month = fields[0], rest
+		}
+		dd, err := xhdate.ParseNumber(day)
 		if err != nil {
 			return nil, fmt.Errorf("invalid day: %w", err)
 		}
 		dr.Day = dd
+		if xhdate.HasHebrewLetters(day) {
+			dr.IsHebrewDate = true
+		}
 
-		yy, err := strconv.Atoi(args[2])
-		if err != nil {
-			return nil, fmt.Errorf("invalid year: %w", err)
+		if err := dr.parseYear(fields[last]); err != nil {
+			return nil, err
 		}
-		dr.Year = yy
 
-		if err := dr.parseGregOrHebMonth(args[0]); err != nil {
+		if err := dr.parseGregOrHebMonth(month); err != nil {
 			return nil, err
 		}
 
@@ -190,7 +241,7 @@ func FromArgs(
 
 	default:
 		return nil, fmt.
-			Errorf("expected at most 3 args for date range spec, got %d", len(args))
+			Errorf("expected at most 4 args for date range spec, got %d", len(fields))
 	}
 
 	// Check months
@@ -235,6 +286,136 @@ func FromArgs(
 	return dr, nil
 }
 
+// spanFromArgs builds a [RangeTypeSpan] from the specs before and after
+// the [SpanSeparator].
+func spanFromArgs(
+	args []string,
+	before, after string,
+	isHebrewDate bool,
+	now time.Time,
+) (*DateRange, error) {
+	if strings.Contains(after, SpanSeparator) {
+		return nil, fmt.Errorf(
+			"expected at most one %q in date range spec, got %q",
+			SpanSeparator,
+			strings.Join(args, " "),
+		)
+	}
+
+	side := func(spec string) (*DateRange, error) {
+		fields := strings.Fields(spec)
+		if len(fields) == 0 {
+			return today(now), nil
+		}
+		return FromArgs(fields, isHebrewDate, now)
+	}
+
+	from, err := side(before)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of date range: %w", err)
+	}
+	until, err := side(after)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of date range: %w", err)
+	}
+
+	return newSpan(args, isHebrewDate, now, from, until)
+}
+
+// relativeFromArg parses date range specs which are relative to now,
+// like `+14d` and `next-week`.
+// If arg is not a relative spec, it returns nil with no error.
+func relativeFromArg(args []string, now time.Time) (*DateRange, error) {
+	arg := strings.TrimSpace(args[0])
+	y, m, d := now.Date()
+	day := func(offset int) *DateRange {
+		return FromTime(time.Date(y, m, d+offset, 0, 0, 0, 0, now.Location()))
+	}
+
+	switch arg {
+	case "this-week":
+		sunday := -int(now.Weekday())
+		return newSpan(args, false, now, day(sunday), day(sunday+6))
+
+	case "next-week":
+		sunday := 7 - int(now.Weekday())
+		return newSpan(args, false, now, day(sunday), day(sunday+6))
+	}
+
+	count, ok := strings.CutPrefix(arg, "+")
+	if !ok || count == "" {
+		return nil, nil
+	}
+	var unitDays int
+	switch count[len(count)-1] {
+	case 'd':
+		unitDays = 1
+	case 'w':
+		unitDays = 7
+	case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return nil, nil // a signed year, like +2026
+	default:
+		return nil, fmt.Errorf(
+			"invalid relative date range %q: unit must be d or w", arg)
+	}
+	n, err := strconv.Atoi(count[:len(count)-1])
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf(
+			"invalid relative date range %q: expected a positive count", arg)
+	}
+	return newSpan(args, false, now, day(0), day(n*unitDays-1))
+}
+
+// today returns a single-day DateRange for the calendar date of now.
+func today(now time.Time) *DateRange {
+	y, m, d := now.Date()
+	return FromTime(time.Date(y, m, d, 0, 0, 0, 0, now.Location()))
+}
+
+func newSpan(
+	args []string,
+	isHebrewDate bool,
+	now time.Time,
+	from, until *DateRange,
+) (*DateRange, error) {
+	dr := &DateRange{
+		Source: Source{
+			Args:         args,
+			IsHebrewDate: isHebrewDate,
+			Now:          now,
+		},
+		RangeType:    RangeTypeSpan,
+		IsHebrewDate: from.IsHebrewDate,
+		From:         from,
+		Until:        until,
+	}
+
+	start, end := dr.Start(false), dr.End(false)
+	if end.Abs() < start.Abs() {
+		return nil, fmt.Errorf(
+			"end of date range (%s) is before its start (%s)",
+			until.basicString(),
+			from.basicString(),
+		)
+	}
+	return dr, nil
+}
+
+// parseYear sets the Year from arg,
+// which may be written in gematriya for Hebrew years.
+// A gematriya year forces IsHebrewDate on.
+func (dr *DateRange) parseYear(arg string) error {
+	yy, err := xhdate.ParseYear(arg)
+	if err != nil {
+		return fmt.Errorf("invalid year: %w", err)
+	}
+	dr.Year = yy
+	if xhdate.HasHebrewLetters(arg) {
+		dr.IsHebrewDate = true
+	}
+	return nil
+}
+
 func (dr *DateRange) parseGregOrHebMonth(arg string) (err error) {
 	dr.IsHebrewDate, dr.GregMonth, dr.HebMonth, err = parseGregOrHebMonth(
 		dr.IsHebrewDate, dr.Year, arg)
@@ -261,9 +442,9 @@ func parseGregOrHebMonth(
 		return
 	}
 
-	hm, err := hdate.MonthFromName(arg)
+	hm, err := xhdate.ParseMonth(arg)
 	if err != nil {
-		if isHebrewYear {
+		if isHebrewYear || xhdate.HasHebrewLetters(arg) {
 			err = fmt.Errorf("unknown Hebrew month: %q", arg)
 		} else {
 			err = fmt.Errorf("Gregorian months must be numeric, got %q", arg)
@@ -289,6 +470,9 @@ func (dr DateRange) basicString() string {
 	}
 
 	switch dr.RangeType {
+	case RangeTypeSpan:
+		return dr.From.basicString() + SpanSeparator + dr.Until.basicString()
+
 	case RangeTypeYear:
 		if dr.IsHebrewDate {
 			return fmt.Sprintf("%d (Hebrew)", dr.Year)
@@ -346,6 +530,9 @@ func (dr DateRange) Start(noJulian bool) hdate.HDate {
 		}
 		return fromGregorian(dr.Year, time.January, 1)
 
+	case RangeTypeSpan:
+		return dr.From.Start(noJulian)
+
 	default:
 		slog.Error(
 			"called Start on a DateRange with an unknown RangeType",
@@ -386,6 +573,9 @@ func (dr DateRange) End(noJulian bool) hdate.HDate {
 		}
 		return fromGregorian(dr.Year, time.December, 31)
 
+	case RangeTypeSpan:
+		return dr.Until.End(noJulian)
+
 	default:
 		slog.Error(
 			"called End on a DateRange with an unknown RangeType",
@@ -29,6 +29,28 @@ func hdatesEqual(a, b hdate.HDate) bool {
 	return a.Day() == b.Day() && a.Month() == b.Month() && a.Year() == b.Year()
 }
 
+// argsDay is the Gregorian day DateRange which FromArgs returns for args.
+func argsDay(
+	now time.Time,
+	y int,
+	m time.Month,
+	d int,
+	args ...string,
+) *daterange.DateRange {
+	return &daterange.DateRange{
+		Source:    daterange.Source{Args: args, Now: now},
+		RangeType: daterange.RangeTypeDay,
+		Day:       d,
+		GregMonth: m,
+		Year:      y,
+	}
+}
+
+// timeDay is the DateRange which FromTime returns for a UTC date.
+func timeDay(y int, m time.Month, d int) *daterange.DateRange {
+	return daterange.FromTime(date(y, m, d))
+}
+
 func TestRangeType_String(t *testing.T) {
 	cases := []struct {
 		Input daterange.RangeType
@@ -38,6 +60,7 @@ func TestRangeType_String(t *testing.T) {
 		{Input: daterange.RangeTypeMonth, Want: "MONTH"},
 		{Input: daterange.RangeTypeToday, Want: "TODAY"},
 		{Input: daterange.RangeTypeDay, Want: "DAY"},
+		{Input: daterange.RangeTypeSpan, Want: "SPAN"},
 		{Input: 99, Want: "UNKNOWN(99)"},
 	}
 	for _, c := range cases {
@@ -416,6 +439,122 @@ func TestDateRange_FromArgs(t *testing.T) {
 			},
 		},
 
+		{
+			Name: "Hebrew day - day first",
+			Args: []string{"15", "Shevat", "5786"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"15", "Shevat", "5786"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeDay,
+				IsHebrewDate: true,
+				Day:          15,
+				HebMonth:     hdate.Shvat,
+				Year:         5786,
+			},
+		},
+		{
+			Name: "Hebrew day - gematriya",
+			Args: []string{"ט״ו בשבט תשפ״ו"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"ט״ו בשבט תשפ״ו"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeDay,
+				IsHebrewDate: true,
+				Day:          15,
+				HebMonth:     hdate.Shvat,
+				Year:         5786,
+			},
+		},
+		{
+			Name: "Hebrew day - gematriya with ASCII quotes",
+			Args: []string{`ט"ו`, "בשבט", `ה'תשפ"ו`},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{`ט"ו`, "בשבט", `ה'תשפ"ו`},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeDay,
+				IsHebrewDate: true,
+				Day:          15,
+				HebMonth:     hdate.Shvat,
+				Year:         5786,
+			},
+		},
+		{
+			Name: "Hebrew day - two-word month",
+			Args: []string{"14", "Adar", "II", "5787"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"14", "Adar", "II", "5787"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeDay,
+				IsHebrewDate: true,
+				Day:          14,
+				HebMonth:     hdate.Adar2,
+				Year:         5787,
+			},
+		},
+		{
+			Name: "Hebrew day - two-word month first",
+			Args: []string{"אדר", "ב׳", "14", "תשפ״ז"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"אדר", "ב׳", "14", "תשפ״ז"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeDay,
+				IsHebrewDate: true,
+				Day:          14,
+				HebMonth:     hdate.Adar2,
+				Year:         5787,
+			},
+		},
+		{
+			Name: "Hebrew month - gematriya year",
+			Args: []string{"Shevat", "תשפ״ו"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"Shevat", "תשפ״ו"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeMonth,
+				IsHebrewDate: true,
+				HebMonth:     hdate.Shvat,
+				Year:         5786,
+			},
+		},
+		{
+			Name: "Hebrew month - Hebrew script",
+			Args: []string{"אדר-ב", "5787"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"אדר-ב", "5787"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeMonth,
+				IsHebrewDate: true,
+				HebMonth:     hdate.Adar2,
+				Year:         5787,
+			},
+		},
+		{
+			Name: "Hebrew year - gematriya",
+			Args: []string{"תשפ״ו"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"תשפ״ו"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeYear,
+				IsHebrewDate: true,
+				Year:         5786,
+			},
+		},
 		{
 			Name:         "invalid Hebrew day - zero day",
 			Args:         []string{"Iyar", "0", "5784"},
@@ -429,10 +568,208 @@ func TestDateRange_FromArgs(t *testing.T) {
 			Err:          `invalid day for Iyyar 5784: 31`,
 		},
 
+		{
+			Name: "Gregorian span",
+			Args: []string{"2026-03-15..2026-04-20"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"2026-03-15..2026-04-20"},
+					Now:  defaultNow,
+				},
+				RangeType: daterange.RangeTypeSpan,
+				From:      argsDay(defaultNow, 2026, time.March, 15, "2026-03-15"),
+				Until:     argsDay(defaultNow, 2026, time.April, 20, "2026-04-20"),
+			},
+		},
+		{
+			Name: "Hebrew span with spaces",
+			Args: []string{"Nisan", "1", "5786", "..", "Nisan", "20", "5786"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"Nisan", "1", "5786", "..", "Nisan", "20", "5786"},
+					Now:  defaultNow,
+				},
+				RangeType:    daterange.RangeTypeSpan,
+				IsHebrewDate: true,
+				From: &daterange.DateRange{
+					Source: daterange.Source{
+						Args: []string{"Nisan", "1", "5786"},
+						Now:  defaultNow,
+					},
+					RangeType:    daterange.RangeTypeDay,
+					IsHebrewDate: true,
+					Day:          1,
+					HebMonth:     hdate.Nisan,
+					Year:         5786,
+				},
+				Until: &daterange.DateRange{
+					Source: daterange.Source{
+						Args: []string{"Nisan", "20", "5786"},
+						Now:  defaultNow,
+					},
+					RangeType:    daterange.RangeTypeDay,
+					IsHebrewDate: true,
+					Day:          20,
+					HebMonth:     hdate.Nisan,
+					Year:         5786,
+				},
+			},
+		},
+		{
+			Name: "span of months",
+			Args: []string{"3", "2026..5", "2026"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"3", "2026..5", "2026"},
+					Now:  defaultNow,
+				},
+				RangeType: daterange.RangeTypeSpan,
+				From: &daterange.DateRange{
+					Source: daterange.Source{
+						Args: []string{"3", "2026"},
+						Now:  defaultNow,
+					},
+					RangeType: daterange.RangeTypeMonth,
+					GregMonth: time.March,
+					Year:      2026,
+				},
+				Until: &daterange.DateRange{
+					Source: daterange.Source{
+						Args: []string{"5", "2026"},
+						Now:  defaultNow,
+					},
+					RangeType: daterange.RangeTypeMonth,
+					GregMonth: time.May,
+					Year:      2026,
+				},
+			},
+		},
+		{
+			Name: "span from today",
+			Args: []string{"..2026-01-05"},
+			Want: daterange.DateRange{
+				Source: daterange.Source{
+					Args: []string{"..2026-01-05"},
+					Now:  defaultNow,
+				},
+				RangeType: daterange.RangeTypeSpan,
+				From:      timeDay(2025, time.December, 30),
+				Until:     argsDay(defaultNow, 2026, time.January, 5, "2026-01-05"),
+			},
+		},
+		{
+			Name: "relative days",
+			Args: []string{"+14d"},
+			Want: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"+14d"}, Now: defaultNow},
+				RangeType: daterange.RangeTypeSpan,
+				From:      timeDay(2025, time.December, 30),
+				Until:     timeDay(2026, time.January, 12),
+			},
+		},
+		{
+			Name: "relative weeks",
+			Args: []string{"+2w"},
+			Want: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"+2w"}, Now: defaultNow},
+				RangeType: daterange.RangeTypeSpan,
+				From:      timeDay(2025, time.December, 30),
+				Until:     timeDay(2026, time.January, 12),
+			},
+		},
+		{
+			Name: "this week",
+			Args: []string{"this-week"},
+			Want: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"this-week"}, Now: defaultNow},
+				RangeType: daterange.RangeTypeSpan,
+				From:      timeDay(2025, time.December, 28),
+				Until:     timeDay(2026, time.January, 3),
+			},
+		},
+		{
+			Name: "next week",
+			Args: []string{"next-week"},
+			Want: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"next-week"}, Now: defaultNow},
+				RangeType: daterange.RangeTypeSpan,
+				From:      timeDay(2026, time.January, 4),
+				Until:     timeDay(2026, time.January, 10),
+			},
+		},
+		{
+			Name: "signed year is not relative",
+			Args: []string{"+2026"},
+			Want: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"+2026"}, Now: defaultNow},
+				RangeType: daterange.RangeTypeYear,
+				Year:      2026,
+			},
+		},
+		{
+			Name: "invalid span - end before start",
+			Args: []string{"2026-04-20..2026-03-15"},
+			Err:  "end of date range (15 March 2026) is before its start (20 April 2026)",
+		},
+		{
+			Name: "invalid span - too many separators",
+			Args: []string{"2026..2027..2028"},
+			Err:  `expected at most one ".." in date range spec, got "2026..2027..2028"`,
+		},
+		{
+			Name: "invalid span - start",
+			Args: []string{"13", "2026..2027"},
+			Err:  "invalid start of date range: invalid month: 13",
+		},
+		{
+			Name: "invalid span - end",
+			Args: []string{"2026..INVALID"},
+			Err:  `invalid end of date range: parsing time "INVALID" as "2006-1-2": cannot parse "INVALID" as "2006"`,
+		},
+		{
+			Name: "invalid relative - unit",
+			Args: []string{"+3x"},
+			Err:  `invalid relative date range "+3x": unit must be d or w`,
+		},
+		{
+			Name: "invalid relative - zero",
+			Args: []string{"+0d"},
+			Err:  `invalid relative date range "+0d": expected a positive count`,
+		},
+		{
+			Name: "invalid relative - missing count",
+			Args: []string{"+d"},
+			Err:  `invalid relative date range "+d": expected a positive count`,
+		},
 		{
 			Name: "invalid Args - too many args",
+			Args: []string{"Adar", "II", "14", "5787", "INVALIDEXTRA"},
+			Err:  `expected at most 4 args for date range spec, got 5`,
+		},
+		{
+			Name: "invalid Args - extra arg",
 			Args: []string{"Iyar", "31", "5784", "INVALIDEXTRA"},
-			Err:  `expected at most 3 args for date range spec, got 4`,
+			Err:  `invalid year: strconv.Atoi: parsing "INVALIDEXTRA": invalid syntax`,
+		},
+		{
+			Name: "invalid Hebrew day - gematriya",
+			Args: []string{"טx", "בשבט", "תשפ״ו"},
+			Err:  `invalid day: invalid gematriya "טx": unexpected 'x'`,
+		},
+		{
+			Name: "invalid Hebrew year - gematriya",
+			Args: []string{"ט״ו", "בשבט", "תשפ״x"},
+			Err:  `invalid year: invalid gematriya "תשפ״x": unexpected 'x'`,
+		},
+		{
+			Name: "invalid Hebrew month - Hebrew script",
+			Args: []string{"בשבע", "ט״ו", "תשפ״ו"},
+			Err:  `unknown Hebrew month: "בשבע"`,
+		},
+		{
+			Name: "invalid Hebrew month - gematriya year with number",
+			Args: []string{"5", "תשפ״ו"},
+			Err:  `expected Hebrew month name, got a number: 5`,
 		},
 		{
 			Name:    "invalid zero now",
@@ -563,6 +900,21 @@ func TestDateRange_String(t *testing.T) {
 			},
 			WantInner: "2 Iyyar 5786 --today",
 		},
+		// Spans
+		{
+			Input: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"3", "2026..4", "20", "2026"}, Now: now},
+				RangeType: daterange.RangeTypeSpan,
+				From: &daterange.DateRange{
+					Source:    daterange.Source{Args: []string{"3", "2026"}, Now: now},
+					RangeType: daterange.RangeTypeMonth,
+					GregMonth: time.March,
+					Year:      2026,
+				},
+				Until: argsDay(now, 2026, time.April, 20, "4", "20", "2026"),
+			},
+			WantInner: "March 2026..20 April 2026",
+		},
 		// Boundary conditions
 		{
 			Input: daterange.DateRange{
@@ -715,6 +1067,27 @@ func TestDateRange_Start(t *testing.T) {
 			},
 			Want: hYear,
 		},
+		// Span
+		{
+			Name: "span",
+			DR: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"5", "2025..", "6", "2025"}},
+				RangeType: daterange.RangeTypeSpan,
+				From: &daterange.DateRange{
+					Source:    daterange.Source{Args: []string{"5", "2025"}},
+					RangeType: daterange.RangeTypeMonth,
+					GregMonth: time.May,
+					Year:      2025,
+				},
+				Until: &daterange.DateRange{
+					Source:    daterange.Source{Args: []string{"6", "2025"}},
+					RangeType: daterange.RangeTypeMonth,
+					GregMonth: time.June,
+					Year:      2025,
+				},
+			},
+			Want: hdate.FromGregorian(2025, time.May, 1),
+		},
 		{
 			Name: "invalid RangeType",
 			DR: daterange.DateRange{
@@ -899,6 +1272,27 @@ func TestDateRange_End(t *testing.T) {
 			},
 			Want: hYear,
 		},
+		// Span
+		{
+			Name: "span",
+			DR: daterange.DateRange{
+				Source:    daterange.Source{Args: []string{"5", "2025..", "6", "2025"}},
+				RangeType: daterange.RangeTypeSpan,
+				From: &daterange.DateRange{
+					Source:    daterange.Source{Args: []string{"5", "2025"}},
+					RangeType: daterange.RangeTypeMonth,
+					GregMonth: time.May,
+					Year:      2025,
+				},
+				Until: &daterange.DateRange{
+					Source:    daterange.Source{Args: []string{"6", "2025"}},
+					RangeType: daterange.RangeTypeMonth,
+					GregMonth: time.June,
+					Year:      2025,
+				},
+			},
+			Want: hdate.FromGregorian(2025, time.June, 30),
+		},
 		{
 			Name: "invalid RangeType",
 			DR: daterange.DateRange{
@@ -0,0 +1,12 @@
+// Package examples holds the example templates and files of the README.
+// Some of the templates are built into hebcalfmt; see [Templates].
+package examples
+
+import "embed"
+
+// Templates are the example templates which are built into hebcalfmt,
+// so that they can run by name, like `hebcalfmt @today`.
+//
+//go:embed today.tmpl monthCalendar.tmpl date.tmpl thisShabbat.tmpl
+//go:embed mincha.tmpl hebcalClassic.tmpl chabad.tmpl chabad-events.txt
+var Templates embed.FS
@@ -728,7 +728,7 @@ The molad for this month, Nison, is Shabbos, 29 Ador 5785 at 7:46 and 1 cheilek
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
 			logBuf := test.Logger(t)
-			args := []string{fpath, "--config=chabad.json"}
+			args := []string{fpath}
 			if c.Args != "" {
 				args = append(args, strings.Fields(c.Args)...)
 			}
@@ -1259,22 +1259,22 @@ A halachic hour is 1h9m51s.
 		{
 			Name: "invalid date",
 			Date: "bad date",
-			Err:  `template: customZmanim.tmpl:6:31: executing "customZmanim.tmpl" at <timeParse $.time.DateOnly .>: error calling timeParse: parsing time "bad date" as "2006-01-02": cannot parse "bad date" as "2006"`,
+			Err:  `customZmanim.tmpl: invalid value for parameter date: parsing time "bad date" as "2006-01-02": cannot parse "bad date" as "2006"`,
 		},
 		{
 			Name: "invalid city",
 			City: "Bad City",
-			Err:  `template: customZmanim.tmpl:3:12: executing "customZmanim.tmpl" at <lookupCity $city>: error calling lookupCity: unknown city "Bad City"`,
+			Err:  `customZmanim.tmpl: invalid value for parameter city: unknown city "Bad City"`,
 		},
 	}
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
 			args := []string{fpath}
 			if c.City != "" {
-				t.Setenv("CITY", c.City)
+				args = append(args, "--param", "city="+c.City)
 			}
 			if c.Date != "" {
-				t.Setenv("DATE", c.Date)
+				args = append(args, "--param", "date="+c.Date)
 			}
 
 			var buf bytes.Buffer
@@ -1289,3 +1289,26 @@ A halachic hour is 1h9m51s.
 		})
 	}
 }
+
+func TestExamples_Lint(t *testing.T) {
+	files, now := setupExample(t)
+
+	args := []string{"lint"}
+	err := fs.WalkDir(files, ".", func(fpath string, d fs.DirEntry, err error) error {
+		if err == nil && strings.HasSuffix(fpath, ".tmpl") {
+			args = append(args, fpath)
+		}
+		return err
+	})
+	test.CheckErr(t, err, "")
+	if len(args) == 1 {
+		t.Fatal("found no templates")
+	}
+
+	var buf bytes.Buffer
+	err = cli.RunInEnvironment(args, files, now, templating.BuildData, &buf)
+	test.CheckErr(t, err, "")
+	if strings.Count(buf.String(), ": ok\n") != len(args)-1 {
+		t.Errorf("expected every template to be ok, got:\n%s", buf.String())
+	}
+}
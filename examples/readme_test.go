@@ -283,6 +283,9 @@ var syntaxExts = map[string]string{
 	"text": ".txt",
 	"json": ".json",
 	"tmpl": ".tmpl",
+	"yaml": ".yaml",
+	"toml": ".toml",
+	"ics":  ".ics",
 }
 
 func (rc *ReadmeContext) FencedBlock(
@@ -310,7 +313,7 @@ func (rc *ReadmeContext) FencedBlock(
 		rc.Cases = append(rc.Cases, rc.ProgressCase)
 		rc.ProgressCase = NewReadmeCase()
 
-	case "text", "json", "tmpl":
+	case "text", "json", "tmpl", "yaml", "toml", "ics":
 		if rc.LastNonemptyLine == nil {
 			fmt.Fprintf(
 				rc.DebugWriter,
@@ -3,10 +3,12 @@ module github.com/chaimleib/hebcalfmt
 go 1.25.5
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/hebcal/greg v1.0.2
 	github.com/hebcal/hdate v1.2.1
 	github.com/hebcal/hebcal-go v0.11.0
 	github.com/spf13/pflag v1.0.10
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,289 @@
+package hcfiles
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/xhdate"
+)
+
+// ParseEventsCSV parses an [io.Reader] of CSV records,
+// like a spreadsheet export, and returns the [Events] entries.
+// In case of an error, fileName helps with debugging.
+//
+// The first record is a header which names the columns.
+// These columns are required, in any order:
+//
+//	month   the Hebrew month, like Cheshvan or Adar II
+//	day     the day of the month
+//	name    the description; may also be called description or desc
+//
+// Header names are case-insensitive,
+// and other columns and blank records are skipped.
+// The entries all become [hebcal.UserEvent]s.
+func ParseEventsCSV(f io.Reader, fileName string) (Events, error) {
+	var result Events
+	errs := scanCSV(f, fileName, []string{"month", "day", "name"},
+		func(rec csvRecord) error {
+			month, err := xhdate.ParseMonth(rec.get("month"))
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidMonth, err)
+			}
+			day, err := strconv.Atoi(rec.get("day"))
+			if err != nil || day < 1 || day > 30 {
+				return fmt.Errorf("%w: %q", ErrInvalidDays, rec.get("day"))
+			}
+			desc := rec.get("name")
+			if desc == "" {
+				return fmt.Errorf("%w: missing description", ErrInvalidFormat)
+			}
+			result.UserEvents = append(
+				result.UserEvents,
+				hebcal.UserEvent{Month: month, Day: day, Desc: desc},
+			)
+			return nil
+		})
+	if len(errs) != 0 {
+		return Events{}, fmt.Errorf("ParseEventsCSV: %w", errors.Join(errs...))
+	}
+	return result, nil
+}
+
+// ParseYahrzeitsCSV parses an [io.Reader] of CSV records,
+// like a spreadsheet export, and returns the [Yahrzeit] entries.
+// In case of an error, fileName helps with debugging.
+//
+// The first record is a header which names the columns,
+// like for [ParseEventsCSV].
+// These columns are required:
+//
+//	month          the Gregorian month number, or the name of a Hebrew month
+//	day            the day of the month
+//	year           the Gregorian or Hebrew year, like the month
+//	name           the description; may also be called description or desc
+//
+// These columns are optional, and hold the options of [ParseYahrzeitEntries]:
+//
+//	after_sunset   yes if the death was after sunset; also true, x or 1
+//	time           the time of death, like 21:15
+//	adar           1, 2 or both
+//	day30          29 or 1
+func ParseYahrzeitsCSV(f io.Reader, fileName string) ([]Yahrzeit, error) {
+	var entries []Yahrzeit
+	errs := scanCSV(f, fileName, []string{"month", "day", "year", "name"},
+		func(rec csvRecord) error {
+			afterSunset, err := parseCSVBool(rec.get("after_sunset"))
+			if err != nil {
+				return fmt.Errorf("%w: after_sunset: %w", ErrInvalidOption, err)
+			}
+
+			// Build a line in the format of a yahrzeits file.
+			fields := []string{rec.get("month"), rec.get("day"), rec.get("year")}
+			if afterSunset {
+				fields = append(fields, "after-sunset")
+			}
+			for _, key := range []string{"time", "adar", "day30"} {
+				if value := rec.get(key); value != "" {
+					fields = append(fields, key+"="+value)
+				}
+			}
+			name := rec.get("name")
+			if name == "" {
+				return fmt.Errorf("%w: missing description", ErrInvalidFormat)
+			}
+			fields = append(fields, name)
+
+			entry, err := parseYahrzeitLine(strings.Join(fields, " "))
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	if len(errs) != 0 {
+		return nil, fmt.Errorf("ParseYahrzeitsCSV: %w", errors.Join(errs...))
+	}
+	return entries, nil
+}
+
+// WriteEventsCSV writes the events as CSV records
+// which [ParseEventsCSV] understands, with a header.
+func WriteEventsCSV(w io.Writer, events []hebcal.UserEvent) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"month", "day", "name"})
+	for _, ev := range events {
+		cw.Write([]string{ev.Month.String(), strconv.Itoa(ev.Day), ev.Desc})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteYahrzeitsCSV writes the entries as CSV records
+// which [ParseYahrzeitsCSV] understands, with a header.
+// The time, adar and day30 columns are only written
+// if some entry needs them.
+func WriteYahrzeitsCSV(w io.Writer, entries []Yahrzeit) error {
+	header := []string{"month", "day", "year", "name", "after_sunset"}
+	for _, key := range []string{"time", "adar", "day30"} {
+		if slices.ContainsFunc(entries, func(y Yahrzeit) bool {
+			return y.option(key) != ""
+		}) {
+			header = append(header, key)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, y := range entries {
+		month, day, year := y.dateFields()
+		record := []string{month, day, year, y.Name, ""}
+		if y.AfterSunset {
+			record[4] = "yes"
+		}
+		for _, key := range header[5:] {
+			record = append(record, y.option(key))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvRecord is a record of a CSV file, with the columns named by its header.
+type csvRecord struct {
+	fields  []string
+	columns map[string]int
+}
+
+// get returns the trimmed field in the column named key,
+// or the empty string if there is none.
+func (rec csvRecord) get(key string) string {
+	i, ok := rec.columns[key]
+	if !ok || i >= len(rec.fields) {
+		return ""
+	}
+	return strings.TrimSpace(rec.fields[i])
+}
+
+// isBlank reports whether all the fields of rec are blank.
+func (rec csvRecord) isBlank() bool {
+	for _, field := range rec.fields {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// csvColumnAliases maps alternative header names to the column keys.
+var csvColumnAliases = map[string]string{
+	"description": "name",
+	"desc":        "name",
+	"aftersunset": "after_sunset",
+}
+
+// csvColumnKey returns the key of the column with the header name.
+func csvColumnKey(name string) string {
+	key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	key = strings.NewReplacer("-", "_", " ", "_").Replace(key)
+	if alias, ok := csvColumnAliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// scanCSV reads the header and then the records of f,
+// calling parseRecord on each record which is not blank.
+// It returns the errors of the file as [SyntaxError]s.
+func scanCSV(
+	f io.Reader,
+	fileName string,
+	required []string,
+	parseRecord func(csvRecord) error,
+) []error {
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return []error{csvSyntaxError(err, fileName)}
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		key := csvColumnKey(name)
+		if _, ok := columns[key]; !ok {
+			columns[key] = i
+		}
+	}
+
+	var errs []error
+	for _, key := range required {
+		if _, ok := columns[key]; !ok {
+			errs = append(errs, SyntaxError{
+				Err:        fmt.Errorf("%w: missing column %q", ErrInvalidFormat, key),
+				FileName:   fileName,
+				LineNumber: 1,
+			})
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+
+	for {
+		fields, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			errs = append(errs, csvSyntaxError(err, fileName))
+			if _, ok := err.(*csv.ParseError); ok {
+				continue
+			}
+			break
+		}
+
+		rec := csvRecord{fields: fields, columns: columns}
+		if rec.isBlank() {
+			continue
+		}
+		if err := parseRecord(rec); err != nil {
+			line, _ := r.FieldPos(0)
+			errs = append(errs, SyntaxError{
+				Err:        err,
+				FileName:   fileName,
+				LineNumber: line,
+			})
+		}
+	}
+	return errs
+}
+
+// csvSyntaxError returns err as a [SyntaxError],
+// with the line number of a [csv.ParseError].
+func csvSyntaxError(err error, fileName string) error {
+	var pe *csv.ParseError
+	if errors.As(err, &pe) {
+		return SyntaxError{Err: pe.Err, FileName: fileName, LineNumber: pe.Line}
+	}
+	return fmt.Errorf("%s: %w", fileName, err)
+}
+
+// parseCSVBool parses a yes/no field of a spreadsheet.
+func parseCSVBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "no", "n", "false", "0":
+		return false, nil
+	case "yes", "y", "true", "x", "1":
+		return true, nil
+	}
+	return false, fmt.Errorf("expected yes or no, got %q", s)
+}
@@ -0,0 +1,221 @@
+package hcfiles_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestParseEventsCSV(t *testing.T) {
+	const fileName = "events.csv"
+	cases := []struct {
+		Name    string
+		Content string
+		WantErr string
+		Want    []hebcal.UserEvent
+	}{
+		{Name: "empty", Content: ""},
+		{
+			Name: "header names",
+			Content: "\ufeffNotes,Description,DAY,Month\n" +
+				"from the spreadsheet,Birthday - Ben Ploni,2,Tishrei\n" +
+				",,,\n" +
+				`,"Shul dinner, annual",1,Adar II` + "\n",
+			Want: []hebcal.UserEvent{
+				{Month: hdate.Tishrei, Day: 2, Desc: "Birthday - Ben Ploni"},
+				{Month: hdate.Adar2, Day: 1, Desc: "Shul dinner, annual"},
+			},
+		},
+		{
+			Name:    "missing columns",
+			Content: "month,desc\nTishrei,Birthday\n",
+			WantErr: "ParseEventsCSV: " + hcfiles.SyntaxError{
+				Err:        fmt.Errorf("%w: missing column %q", hcfiles.ErrInvalidFormat, "day"),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid records",
+			Content: "month,day,name\nTishrei,2,Birthday\nFoo,2,Party\n\nKislev,31,Party\nKislev,3,\n",
+			WantErr: fmt.Sprintf("ParseEventsCSV: %v", errors.Join(
+				hcfiles.SyntaxError{
+					Err: fmt.Errorf("%w: %w", hcfiles.ErrInvalidMonth,
+						errors.New(`unknown Hebrew month: "Foo"`)),
+					FileName:   fileName,
+					LineNumber: 3,
+				},
+				hcfiles.SyntaxError{
+					Err:        fmt.Errorf("%w: %q", hcfiles.ErrInvalidDays, "31"),
+					FileName:   fileName,
+					LineNumber: 5,
+				},
+				hcfiles.SyntaxError{
+					Err:        fmt.Errorf("%w: missing description", hcfiles.ErrInvalidFormat),
+					FileName:   fileName,
+					LineNumber: 6,
+				},
+			)),
+		},
+		{
+			Name:    "invalid CSV",
+			Content: "month,day,name\nTishrei,2,\"Birthday\n",
+			WantErr: "ParseEventsCSV: " + hcfiles.SyntaxError{
+				Err:        errors.New(`extraneous or missing " in quoted-field`),
+				FileName:   fileName,
+				LineNumber: 2,
+			}.Error(),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := hcfiles.ParseEventsCSV(strings.NewReader(c.Content), fileName)
+			test.CheckErr(t, err, c.WantErr)
+			test.CheckSlice(t, "events", c.Want, got.UserEvents)
+		})
+	}
+}
+
+func TestParseYahrzeitsCSV(t *testing.T) {
+	const fileName = "yahrzeits.csv"
+	cases := []struct {
+		Name    string
+		Content string
+		WantErr string
+		Want    []hcfiles.Yahrzeit
+	}{
+		{
+			Name: "Gregorian and Hebrew dates",
+			Content: "name,month,day,year,After Sunset,time,adar,day30\n" +
+				"Joe Shmo,10,8,1967,yes,,,\n" +
+				"Jane Doe,10,8,1967,,21:15,,\n" +
+				"Moshe,Adar,7,5783,no,,both,\n" +
+				"Sarah,Cheshvan,30,5720,,,,1\n" +
+				"Ploni,Adar 2,15,5784,,,,\n",
+			Want: []hcfiles.Yahrzeit{
+				{Date: date(1967, time.October, 8), AfterSunset: true, Name: "Joe Shmo"},
+				{
+					Date:  date(1967, time.October, 8),
+					Timed: true,
+					Time:  21*time.Hour + 15*time.Minute,
+					Name:  "Jane Doe",
+				},
+				{
+					HebrewDate: hdate.New(5783, hdate.Adar1, 7),
+					Adar:       hcfiles.AdarBoth,
+					Name:       "Moshe",
+				},
+				{
+					HebrewDate: hdate.New(5720, hdate.Cheshvan, 30),
+					Day30:      hcfiles.Day30Next,
+					Name:       "Sarah",
+				},
+				{HebrewDate: hdate.New(5784, hdate.Adar2, 15), Name: "Ploni"},
+			},
+		},
+		{
+			Name:    "invalid records",
+			Content: "name,month,day,year,after_sunset\nJoe,13,8,1967,\nJane,10,8,1967,maybe\nSam,10,8,1967\n",
+			WantErr: fmt.Sprintf("ParseYahrzeitsCSV: %v", errors.Join(
+				hcfiles.SyntaxError{
+					Err:        hcfiles.ErrInvalidMonth,
+					FileName:   fileName,
+					LineNumber: 2,
+				},
+				hcfiles.SyntaxError{
+					Err: fmt.Errorf("%w: after_sunset: %w", hcfiles.ErrInvalidOption,
+						errors.New(`expected yes or no, got "maybe"`)),
+					FileName:   fileName,
+					LineNumber: 3,
+				},
+			)),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := hcfiles.ParseYahrzeitsCSV(strings.NewReader(c.Content), fileName)
+			test.CheckErr(t, err, c.WantErr)
+			test.CheckSlice(t, "yahrzeits", c.Want, got)
+		})
+	}
+}
+
+func TestWriteEventsCSV(t *testing.T) {
+	events := []hebcal.UserEvent{
+		{Month: hdate.Tishrei, Day: 2, Desc: "Birthday - Ben Ploni"},
+		{Month: hdate.Adar1, Day: 30, Desc: "Dinner, annual"},
+	}
+	var buf strings.Builder
+	test.CheckErr(t, hcfiles.WriteEventsCSV(&buf, events), "")
+	test.CheckString(t, "csv",
+		"month,day,name\nTishrei,2,Birthday - Ben Ploni\nAdar I,30,\"Dinner, annual\"\n",
+		buf.String())
+
+	got, err := hcfiles.ParseEventsCSV(strings.NewReader(buf.String()), "events.csv")
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "round trip", events, got.UserEvents)
+}
+
+func TestWriteEvents(t *testing.T) {
+	events := []hebcal.UserEvent{
+		{Month: hdate.Shvat, Day: 15, Desc: "Tu BiShvat seder"},
+		{Month: hdate.Adar1, Day: 30, Desc: "Dinner"},
+		{Month: hdate.Adar2, Day: 7, Desc: "Birthday"},
+	}
+	var buf strings.Builder
+	test.CheckErr(t, hcfiles.WriteEvents(&buf, events), "")
+	test.CheckString(t, "lines",
+		"Sh'vat 15 Tu BiShvat seder\nAdar1 30 Dinner\nAdar2 7 Birthday\n",
+		buf.String())
+
+	got, err := hcfiles.ParseEvents(strings.NewReader(buf.String()), "events.txt")
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "round trip", events, got)
+}
+
+func TestWriteYahrzeits(t *testing.T) {
+	entries := []hcfiles.Yahrzeit{
+		{Date: date(1967, time.October, 8), AfterSunset: true, Name: "Joe Shmo"},
+		{
+			Date:  date(1967, time.October, 8),
+			Timed: true,
+			Time:  21*time.Hour + 5*time.Minute,
+			Name:  "Jane Doe",
+		},
+		{HebrewDate: hdate.New(5783, hdate.Adar1, 7), Adar: hcfiles.AdarII, Name: "Moshe"},
+		{HebrewDate: hdate.New(5784, hdate.Adar1, 30), Day30: hcfiles.Day30Last, Name: "Sarah"},
+	}
+
+	var lines strings.Builder
+	test.CheckErr(t, hcfiles.WriteYahrzeits(&lines, entries), "")
+	test.CheckString(t, "lines",
+		"10 8 1967 after-sunset Joe Shmo\n"+
+			"10 8 1967 time=21:05 Jane Doe\n"+
+			"Adar 7 5783 adar=2 Moshe\n"+
+			"Adar I 30 5784 day30=29 Sarah\n",
+		lines.String())
+	got, err := hcfiles.ParseYahrzeitEntries(strings.NewReader(lines.String()), "yahrzeits.txt")
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "lines round trip", entries, got)
+
+	var csv strings.Builder
+	test.CheckErr(t, hcfiles.WriteYahrzeitsCSV(&csv, entries), "")
+	test.CheckString(t, "csv",
+		"month,day,year,name,after_sunset,time,adar,day30\n"+
+			"10,8,1967,Joe Shmo,yes,,,\n"+
+			"10,8,1967,Jane Doe,,21:05,,\n"+
+			"Adar,7,5783,Moshe,,,2,\n"+
+			"Adar I,30,5784,Sarah,,,,29\n",
+		csv.String())
+	got, err = hcfiles.ParseYahrzeitsCSV(strings.NewReader(csv.String()), "yahrzeits.csv")
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "csv round trip", entries, got)
+}
@@ -3,24 +3,49 @@ package hcfiles
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type SyntaxError struct {
 	Err        error
 	FileName   string
 	LineNumber int
+
+	// IncludedFrom holds the `#include` directives
+	// which led to FileName, outermost first.
+	IncludedFrom []Include
 }
 
 var _ error = SyntaxError{}
 
 func (e SyntaxError) Error() string {
-	return fmt.Sprintf("error at %s:%d: %v", e.FileName, e.LineNumber, e.Err)
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "error at %s:%d", e.FileName, e.LineNumber)
+	for i := len(e.IncludedFrom) - 1; i >= 0; i-- {
+		fmt.Fprintf(&buf, ", included from %v", e.IncludedFrom[i])
+	}
+	fmt.Fprintf(&buf, ": %v", e.Err)
+	return buf.String()
 }
 
 func (e SyntaxError) Unwrap() error { return e.Err }
 
+// Include is the location of an `#include` directive.
+type Include struct {
+	FileName   string
+	LineNumber int
+}
+
+func (inc Include) String() string {
+	return fmt.Sprintf("%s:%d", inc.FileName, inc.LineNumber)
+}
+
 var (
-	ErrInvalidFormat = errors.New("invalid format")
-	ErrInvalidMonth  = errors.New("invalid month")
-	ErrInvalidDays   = errors.New("invalid days")
+	ErrInvalidFormat   = errors.New("invalid format")
+	ErrInvalidMonth    = errors.New("invalid month")
+	ErrInvalidDays     = errors.New("invalid days")
+	ErrInvalidOption   = errors.New("invalid option")
+	ErrInclude         = errors.New("failed to include file")
+	ErrIncludeCycle    = errors.New("include cycle")
+	ErrUnsupportedRule = errors.New("unsupported recurrence")
 )
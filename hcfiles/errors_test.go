@@ -25,3 +25,22 @@ func TestSyntaxError(t *testing.T) {
 		t.Errorf("expected message to be\n%q\nbut got\n%q", wantMsg, e.Error())
 	}
 }
+
+func TestSyntaxError_IncludedFrom(t *testing.T) {
+	e := hcfiles.SyntaxError{
+		Err:        errors.New("test error"),
+		FileName:   "family/more.txt",
+		LineNumber: 3,
+		IncludedFrom: []hcfiles.Include{
+			{FileName: "events.txt", LineNumber: 7},
+			{FileName: "family/events.txt", LineNumber: 2},
+		},
+	}
+
+	const wantMsg = "error at family/more.txt:3" +
+		", included from family/events.txt:2" +
+		", included from events.txt:7: test error"
+	if e.Error() != wantMsg {
+		t.Errorf("expected message to be\n%q\nbut got\n%q", wantMsg, e.Error())
+	}
+}
@@ -3,10 +3,13 @@ package hcfiles_test
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/event"
 	"github.com/hebcal/hebcal-go/hebcal"
 
 	"github.com/chaimleib/hebcalfmt/hcfiles"
@@ -51,6 +54,13 @@ func TestParseEvents(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:    "extended lines are left out",
+			Content: "every Tuesday Gemara shiur\nKislev 6 Jane Doe",
+			Want: []hebcal.UserEvent{
+				{Month: hdate.Kislev, Day: 6, Desc: "Jane Doe"},
+			},
+		},
 		{
 			Name:    "invalid line",
 			Content: "INVALID",
@@ -136,3 +146,281 @@ func TestParseEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEventRules(t *testing.T) {
+	const fileName = "testEvents.txt"
+	cases := []struct {
+		Name    string
+		Content string
+		WantErr string
+		Want    []hebcal.UserEvent
+		Rules   hcfiles.EventRules
+	}{
+		{Name: "empty", Content: "", WantErr: "", Want: nil},
+		{
+			Name:    "basic",
+			Content: "Cheshvan 03 Joe Shmo",
+			WantErr: "",
+			Want: []hebcal.UserEvent{
+				{
+					Month: hdate.Cheshvan,
+					Day:   3,
+					Desc:  "Joe Shmo",
+				},
+			},
+		},
+		{
+			Name:    "multiple entries",
+			Content: "Cheshvan 03 Joe Shmo\nKislev 6 Jane Doe",
+			WantErr: "",
+			Want: []hebcal.UserEvent{
+				{
+					Month: hdate.Cheshvan,
+					Day:   3,
+					Desc:  "Joe Shmo",
+				},
+				{
+					Month: hdate.Kislev,
+					Day:   6,
+					Desc:  "Jane Doe",
+				},
+			},
+		},
+		{
+			Name:    "comments and blank lines",
+			Content: "# Family\n\nCheshvan 03 Joe Shmo\n  # indented comment\n",
+			Want: []hebcal.UserEvent{
+				{Month: hdate.Cheshvan, Day: 3, Desc: "Joe Shmo"},
+			},
+		},
+		{
+			Name:    "invalid line",
+			Content: "INVALID",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: expected 4 capture fields, got 0",
+					hcfiles.ErrInvalidFormat,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+			Want: nil,
+		},
+		{
+			Name:    "invalid lines",
+			Content: "INVALID\nWRONG",
+			WantErr: "ParseEvents: " + errors.Join(
+				hcfiles.SyntaxError{
+					Err: fmt.Errorf(
+						"%w: expected 4 capture fields, got 0",
+						hcfiles.ErrInvalidFormat,
+					),
+					FileName:   fileName,
+					LineNumber: 1,
+				},
+				hcfiles.SyntaxError{
+					Err: fmt.Errorf(
+						"%w: expected 4 capture fields, got 0",
+						hcfiles.ErrInvalidFormat,
+					),
+					FileName:   fileName,
+					LineNumber: 2,
+				},
+			).Error(),
+			Want: nil,
+		},
+		{
+			Name:    "invalid month",
+			Content: "13 03 Joe Shmo",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err:        hcfiles.ErrInvalidMonth,
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+			Want: nil,
+		},
+		{
+			Name:    "invalid day",
+			Content: "Cheshvan 32 Joe Shmo",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err:        hcfiles.ErrInvalidDays,
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+			Want: nil,
+		},
+		{
+			Name:    "hebrew date with options",
+			Content: "Kislev 25 time=7:30pm days=8 flags=chag Festival of Lights",
+			Rules: hcfiles.EventRules{{
+				Schedule: hcfiles.HebrewAnnual{Month: hdate.Kislev, Day: 25},
+				Desc:     "Festival of Lights",
+				Days:     8,
+				Timed:    true,
+				Time:     19*time.Hour + 30*time.Minute,
+				Flags:    event.CHAG,
+			}},
+		},
+		{
+			Name: "extended dates",
+			Content: "2026-03-15 Dedication\n" +
+				"07-04 years=1776- Independence Day\n" +
+				"every Tuesday time=20:00 category=shiur Gemara shiur\n" +
+				"first Shabbat of Cheshvan Kiddush\n" +
+				"last day of each Hebrew month Yom Kippur Katan prep\n" +
+				"day 15 of each Gregorian month years=2025-2026 Dues\n" +
+				"second Monday of Adar II Purim committee\n" +
+				"Tishrei 2 Birthday - Ben Ploni",
+			Want: []hebcal.UserEvent{
+				{Month: hdate.Tishrei, Day: 2, Desc: "Birthday - Ben Ploni"},
+			},
+			Rules: hcfiles.EventRules{
+				{
+					Schedule: hcfiles.GregorianDate{Year: 2026, Month: time.March, Day: 15},
+					Desc:     "Dedication",
+					Days:     1,
+				},
+				{
+					Schedule: hcfiles.GregorianAnnual{Month: time.July, Day: 4},
+					Desc:     "Independence Day",
+					Days:     1,
+					FromYear: 1776,
+				},
+				{
+					Schedule:   hcfiles.Weekly{Weekday: time.Tuesday},
+					Desc:       "Gemara shiur",
+					Days:       1,
+					Timed:      true,
+					Time:       20 * time.Hour,
+					Categories: []string{"shiur"},
+				},
+				{
+					Schedule: hcfiles.Monthly{
+						Hebrew:      true,
+						HebrewMonth: hdate.Cheshvan,
+						Nth:         1,
+						ByWeekday:   true,
+						Weekday:     time.Saturday,
+					},
+					Desc: "Kiddush",
+					Days: 1,
+				},
+				{
+					Schedule: hcfiles.Monthly{Hebrew: true, Nth: hcfiles.Last},
+					Desc:     "Yom Kippur Katan prep",
+					Days:     1,
+				},
+				{
+					Schedule: hcfiles.Monthly{Nth: 15},
+					Desc:     "Dues",
+					Days:     1,
+					FromYear: 2025,
+					ToYear:   2026,
+				},
+				{
+					Schedule: hcfiles.Monthly{
+						Hebrew:      true,
+						HebrewMonth: hdate.Adar2,
+						Nth:         2,
+						ByWeekday:   true,
+						Weekday:     time.Monday,
+					},
+					Desc: "Purim committee",
+					Days: 1,
+				},
+			},
+		},
+		{
+			Name:    "invalid gregorian date",
+			Content: "02-30 Nonexistent",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: February has no day 30",
+					hcfiles.ErrInvalidDays,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid weekday",
+			Content: "every Funday Party",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: expected a weekday after \"every\", got \"Funday\"",
+					hcfiles.ErrInvalidFormat,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid option",
+			Content: "every Friday flags=NOPE Oneg",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: flags=NOPE: unknown flag \"NOPE\"",
+					hcfiles.ErrInvalidOption,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "backwards years",
+			Content: "every Friday years=2030-2020 Oneg",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: years=2030-2020: range ends before it starts",
+					hcfiles.ErrInvalidOption,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "missing description",
+			Content: "every Friday days=2",
+			WantErr: "ParseEvents: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: missing description",
+					hcfiles.ErrInvalidFormat,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			f := strings.NewReader(c.Content)
+			events, err := hcfiles.ParseEventRules(f, fileName)
+			test.CheckErr(t, err, c.WantErr)
+			if !reflect.DeepEqual(c.Rules, events.Rules) {
+				t.Errorf("rules did not match - want:\n%+v\ngot:\n%+v",
+					c.Rules, events.Rules)
+			}
+			got := events.UserEvents
+			var i, j int
+			for j = range c.Want {
+				if i >= len(got) {
+					t.Errorf(
+						"unexpected extra item at index %d, skipping rest:\n%v",
+						i,
+						got[i],
+					)
+					break
+				}
+				if c.Want[j] != got[i] {
+					t.Errorf("unexpected item at index %d:\n%v\nwant:\n%v",
+						i, got[i], c.Want[j])
+					// assume other lines will match, so allow line to increment
+				}
+				i++
+			}
+			if len(c.Want) != len(got) {
+				t.Errorf("expected %d results, got %d", len(c.Want), len(got))
+			}
+		})
+	}
+}
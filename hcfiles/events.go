@@ -1,20 +1,36 @@
 package hcfiles
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/hebcal/hdate"
 	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/warning"
 )
 
 var hebRe = regexp.MustCompile(`^(\S+)\s+(\d+)\s+(.+)$`)
 
+// Events are the entries of an events file.
+type Events struct {
+	// UserEvents are the entries which hebcal generates by itself.
+	UserEvents []hebcal.UserEvent
+
+	// Rules are the entries which need the extended syntax.
+	// See [HebrewCalendar].
+	Rules EventRules
+
+	// Warnings are the problems with entries which were skipped,
+	// like recurrences which [ParseEventsICS] cannot represent.
+	Warnings warning.Warnings
+}
+
 // ParseEvents parses an [io.Reader] of event lines
 // and returns a slice of [hebcal.UserEvent] entries.
 // In case of an error, fileName helps with debugging.
@@ -25,56 +41,149 @@ var hebRe = regexp.MustCompile(`^(\S+)\s+(\d+)\s+(.+)$`)
 //
 // MMMM is a string identifying the Hebrew month.
 // Description is a newline-terminated string.
+//
+// Lines in the extended syntax of [ParseEventRules] are checked,
+// but left out of the result.
 func ParseEvents(f io.Reader, fileName string) ([]hebcal.UserEvent, error) {
-	lineNumber := 0
-	var errs []error
-	lineErr := func(err error) {
-		errs = append(errs, SyntaxError{
-			Err:        err,
-			FileName:   fileName,
-			LineNumber: lineNumber,
-		})
+	events, err := ParseEventRules(f, fileName)
+	if err != nil {
+		return nil, err
 	}
+	return events.UserEvents, nil
+}
+
+// ParseEventRules parses an [io.Reader] of event lines
+// and returns the [Events] entries.
+// In case of an error, fileName helps with debugging.
+//
+// The classic lines are in the following format, using Hebrew dates:
+//
+//	MMMM DD Description
+//
+// MMMM is a string identifying the Hebrew month.
+// Description is a newline-terminated string.
+// These lines become [hebcal.UserEvent]s.
+//
+// Lines may also start with one of these dates,
+// which become [EventRule]s:
+//
+//	YYYY-MM-DD                    a single Gregorian date
+//	MM-DD                         a Gregorian date every year
+//	every WEEKDAY                 every week
+//	NTH WEEKDAY of MONTH          like "first Shabbat of Cheshvan"
+//	NTH day of MONTH              like "last day of each Hebrew month"
+//	day N of MONTH                like "day 15 of each Gregorian month"
+//
+// NTH is first through fifth, or last.
+// WEEKDAY is an English weekday name, or Shabbat.
+// MONTH is the name of a Hebrew or Gregorian month,
+// or "each Hebrew month" or "each Gregorian month".
+//
+// Between any date and the Description, these options may be given:
+//
+//	time=HH:MM            the time of day, like 19:30 or 7:30pm
+//	days=N                how many days the event lasts
+//	years=YYYY-YYYY       the years the event may start in; either end may be left open
+//	flags=NAME,...        extra flags, named like the `$.event` constants
+//	category=NAME,...     categories of the event
+//
+// The years are Hebrew years for Hebrew dates, and Gregorian years otherwise.
+// Hebrew dates with options also become EventRules.
+//
+// Blank lines and lines starting with # are skipped.
+// ParseEventRules fails on `#include` lines; see [EventsParser].
+func ParseEventRules(f io.Reader, fileName string) (Events, error) {
+	return EventsParser(nil)(f, fileName)
+}
 
-	entries := make([]hebcal.UserEvent, 0, 10)
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line0 := scanner.Text()
-		line := strings.TrimSpace(line0)
-		lineNumber++
-
-		fields := hebRe.FindStringSubmatch(line)
-		if len(fields) != 4 {
-			lineErr(
-				fmt.Errorf(
-					"%w: expected 4 capture fields, got %d",
-					ErrInvalidFormat,
-					len(fields),
-				),
-			)
-			continue
+// EventsParser returns a function like [ParseEventRules]
+// which also follows lines like
+//
+//	#include other.txt
+//
+// by reading the named file from files,
+// relative to the directory of the including file.
+// The lines of the included file are parsed as if they were in its place.
+func EventsParser(files fs.FS) func(io.Reader, string) (Events, error) {
+	return func(f io.Reader, fileName string) (Events, error) {
+		var result Events
+		s := lineScanner{
+			files:     files,
+			parseLine: func(line string) error { return parseEventLine(&result, line) },
 		}
+		s.scan(f, fileName, nil)
 
-		month, err := hdate.MonthFromName(fields[1])
-		if err != nil {
-			lineErr(ErrInvalidMonth)
-			continue
+		if len(s.errs) != 0 {
+			return Events{}, fmt.Errorf("ParseEvents: %w", errors.Join(s.errs...))
 		}
+		return result, nil
+	}
+}
 
-		day, _ := strconv.Atoi(fields[2])
-		if day < 1 || day > 30 {
-			lineErr(ErrInvalidDays)
-			continue
+// parseEventLine parses a line of an events file into result.
+func parseEventLine(result *Events, line string) error {
+	if word, _ := nextField(line); isExtendedDate(word) {
+		schedule, rest, err := parseSchedule(line)
+		if err != nil {
+			return err
+		}
+		rule := EventRule{Schedule: schedule, Days: 1}
+		if _, err := parseOptions(&rule, rest); err != nil {
+			return err
 		}
+		result.Rules = append(result.Rules, rule)
+		return nil
+	}
 
-		entries = append(
-			entries,
-			hebcal.UserEvent{Month: month, Day: day, Desc: fields[3]},
+	fields := hebRe.FindStringSubmatch(line)
+	if len(fields) != 4 {
+		return fmt.Errorf(
+			"%w: expected 4 capture fields, got %d",
+			ErrInvalidFormat,
+			len(fields),
 		)
 	}
 
-	if len(errs) != 0 {
-		return nil, fmt.Errorf("ParseEvents: %w", errors.Join(errs...))
+	month, err := hdate.MonthFromName(fields[1])
+	if err != nil {
+		return ErrInvalidMonth
+	}
+
+	day, _ := strconv.Atoi(fields[2])
+	if day < 1 || day > 30 {
+		return ErrInvalidDays
+	}
+
+	rule := EventRule{Schedule: HebrewAnnual{Month: month, Day: day}, Days: 1}
+	hasOptions, err := parseOptions(&rule, fields[3])
+	if err != nil {
+		return err
+	}
+	if hasOptions {
+		result.Rules = append(result.Rules, rule)
+		return nil
+	}
+
+	result.UserEvents = append(
+		result.UserEvents,
+		hebcal.UserEvent{Month: month, Day: day, Desc: fields[3]},
+	)
+	return nil
+}
+
+// WriteEvents writes the events as lines
+// which [ParseEvents] understands.
+func WriteEvents(w io.Writer, events []hebcal.UserEvent) error {
+	for _, ev := range events {
+		// Month names are written without spaces, like Adar1,
+		// since they must be a single field.
+		month := strings.ReplaceAll(ev.Month.String(), " ", "")
+		if ev.Month == hdate.Adar1 || ev.Month == hdate.Adar2 {
+			month = fmt.Sprintf("Adar%d", ev.Month-hdate.Adar1+1)
+		}
+		if _, err := fmt.Fprintf(w, "%s %d %s\n", month, ev.Day, ev.Desc); err != nil {
+			return err
+		}
 	}
-	return entries, nil
+	return nil
 }
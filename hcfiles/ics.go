@@ -0,0 +1,339 @@
+package hcfiles
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/ical"
+)
+
+// ParseEventsICS parses an [io.Reader] of iCalendar data,
+// like an .ics export of another calendar app,
+// and returns its VEVENTs as [Events] entries.
+// In case of an error, fileName helps with debugging.
+//
+// Each VEVENT becomes an [EventRule] on the Gregorian calendar:
+//
+//   - The SUMMARY is the description, and CATEGORIES are its categories.
+//   - DTSTART gives the date. If it has a time of day in UTC,
+//     like 20250705T023000Z, or in the zone named by its TZID parameter,
+//     the date and time are converted to UTC; see [ICSParser].
+//     Other times of day are kept as written.
+//   - DTEND or DURATION gives the number of days of all-day events.
+//   - Without an RRULE, the event happens once, like a YYYY-MM-DD line.
+//   - An RRULE with FREQ=YEARLY repeats the event every year,
+//     like an MM-DD line, limited by any COUNT or UNTIL.
+//
+// Events with other recurrences, like FREQ=WEEKLY,
+// are skipped with a warning in the Warnings of the result.
+// Cancelled events, other components like VTODO,
+// and other properties are skipped.
+func ParseEventsICS(f io.Reader, fileName string) (Events, error) {
+	return ICSParser(time.UTC)(f, fileName)
+}
+
+// ICSParser returns a function like [ParseEventsICS]
+// which converts the times of day of events into tz,
+// like the time zone of the location of the calendar.
+func ICSParser(tz *time.Location) func(io.Reader, string) (Events, error) {
+	return func(f io.Reader, fileName string) (Events, error) {
+		return parseEventsICS(f, fileName, tz)
+	}
+}
+
+func parseEventsICS(f io.Reader, fileName string, tz *time.Location) (Events, error) {
+	props, err := ical.ReadProps(f)
+	if err != nil {
+		return Events{}, fmt.Errorf("ParseEventsICS: %s: %w", fileName, err)
+	}
+
+	var (
+		result Events
+		errs   []error
+		event  []ical.Prop // the properties of the current VEVENT
+		start  int         // the line of its BEGIN:VEVENT
+		inside []string    // the components which are open
+	)
+	for _, prop := range props {
+		switch prop.Name {
+		case "BEGIN":
+			inside = append(inside, strings.ToUpper(prop.Value))
+			if inside[len(inside)-1] == "VEVENT" {
+				event, start = nil, prop.Line
+			}
+			continue
+
+		case "END":
+			if len(inside) == 0 {
+				continue
+			}
+			component := inside[len(inside)-1]
+			inside = inside[:len(inside)-1]
+			if component != "VEVENT" {
+				continue
+			}
+			rule, ok, err := parseVEvent(event, tz)
+			if errors.Is(err, ErrUnsupportedRule) {
+				result.Warnings.Append(fmt.Errorf(
+					"%s:%d: skipped VEVENT: %w", fileName, start, err))
+			} else if err != nil {
+				errs = append(errs, SyntaxError{
+					Err:        err,
+					FileName:   fileName,
+					LineNumber: start,
+				})
+			} else if ok {
+				result.Rules = append(result.Rules, rule)
+			}
+			continue
+		}
+
+		// Skip the properties of nested components, like VALARM.
+		if len(inside) != 0 && inside[len(inside)-1] == "VEVENT" {
+			event = append(event, prop)
+		}
+	}
+
+	if len(errs) != 0 {
+		return Events{}, fmt.Errorf("ParseEventsICS: %w", errors.Join(errs...))
+	}
+	return result, nil
+}
+
+// EventsFileParser returns the parser for the events file at fpath,
+// chosen by its extension: [ICSParser] for .ics files,
+// converting times into tz, or else [EventsParser].
+func EventsFileParser(
+	files fs.FS,
+	fpath string,
+	tz *time.Location,
+) func(io.Reader, string) (Events, error) {
+	if strings.EqualFold(path.Ext(fpath), ".ics") {
+		return ICSParser(tz)
+	}
+	return EventsParser(files)
+}
+
+// parseVEvent converts the properties of a VEVENT into an [EventRule],
+// with its times of day in tz.
+// It returns false if the event is cancelled.
+// An error wrapping [ErrUnsupportedRule] is returned
+// if its recurrence cannot be represented.
+func parseVEvent(props []ical.Prop, tz *time.Location) (EventRule, bool, error) {
+	get := func(name string) (ical.Prop, bool) {
+		for _, prop := range props {
+			if prop.Name == name {
+				return prop, true
+			}
+		}
+		return ical.Prop{}, false
+	}
+
+	if status, _ := get("STATUS"); strings.EqualFold(status.Value, "CANCELLED") {
+		return EventRule{}, false, nil
+	}
+
+	r := EventRule{Days: 1}
+	summary, _ := get("SUMMARY")
+	r.Desc = strings.TrimSpace(ical.UnescapeText(summary.Value))
+	if r.Desc == "" {
+		return EventRule{}, false, fmt.Errorf("%w: missing SUMMARY", ErrInvalidFormat)
+	}
+
+	dtstart, ok := get("DTSTART")
+	if !ok {
+		return EventRule{}, false, fmt.Errorf("%w: missing DTSTART", ErrInvalidFormat)
+	}
+	startDate, timed, err := parseICSDate(dtstart.Value, dtstart.Params["TZID"], tz)
+	if err != nil {
+		return EventRule{}, false, fmt.Errorf("%w: DTSTART: %w", ErrInvalidFormat, err)
+	}
+	if timed {
+		// Take the clock time, which differs from the time since midnight
+		// on days when daylight saving time changes.
+		r.Timed = true
+		r.Time = time.Duration(startDate.Hour())*time.Hour +
+			time.Duration(startDate.Minute())*time.Minute +
+			time.Duration(startDate.Second())*time.Second
+	} else if days, err := icsAllDayLength(startDate, get); err != nil {
+		return EventRule{}, false, err
+	} else if days > 0 {
+		r.Days = days
+	}
+
+	for _, prop := range props {
+		if prop.Name != "CATEGORIES" {
+			continue
+		}
+		for name := range strings.SplitSeq(prop.Value, ",") {
+			if name = strings.TrimSpace(ical.UnescapeText(name)); name != "" {
+				r.Categories = append(r.Categories, name)
+			}
+		}
+	}
+
+	rrule, ok := get("RRULE")
+	if !ok {
+		r.Schedule = GregorianDate{
+			Year:  startDate.Year(),
+			Month: startDate.Month(),
+			Day:   startDate.Day(),
+		}
+		return r, true, nil
+	}
+	if err := parseYearlyRRule(&r, startDate, rrule.Value, tz); errors.Is(err, ErrUnsupportedRule) {
+		return EventRule{}, false, fmt.Errorf("RRULE: %w", err)
+	} else if err != nil {
+		return EventRule{}, false, fmt.Errorf("%w: RRULE: %w", ErrInvalidFormat, err)
+	}
+	return r, true, nil
+}
+
+// icsAllDayLength returns the number of days of an all-day event
+// starting on start, from its DTEND or DURATION,
+// or 0 if it has neither.
+func icsAllDayLength(start time.Time, get func(string) (ical.Prop, bool)) (int, error) {
+	days := 0
+	if dtend, ok := get("DTEND"); ok {
+		end, _, err := parseICSDate(dtend.Value, "", start.Location())
+		if err != nil {
+			return 0, fmt.Errorf("%w: DTEND: %w", ErrInvalidFormat, err)
+		}
+		// DTEND is the day after the event.
+		days = int(end.Sub(start).Hours()+12) / 24
+	} else if duration, ok := get("DURATION"); ok {
+		var err error
+		if days, err = parseICSDays(duration.Value); err != nil {
+			return 0, fmt.Errorf("%w: DURATION: %w", ErrInvalidFormat, err)
+		}
+	}
+	if days > 366 {
+		return 0, fmt.Errorf("%w: lasts %d days, expected 1 to 366", ErrInvalidDays, days)
+	}
+	return days, nil
+}
+
+// parseICSDate parses a DATE, like 20250704,
+// or a DATE-TIME, like 20250704T193000 or 20250704T233000Z,
+// into tz.
+// A DATE-TIME in UTC, ending with Z, or in the zone named by tzid,
+// is converted into tz, which may change its date.
+// Other DATE-TIMEs, and DATEs, are taken to be in tz as written.
+// It reports whether there was a time of day.
+func parseICSDate(s, tzid string, tz *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("20060102", s, tz); err == nil {
+		return t, false, nil
+	}
+
+	from := tz
+	if tzid != "" {
+		var err error
+		if from, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID %q", tzid)
+		}
+	}
+	value := s
+	if v, ok := strings.CutSuffix(s, "Z"); ok {
+		value, from = v, time.UTC
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, from)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q", s)
+	}
+	return t.In(tz), true, nil
+}
+
+// parseICSDays parses a DURATION of whole days or weeks,
+// like P3D or P1W.
+func parseICSDays(s string) (int, error) {
+	unit := 1
+	switch {
+	case strings.HasSuffix(s, "D"):
+	case strings.HasSuffix(s, "W"):
+		unit = 7
+	default:
+		return 0, fmt.Errorf("expected whole days or weeks, got %q", s)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(s[:len(s)-1], "P"))
+	if err != nil || !strings.HasPrefix(s, "P") || n < 1 {
+		return 0, fmt.Errorf("expected whole days or weeks, got %q", s)
+	}
+	return n * unit, nil
+}
+
+// parseYearlyRRule sets r to repeat every year from start,
+// as described by the RRULE value, with an UNTIL in tz.
+// Recurrences other than every year wrap [ErrUnsupportedRule].
+func parseYearlyRRule(r *EventRule, start time.Time, value string, tz *time.Location) error {
+	r.Schedule = GregorianAnnual{Month: start.Month(), Day: start.Day()}
+	r.FromYear = start.Year()
+
+	parts := strings.Split(value, ";")
+	freq := ""
+	for _, part := range parts {
+		if key, val, _ := strings.Cut(part, "="); strings.EqualFold(key, "FREQ") {
+			freq = strings.ToUpper(val)
+		}
+	}
+	if freq != "YEARLY" {
+		return fmt.Errorf("%w: FREQ=%s; only yearly events are supported", ErrUnsupportedRule, freq)
+	}
+
+	for _, part := range parts {
+		key, val, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+
+		case "INTERVAL":
+			if val != "1" {
+				return fmt.Errorf("%w: INTERVAL=%s; only every year is supported", ErrUnsupportedRule, val)
+			}
+
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return fmt.Errorf("COUNT=%s: expected a positive number", val)
+			}
+			r.ToYear = start.Year() + count - 1
+
+		case "UNTIL":
+			until, _, err := parseICSDate(val, "", tz)
+			if err != nil {
+				return fmt.Errorf("UNTIL=%s: %w", val, err)
+			}
+			r.ToYear = until.Year()
+			if until.Month() < start.Month() ||
+				until.Month() == start.Month() && until.Day() < start.Day() {
+				r.ToYear--
+			}
+			if r.ToYear < r.FromYear {
+				return fmt.Errorf("UNTIL=%s: ends before DTSTART", val)
+			}
+
+		case "BYMONTH":
+			if val != strconv.Itoa(int(start.Month())) {
+				return fmt.Errorf("%w: BYMONTH=%s; only the month of DTSTART is supported",
+					ErrUnsupportedRule, val)
+			}
+
+		case "BYMONTHDAY":
+			if val != strconv.Itoa(start.Day()) {
+				return fmt.Errorf("%w: BYMONTHDAY=%s; only the day of DTSTART is supported",
+					ErrUnsupportedRule, val)
+			}
+
+		case "WKST":
+			// Only matters for weekly recurrences.
+
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedRule, part)
+		}
+	}
+	return nil
+}
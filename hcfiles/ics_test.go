@@ -0,0 +1,308 @@
+package hcfiles_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+// icsCalendar wraps the lines in a VCALENDAR.
+func icsCalendar(lines ...string) string {
+	return strings.Join(append(append(
+		[]string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Example//EN"},
+		lines...), "END:VCALENDAR", ""), "\r\n")
+}
+
+func TestParseEventsICS(t *testing.T) {
+	const fileName = "family.ics"
+	cases := []struct {
+		Name    string
+		Content string
+		WantErr string
+		Want    hcfiles.EventRules
+		Warns   string
+	}{
+		{Name: "empty", Content: icsCalendar()},
+		{
+			Name: "yearly birthday",
+			Content: icsCalendar(
+				"BEGIN:VEVENT",
+				"UID:1@example.com",
+				"DTSTART;VALUE=DATE:19900315",
+				"DTEND;VALUE=DATE:19900316",
+				"RRULE:FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=15",
+				`SUMMARY:Reuven's birthday\, with cake`,
+				"CATEGORIES:Family,Birthdays",
+				"BEGIN:VALARM",
+				"ACTION:DISPLAY",
+				"DESCRIPTION:Reminder",
+				"TRIGGER:-P1D",
+				"END:VALARM",
+				"END:VEVENT",
+			),
+			Want: hcfiles.EventRules{{
+				Schedule:   hcfiles.GregorianAnnual{Month: time.March, Day: 15},
+				Desc:       "Reuven's birthday, with cake",
+				FromYear:   1990,
+				Days:       1,
+				Categories: []string{"Family", "Birthdays"},
+			}},
+		},
+		{
+			Name: "count, until and single dates",
+			Content: icsCalendar(
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20250704",
+				"RRULE:FREQ=YEARLY;COUNT=3",
+				"SUMMARY:Picnic",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20200901",
+				"RRULE:FREQ=YEARLY;INTERVAL=1;UNTIL=20240801",
+				"SUMMARY:School starts",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20251224",
+				"DURATION:P1W",
+				"SUMMARY:Vacation",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART:20251102T193000",
+				"DTEND:20251102T210000",
+				"SUMMARY:Shul dinner",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20251103",
+				"STATUS:CANCELLED",
+				"SUMMARY:Cancelled",
+				"END:VEVENT",
+			),
+			Want: hcfiles.EventRules{
+				{
+					Schedule: hcfiles.GregorianAnnual{Month: time.July, Day: 4},
+					Desc:     "Picnic",
+					FromYear: 2025,
+					ToYear:   2027,
+					Days:     1,
+				},
+				{
+					Schedule: hcfiles.GregorianAnnual{Month: time.September, Day: 1},
+					Desc:     "School starts",
+					FromYear: 2020,
+					ToYear:   2023,
+					Days:     1,
+				},
+				{
+					Schedule: hcfiles.GregorianDate{Year: 2025, Month: time.December, Day: 24},
+					Desc:     "Vacation",
+					Days:     7,
+				},
+				{
+					Schedule: hcfiles.GregorianDate{Year: 2025, Month: time.November, Day: 2},
+					Desc:     "Shul dinner",
+					Days:     1,
+					Timed:    true,
+					Time:     19*time.Hour + 30*time.Minute,
+				},
+			},
+		},
+		{
+			Name: "unsupported recurrences are skipped",
+			Content: icsCalendar(
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20250704",
+				"RRULE:FREQ=WEEKLY;BYDAY=TU",
+				"SUMMARY:Shiur",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20250704",
+				"RRULE:FREQ=MONTHLY",
+				"SUMMARY:Rent",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20251127",
+				"RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+				"SUMMARY:Thanksgiving",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:19900315",
+				"RRULE:FREQ=YEARLY",
+				"SUMMARY:Reuven's birthday",
+				"END:VEVENT",
+			),
+			Want: hcfiles.EventRules{{
+				Schedule: hcfiles.GregorianAnnual{Month: time.March, Day: 15},
+				Desc:     "Reuven's birthday",
+				FromYear: 1990,
+				Days:     1,
+			}},
+			Warns: "3 warnings:\n" +
+				"family.ics:4: skipped VEVENT: RRULE: unsupported recurrence: " +
+				"FREQ=WEEKLY; only yearly events are supported\n" +
+				"family.ics:9: skipped VEVENT: RRULE: unsupported recurrence: " +
+				"FREQ=MONTHLY; only yearly events are supported\n" +
+				"family.ics:14: skipped VEVENT: RRULE: unsupported recurrence: BYDAY=4TH",
+		},
+		{
+			Name: "invalid events",
+			Content: icsCalendar(
+				"BEGIN:VEVENT",
+				"DTSTART;TZID=Eastern Standard Time:20250704T120000",
+				"SUMMARY:Shiur",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART;VALUE=DATE:20250704",
+				"END:VEVENT",
+				"BEGIN:VEVENT",
+				"DTSTART:July 4th",
+				"SUMMARY:Picnic",
+				"END:VEVENT",
+			),
+			WantErr: fmt.Sprintf("ParseEventsICS: %v", errors.Join(
+				hcfiles.SyntaxError{
+					Err: fmt.Errorf("%w: DTSTART: %w", hcfiles.ErrInvalidFormat,
+						errors.New(`unknown TZID "Eastern Standard Time"`)),
+					FileName:   fileName,
+					LineNumber: 4,
+				},
+				hcfiles.SyntaxError{
+					Err:        fmt.Errorf("%w: missing SUMMARY", hcfiles.ErrInvalidFormat),
+					FileName:   fileName,
+					LineNumber: 8,
+				},
+				hcfiles.SyntaxError{
+					Err: fmt.Errorf("%w: DTSTART: %w", hcfiles.ErrInvalidFormat,
+						errors.New(`invalid date "July 4th"`)),
+					FileName:   fileName,
+					LineNumber: 11,
+				},
+			)),
+		},
+		{
+			Name:    "invalid content line",
+			Content: "BEGIN:VCALENDAR\nnonsense\n",
+			WantErr: `ParseEventsICS: family.ics: line 2: invalid content line "nonsense"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			events, err := hcfiles.ParseEventsICS(strings.NewReader(c.Content), fileName)
+			test.CheckErr(t, err, c.WantErr)
+			if !reflect.DeepEqual(c.Want, events.Rules) {
+				t.Errorf("rules did not match - want:\n%+v\ngot:\n%+v",
+					c.Want, events.Rules)
+			}
+			test.CheckSlice(t, "UserEvents", nil, events.UserEvents)
+			test.CheckErr(t, events.Warnings.Build(), c.Warns)
+		})
+	}
+}
+
+func TestICSParser(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		Name    string
+		DTStart string
+		Want    hcfiles.GregorianDate
+		Time    time.Duration
+	}{
+		{
+			Name:    "UTC on the next day",
+			DTStart: "DTSTART:20250705T023000Z",
+			Want:    hcfiles.GregorianDate{Year: 2025, Month: time.July, Day: 4},
+			Time:    22*time.Hour + 30*time.Minute,
+		},
+		{
+			Name:    "TZID",
+			DTStart: "DTSTART;TZID=Asia/Jerusalem:20250705T010000",
+			Want:    hcfiles.GregorianDate{Year: 2025, Month: time.July, Day: 4},
+			Time:    18 * time.Hour,
+		},
+		{
+			Name:    "floating",
+			DTStart: "DTSTART:20250705T023000",
+			Want:    hcfiles.GregorianDate{Year: 2025, Month: time.July, Day: 5},
+			Time:    2*time.Hour + 30*time.Minute,
+		},
+		{
+			// Clocks fell back from 2:00 to 1:00 that morning.
+			Name:    "clock time on a DST change",
+			DTStart: "DTSTART:20251102T063000Z",
+			Want:    hcfiles.GregorianDate{Year: 2025, Month: time.November, Day: 2},
+			Time:    1*time.Hour + 30*time.Minute,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			content := icsCalendar("BEGIN:VEVENT", c.DTStart, "SUMMARY:Call", "END:VEVENT")
+			events, err := hcfiles.ICSParser(newYork)(strings.NewReader(content), "call.ics")
+			test.CheckErr(t, err, "")
+			want := hcfiles.EventRules{{
+				Schedule: c.Want,
+				Desc:     "Call",
+				Days:     1,
+				Timed:    true,
+				Time:     c.Time,
+			}}
+			if !reflect.DeepEqual(want, events.Rules) {
+				t.Errorf("rules did not match - want:\n%+v\ngot:\n%+v", want, events.Rules)
+			}
+		})
+	}
+}
+
+func TestEventsFileParser(t *testing.T) {
+	files := fstest.MapFS{
+		"family.ics": {Data: []byte(icsCalendar(
+			"BEGIN:VEVENT",
+			"DTSTART;VALUE=DATE:19900315",
+			"RRULE:FREQ=YEARLY",
+			"SUMMARY:Reuven's birthday",
+			"END:VEVENT",
+		))},
+		"family.txt": {Data: []byte("Nisan 19 Reuven's Hebrew birthday\n")},
+	}
+	opts := hebcal.CalOptions{
+		Start:      hdate.FromTime(date(2026, time.March, 1)),
+		End:        hdate.FromTime(date(2026, time.April, 30)),
+		NoHolidays: true,
+	}
+
+	var got []string
+	for _, fpath := range []string{"family.ics", "family.txt"} {
+		f, err := files.Open(fpath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events, err := hcfiles.EventsFileParser(files, fpath, time.UTC)(f, fpath)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts.UserEvents = append(opts.UserEvents, events.UserEvents...)
+		calEvents, err := hcfiles.HebrewCalendar(&opts, events.Rules)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ev := range calEvents {
+			got = append(got, ev.GetDate().Gregorian().Format(time.DateOnly)+" "+ev.Render("en"))
+		}
+	}
+	test.CheckSlice(t, "events", []string{
+		"2026-03-15 Reuven's birthday",
+		"2026-04-06 Reuven's Hebrew birthday",
+	}, got)
+}
@@ -0,0 +1,104 @@
+package hcfiles
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// includePrefix starts a line which includes another file.
+const includePrefix = "#include"
+
+// lineScanner reads the lines of events and yahrzeit files,
+// skipping blank lines and comments, and following `#include` directives.
+type lineScanner struct {
+	// files resolves `#include` directives.
+	// If it is nil, they are errors.
+	files fs.FS
+
+	// parseLine parses a line which is not blank or a comment.
+	parseLine func(line string) error
+
+	// errs collects a [SyntaxError] for each bad line.
+	errs []error
+}
+
+// scan parses the lines of f, which is named fileName.
+// chain holds the include directives which led to f, outermost first.
+func (s *lineScanner) scan(f io.Reader, fileName string, chain []Include) {
+	lineNumber := 0
+	lineErr := func(err error) {
+		s.errs = append(s.errs, SyntaxError{
+			Err:          err,
+			FileName:     fileName,
+			LineNumber:   lineNumber,
+			IncludedFrom: chain,
+		})
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		lineNumber++
+
+		if target, ok := includeTarget(line); ok {
+			here := Include{FileName: fileName, LineNumber: lineNumber}
+			if err := s.include(target, append(chain[:len(chain):len(chain)], here)); err != nil {
+				lineErr(err)
+			}
+			continue
+		}
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if err := s.parseLine(line); err != nil {
+			lineErr(err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		lineErr(err)
+	}
+}
+
+// include parses the file named by target,
+// relative to the directory of the last file in chain.
+func (s *lineScanner) include(target string, chain []Include) error {
+	if target == "" {
+		return fmt.Errorf("%w: missing file name", ErrInclude)
+	}
+	if s.files == nil {
+		return fmt.Errorf("%w: %s: no file system to read it from",
+			ErrInclude, target)
+	}
+
+	includer := chain[len(chain)-1].FileName
+	fpath := path.Join(path.Dir(includer), target)
+	for _, inc := range chain {
+		if path.Clean(inc.FileName) == fpath {
+			return fmt.Errorf("%w: %s", ErrIncludeCycle, fpath)
+		}
+	}
+
+	f, err := s.files.Open(fpath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInclude, err)
+	}
+	defer f.Close()
+	s.scan(f, fpath, chain)
+	return nil
+}
+
+// includeTarget returns the file named by an `#include` line,
+// and whether line is one.
+func includeTarget(line string) (string, bool) {
+	rest, ok := strings.CutPrefix(line, includePrefix)
+	if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+		return "", false
+	}
+	return strings.Trim(strings.TrimSpace(rest), `"`), true
+}
@@ -0,0 +1,124 @@
+package hcfiles_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/fsys"
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestEventsParser(t *testing.T) {
+	file := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fsys.WrapFS{
+		BaseDir: "config",
+		FS: fstest.MapFS{
+			"config/events.txt": file(
+				"# Shul events\n\nTishrei 2 Birthday\n#include family/events.txt\n"),
+			"config/family/events.txt": file(
+				"  # Family\nKislev 6 Anniversary\n#include more.txt\n"),
+			"config/family/more.txt":  file("Nisan 14 Party\n"),
+			"config/bad.txt":          file("#include family/bad.txt\n"),
+			"config/family/bad.txt":   file("\nTishrei 40 Typo\n"),
+			"config/cycle.txt":        file("Tishrei 2 Birthday\n#include family/cycle.txt\n"),
+			"config/family/cycle.txt": file("#include ../cycle.txt\n"),
+		},
+	}
+
+	cases := []struct {
+		Name     string
+		FileName string
+		Want     []hebcal.UserEvent
+		Err      string
+	}{
+		{
+			Name:     "nested includes",
+			FileName: "events.txt",
+			Want: []hebcal.UserEvent{
+				{Month: hdate.Tishrei, Day: 2, Desc: "Birthday"},
+				{Month: hdate.Kislev, Day: 6, Desc: "Anniversary"},
+				{Month: hdate.Nisan, Day: 14, Desc: "Party"},
+			},
+		},
+		{
+			Name:     "error in included file",
+			FileName: "bad.txt",
+			Err: "ParseEvents: error at family/bad.txt:2, included from bad.txt:1: " +
+				hcfiles.ErrInvalidDays.Error(),
+		},
+		{
+			Name:     "cycle",
+			FileName: "cycle.txt",
+			Err: "ParseEvents: error at family/cycle.txt:1, included from cycle.txt:2: " +
+				"include cycle: cycle.txt",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			f, err := files.Open(c.FileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			got, err := hcfiles.EventsParser(files)(f, c.FileName)
+			test.CheckErr(t, err, c.Err)
+			test.CheckSlice(t, "UserEvents", c.Want, got.UserEvents)
+		})
+	}
+}
+
+func TestEventsParser_MissingInclude(t *testing.T) {
+	files := fstest.MapFS{}
+	content := "#include missing.txt"
+	_, err := hcfiles.EventsParser(files)(strings.NewReader(content), "events.txt")
+	if !errors.Is(err, hcfiles.ErrInclude) {
+		t.Errorf("expected ErrInclude, got %v", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestParseEvents_Include(t *testing.T) {
+	const fileName = "testEvents.txt"
+	_, err := hcfiles.ParseEvents(strings.NewReader("#include other.txt"), fileName)
+	test.CheckErr(t, err, "ParseEvents: "+hcfiles.SyntaxError{
+		Err: fmt.Errorf("%w: other.txt: no file system to read it from",
+			hcfiles.ErrInclude),
+		FileName:   fileName,
+		LineNumber: 1,
+	}.Error())
+}
+
+func TestYahrzeitsParser(t *testing.T) {
+	files := fstest.MapFS{
+		"yahrzeits.txt": &fstest.MapFile{Data: []byte(
+			"# Family\n\n10 8 1967 Joe Shmo\n#include more.txt\n",
+		)},
+		"more.txt": &fstest.MapFile{Data: []byte("02 03 2004 Jane Doe\n")},
+	}
+	f, err := files.Open("yahrzeits.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := hcfiles.YahrzeitsParser(files)(f, "yahrzeits.txt")
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "yahrzeits", []hcfiles.Yahrzeit{
+		{Date: date(1967, time.October, 8), Name: "Joe Shmo"},
+		{Date: date(2004, time.February, 3), Name: "Jane Doe"},
+	}, got)
+}
@@ -0,0 +1,244 @@
+package hcfiles
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hebcal/greg"
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/event"
+	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/zmanim"
+)
+
+// FileEvent is a day of an event generated from an [EventRule].
+// Events with a time of day are wrapped in a [hebcal.TimedEvent]
+// with the FileEvent as its LinkedEvent.
+type FileEvent struct {
+	Date       hdate.HDate
+	Desc       string
+	Flags      event.HolidayFlags
+	Categories []string
+
+	// Source is the events file which the event came from, if known.
+	Source string
+
+	// Day counts the days of a multi-day event from 1, up to Days.
+	Day, Days int
+}
+
+var _ event.CalEvent = FileEvent{}
+
+func (ev FileEvent) GetDate() hdate.HDate         { return ev.Date }
+func (ev FileEvent) GetFlags() event.HolidayFlags { return ev.Flags }
+func (ev FileEvent) GetEmoji() string             { return "" }
+func (ev FileEvent) Basename() string             { return ev.Desc }
+func (ev FileEvent) Render(locale string) string {
+	if ev.Days > 1 {
+		return fmt.Sprintf("%s (day %d of %d)", ev.Desc, ev.Day, ev.Days)
+	}
+	return ev.Desc
+}
+
+// CalendarRange returns the first and last days
+// that [hebcal.HebrewCalendar] would generate events for with opts.
+func CalendarRange(opts *hebcal.CalOptions) (start, end hdate.HDate, err error) {
+	zero := hdate.HDate{}
+	switch {
+	case opts.Start != zero && opts.End != zero:
+		return opts.Start, opts.End, nil
+	case opts.Start != zero || opts.End != zero:
+		return zero, zero, errors.New("opts.Start requires opts.End")
+	}
+
+	year := opts.Year
+	if year == 0 {
+		gy, gm, gd := time.Now().Date()
+		year = gy
+		if opts.IsHebrewYear {
+			year = hdate.FromGregorian(gy, gm, gd).Year()
+		}
+	} else if opts.IsHebrewYear && year < 1 {
+		return zero, zero, errors.New("invalid Hebrew year")
+	}
+	numYears := max(opts.NumYears, 1)
+
+	if opts.IsHebrewYear {
+		// Like hebcal, start on Erev Rosh Hashana.
+		startAbs := hdate.ToRD(year, hdate.Tishrei, 1)
+		if year > 1 {
+			startAbs--
+		}
+		endAbs := hdate.ToRD(year+numYears, hdate.Tishrei, 1) - 1
+		return hdate.FromRD(startAbs), hdate.FromRD(endAbs), nil
+	}
+
+	toRD := greg.ToRD
+	if opts.NoJulian {
+		toRD = greg.ProlepticToRD
+	}
+	if opts.Month != 0 {
+		startAbs := toRD(year, opts.Month, 1)
+		endAbs := startAbs + int64(greg.DaysIn(opts.Month, year)) - 1
+		return hdate.FromRD(startAbs), hdate.FromRD(endAbs), nil
+	}
+	startAbs := toRD(year, time.January, 1)
+	endAbs := toRD(year+numYears, time.January, 1) - 1
+	return hdate.FromRD(startAbs), hdate.FromRD(endAbs), nil
+}
+
+// Occurrences returns the events of the rules
+// in the date range selected by opts, sorted by date.
+// Days of multi-day events which started before the range are included.
+// Events on the same date are in the order of their rules.
+func (rules EventRules) Occurrences(
+	opts *hebcal.CalOptions,
+) ([]event.CalEvent, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	start, end, err := CalendarRange(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	tz, err := TimeZone(opts.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDays := 1
+	for _, r := range rules {
+		maxDays = max(maxDays, r.Days)
+	}
+
+	type occurrence struct {
+		abs  int64
+		rule int
+		day  int
+	}
+	var found []occurrence
+	startAbs, endAbs := start.Abs(), end.Abs()
+	for abs := startAbs - int64(maxDays-1); abs <= endAbs; abs++ {
+		d := newCalDay(hdate.FromRD(abs), opts.NoJulian)
+		for i, r := range rules {
+			if !r.startsOn(d) {
+				continue
+			}
+			for day := range max(r.Days, 1) {
+				if dayAbs := abs + int64(day); dayAbs >= startAbs && dayAbs <= endAbs {
+					found = append(found, occurrence{abs: dayAbs, rule: i, day: day + 1})
+				}
+			}
+		}
+	}
+	slices.SortFunc(found, func(a, b occurrence) int {
+		return cmp.Or(cmp.Compare(a.abs, b.abs), cmp.Compare(a.rule, b.rule))
+	})
+
+	results := make([]event.CalEvent, 0, len(found))
+	for _, o := range found {
+		results = append(results, rules[o.rule].event(hdate.FromRD(o.abs), o.day, tz, opts))
+	}
+	return results, nil
+}
+
+// event returns the given day of the occurrence of r on hd.
+func (r EventRule) event(
+	hd hdate.HDate,
+	day int,
+	tz *time.Location,
+	opts *hebcal.CalOptions,
+) event.CalEvent {
+	ev := FileEvent{
+		Date:       hd,
+		Desc:       r.Desc,
+		Flags:      event.USER_EVENT | r.Flags,
+		Categories: r.Categories,
+		Source:     r.Source,
+		Day:        day,
+		Days:       max(r.Days, 1),
+	}
+	if !r.Timed {
+		return ev
+	}
+	d := newCalDay(hd, opts.NoJulian)
+	hour, minute := int(r.Time/time.Hour), int(r.Time%time.Hour/time.Minute)
+	t := time.Date(d.GregYear, d.GregMonth, d.GregDay, hour, minute, 0, 0, tz)
+	return hebcal.NewTimedEvent(hd, ev.Render(""), ev.Flags, t, 0, ev, opts)
+}
+
+// TimeZone returns the time zone of loc,
+// or UTC if loc is nil or has none.
+func TimeZone(loc *zmanim.Location) (*time.Location, error) {
+	if loc == nil || loc.TimeZoneId == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(loc.TimeZoneId)
+}
+
+// HebrewCalendar is like [hebcal.HebrewCalendar],
+// but adds the events of rules after those of hebcal on each day.
+// If opts.AddHebrewDates or opts.AddHebrewDatesForEvents is set,
+// days with no other events get a [event.HebrewDateEvent] first.
+func HebrewCalendar(
+	opts *hebcal.CalOptions,
+	rules EventRules,
+) ([]event.CalEvent, error) {
+	events, err := hebcal.HebrewCalendar(opts)
+	if err != nil {
+		return nil, err
+	}
+	extra, err := rules.Occurrences(opts)
+	if err != nil {
+		return nil, err
+	}
+	return mergeEvents(
+		events,
+		extra,
+		opts.AddHebrewDates || opts.AddHebrewDatesForEvents,
+	), nil
+}
+
+// mergeEvents inserts the extra events into events,
+// after the events of the same day.
+// Both must be sorted by date.
+func mergeEvents(
+	events, extra []event.CalEvent,
+	addHebrewDates bool,
+) []event.CalEvent {
+	if len(extra) == 0 {
+		return events
+	}
+	results := make([]event.CalEvent, 0, len(events)+len(extra)+1)
+	i := 0
+	for j := 0; j < len(extra); {
+		hd := extra[j].GetDate()
+		abs := hd.Abs()
+		for i < len(events) && dateAbs(events[i]) <= abs {
+			results = append(results, events[i])
+			i++
+		}
+		if addHebrewDates && !hasEventOn(results, abs) {
+			results = append(results, event.NewHebrewDateEvent(hd))
+		}
+		for j < len(extra) && dateAbs(extra[j]) == abs {
+			results = append(results, extra[j])
+			j++
+		}
+	}
+	return append(results, events[i:]...)
+}
+
+func dateAbs(ev event.CalEvent) int64 {
+	hd := ev.GetDate()
+	return hd.Abs()
+}
+
+// hasEventOn reports whether the last of events is on the day abs.
+func hasEventOn(events []event.CalEvent, abs int64) bool {
+	return len(events) != 0 && dateAbs(events[len(events)-1]) == abs
+}
@@ -0,0 +1,243 @@
+package hcfiles_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/event"
+	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/zmanim"
+
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestCalendarRange(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Opts      hebcal.CalOptions
+		WantStart hdate.HDate
+		WantEnd   hdate.HDate
+		Err       string
+	}{
+		{
+			Name:      "start and end",
+			Opts:      hebcal.CalOptions{Start: hdate.New(5786, hdate.Kislev, 1), End: hdate.New(5786, hdate.Kislev, 30)},
+			WantStart: hdate.New(5786, hdate.Kislev, 1),
+			WantEnd:   hdate.New(5786, hdate.Kislev, 30),
+		},
+		{
+			Name: "start without end",
+			Opts: hebcal.CalOptions{Start: hdate.New(5786, hdate.Kislev, 1)},
+			Err:  "opts.Start requires opts.End",
+		},
+		{
+			Name:      "gregorian month",
+			Opts:      hebcal.CalOptions{Year: 2026, Month: time.February},
+			WantStart: hdate.FromGregorian(2026, time.February, 1),
+			WantEnd:   hdate.FromGregorian(2026, time.February, 28),
+		},
+		{
+			Name:      "gregorian years",
+			Opts:      hebcal.CalOptions{Year: 2025, NumYears: 2},
+			WantStart: hdate.FromGregorian(2025, time.January, 1),
+			WantEnd:   hdate.FromGregorian(2026, time.December, 31),
+		},
+		{
+			Name:      "hebrew year",
+			Opts:      hebcal.CalOptions{Year: 5786, IsHebrewYear: true},
+			WantStart: hdate.New(5785, hdate.Elul, 29),
+			WantEnd:   hdate.New(5786, hdate.Elul, 29),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			start, end, err := hcfiles.CalendarRange(&c.Opts)
+			test.CheckErr(t, err, c.Err)
+			if err != nil {
+				return
+			}
+			test.CheckHDate(t, "start", c.WantStart, start)
+			test.CheckHDate(t, "end", c.WantEnd, end)
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	nov2025 := hebcal.CalOptions{Year: 2025, Month: time.November}
+	cases := []struct {
+		Name  string
+		Opts  hebcal.CalOptions
+		Rules hcfiles.EventRules
+		Want  []string
+	}{
+		{Name: "no rules", Opts: nov2025},
+		{
+			Name: "first Shabbat of Cheshvan",
+			Opts: nov2025,
+			Rules: hcfiles.EventRules{{
+				Schedule: hcfiles.Monthly{
+					Hebrew:      true,
+					HebrewMonth: hdate.Cheshvan,
+					Nth:         1,
+					ByWeekday:   true,
+					Weekday:     time.Saturday,
+				},
+				Desc: "Kiddush",
+				Days: 1,
+			}},
+			Want: nil, // 1 Cheshvan 5786 was in October
+		},
+		{
+			Name: "last Shabbat of Cheshvan",
+			Opts: nov2025,
+			Rules: hcfiles.EventRules{{
+				Schedule: hcfiles.Monthly{
+					Hebrew:      true,
+					HebrewMonth: hdate.Cheshvan,
+					Nth:         hcfiles.Last,
+					ByWeekday:   true,
+					Weekday:     time.Saturday,
+				},
+				Desc: "Kiddush",
+				Days: 1,
+			}},
+			Want: []string{"2025-11-15 Kiddush"},
+		},
+		{
+			Name: "last day of each Hebrew month",
+			Opts: nov2025,
+			Rules: hcfiles.EventRules{{
+				Schedule: hcfiles.Monthly{Hebrew: true, Nth: hcfiles.Last},
+				Desc:     "Month ends",
+				Days:     1,
+			}},
+			Want: []string{"2025-11-20 Month ends"},
+		},
+		{
+			Name: "years limit",
+			Opts: hebcal.CalOptions{Year: 2024, NumYears: 3},
+			Rules: hcfiles.EventRules{{
+				Schedule: hcfiles.GregorianAnnual{Month: time.July, Day: 4},
+				Desc:     "Picnic",
+				Days:     1,
+				FromYear: 2025,
+			}},
+			Want: []string{"2025-07-04 Picnic", "2026-07-04 Picnic"},
+		},
+		{
+			Name: "multi-day event started before range",
+			Opts: nov2025,
+			Rules: hcfiles.EventRules{{
+				Schedule: hcfiles.GregorianDate{Year: 2025, Month: time.October, Day: 30},
+				Desc:     "Retreat",
+				Days:     3,
+			}},
+			Want: []string{
+				"2025-11-01 Retreat (day 3 of 3)",
+			},
+		},
+		{
+			Name: "same day in rule order",
+			Opts: hebcal.CalOptions{
+				Start: hdate.FromGregorian(2025, time.November, 4),
+				End:   hdate.FromGregorian(2025, time.November, 4),
+			},
+			Rules: hcfiles.EventRules{
+				{Schedule: hcfiles.Weekly{Weekday: time.Tuesday}, Desc: "Shiur", Days: 1},
+				{Schedule: hcfiles.Monthly{Nth: 4}, Desc: "Dues", Days: 1},
+			},
+			Want: []string{"2025-11-04 Shiur", "2025-11-04 Dues"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := c.Rules.Occurrences(&c.Opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotStr := make([]string, 0, len(got))
+			for _, ev := range got {
+				gotStr = append(gotStr, formatEvent(ev))
+			}
+			test.CheckSlice(t, "occurrences", c.Want, gotStr)
+		})
+	}
+}
+
+func TestOccurrences_Timed(t *testing.T) {
+	loc := zmanim.NewLocation("New York", "US", 40.71, -74.0, "America/New_York")
+	opts := hebcal.CalOptions{
+		Location: &loc,
+		Start:    hdate.FromGregorian(2025, time.November, 4),
+		End:      hdate.FromGregorian(2025, time.November, 4),
+	}
+	rules := hcfiles.EventRules{{
+		Schedule:   hcfiles.Weekly{Weekday: time.Tuesday},
+		Desc:       "Shiur",
+		Days:       1,
+		Timed:      true,
+		Time:       20*time.Hour + 15*time.Minute,
+		Flags:      event.MINOR_HOLIDAY,
+		Categories: []string{"learning"},
+	}}
+
+	got, err := rules.Occurrences(&opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(got))
+	}
+	timed, ok := got[0].(hebcal.TimedEvent)
+	if !ok {
+		t.Fatalf("expected a hebcal.TimedEvent, got %T", got[0])
+	}
+	test.CheckString(t, "time",
+		"2025-11-04T20:15:00-05:00", timed.EventTime.Format(time.RFC3339))
+	test.CheckComparable(t, "flags",
+		event.USER_EVENT|event.MINOR_HOLIDAY, timed.GetFlags())
+	linked, ok := timed.LinkedEvent.(hcfiles.FileEvent)
+	if !ok {
+		t.Fatalf("expected a linked hcfiles.FileEvent, got %T", timed.LinkedEvent)
+	}
+	test.CheckSlice(t, "categories", []string{"learning"}, linked.Categories)
+}
+
+func TestHebrewCalendar(t *testing.T) {
+	opts := hebcal.CalOptions{
+		Start:                   hdate.FromGregorian(2025, time.November, 3),
+		End:                     hdate.FromGregorian(2025, time.November, 5),
+		NoHolidays:              true,
+		AddHebrewDatesForEvents: true,
+		UserEvents: []hebcal.UserEvent{
+			{Month: hdate.Cheshvan, Day: 13, Desc: "Birthday"},
+		},
+	}
+	rules := hcfiles.EventRules{
+		{Schedule: hcfiles.Weekly{Weekday: time.Tuesday}, Desc: "Shiur", Days: 1},
+		{Schedule: hcfiles.Weekly{Weekday: time.Wednesday}, Desc: "Chesed", Days: 1},
+	}
+
+	got, err := hcfiles.HebrewCalendar(&opts, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotStr := make([]string, 0, len(got))
+	for _, ev := range got {
+		gotStr = append(gotStr, formatEvent(ev))
+	}
+	test.CheckSlice(t, "events", []string{
+		"2025-11-04 13th of Cheshvan, 5786",
+		"2025-11-04 Birthday",
+		"2025-11-04 Shiur",
+		"2025-11-05 14th of Cheshvan, 5786",
+		"2025-11-05 Chesed",
+	}, gotStr)
+}
+
+func formatEvent(ev event.CalEvent) string {
+	hd := ev.GetDate()
+	return hd.Gregorian().Format(time.DateOnly) + " " + ev.Render("en")
+}
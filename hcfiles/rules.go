@@ -0,0 +1,370 @@
+package hcfiles
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hebcal/hebcal-go/event"
+
+	"github.com/chaimleib/hebcalfmt/xhdate"
+)
+
+// EventRule is an entry of an events file
+// which hebcal cannot generate by itself with a [hebcal.UserEvent].
+// Its events are generated with [EventRules.Occurrences].
+type EventRule struct {
+	Schedule Schedule
+	Desc     string
+
+	// FromYear and ToYear limit the years in which the event starts,
+	// inclusive. Zero means no limit.
+	// The years are Hebrew years if the Schedule IsHebrew,
+	// or else Gregorian years.
+	FromYear, ToYear int
+
+	// Days is how many days the event lasts, at least 1.
+	Days int
+
+	// If Timed is set, the event happens at Time past midnight,
+	// in the time zone of the location.
+	Timed bool
+	Time  time.Duration
+
+	// Flags are set on the events in addition to [event.USER_EVENT].
+	Flags      event.HolidayFlags
+	Categories []string
+
+	// Source is the events file which the rule came from, if known.
+	Source string
+}
+
+// EventRules are the [EventRule]s of an events file.
+type EventRules []EventRule
+
+// inYears reports whether year is within the FromYear and ToYear of r.
+func (r EventRule) inYears(year int) bool {
+	return (r.FromYear == 0 || year >= r.FromYear) &&
+		(r.ToYear == 0 || year <= r.ToYear)
+}
+
+// startsOn reports whether an occurrence of r starts on d.
+func (r EventRule) startsOn(d calDay) bool {
+	year := d.GregYear
+	if r.Schedule.IsHebrew() {
+		year = d.Year()
+	}
+	return r.inYears(year) && r.Schedule.matches(d)
+}
+
+var (
+	gregDateRe   = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	gregAnnualRe = regexp.MustCompile(`^(\d{1,2})-(\d{1,2})$`)
+	timeRe       = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	yearsRe      = regexp.MustCompile(`^(\d*)-(\d*)$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+	"shabbat": time.Saturday, "shabbos": time.Saturday,
+}
+
+var ordinals = map[string]int{
+	"first": 1, "1st": 1,
+	"second": 2, "2nd": 2,
+	"third": 3, "3rd": 3,
+	"fourth": 4, "4th": 4,
+	"fifth": 5, "5th": 5,
+	"last": Last,
+}
+
+// nextField splits the first whitespace-separated field off of s.
+func nextField(s string) (field, rest string) {
+	s = strings.TrimLeft(s, " \t")
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeft(s[i:], " \t")
+}
+
+// isExtendedDate reports whether word starts a date
+// in the extended syntax of [ParseEventRules].
+func isExtendedDate(word string) bool {
+	lower := strings.ToLower(word)
+	_, isOrdinal := ordinals[lower]
+	return isOrdinal || lower == "every" || lower == "day" ||
+		gregDateRe.MatchString(word) || gregAnnualRe.MatchString(word)
+}
+
+// parseSchedule parses the date at the start of line
+// in the extended syntax of [ParseEventRules],
+// and returns the rest of the line.
+func parseSchedule(line string) (Schedule, string, error) {
+	word, rest := nextField(line)
+	lower := strings.ToLower(word)
+
+	if m := gregDateRe.FindStringSubmatch(word); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		day, _ := strconv.Atoi(m[3])
+		if err := checkGregorian(year, month, day); err != nil {
+			return nil, "", err
+		}
+		return GregorianDate{Year: year, Month: time.Month(month), Day: day}, rest, nil
+	}
+
+	if m := gregAnnualRe.FindStringSubmatch(word); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		day, _ := strconv.Atoi(m[2])
+		// 2024 is a leap year, so that February 29 is allowed.
+		if err := checkGregorian(2024, month, day); err != nil {
+			return nil, "", err
+		}
+		return GregorianAnnual{Month: time.Month(month), Day: day}, rest, nil
+	}
+
+	if lower == "every" {
+		word, rest = nextField(rest)
+		weekday, ok := weekdays[strings.ToLower(word)]
+		if !ok {
+			return nil, "", fmt.Errorf(
+				"%w: expected a weekday after \"every\", got %q",
+				ErrInvalidFormat, word)
+		}
+		return Weekly{Weekday: weekday}, rest, nil
+	}
+
+	var s Monthly
+	if lower == "day" {
+		word, rest = nextField(rest)
+		n, err := strconv.Atoi(word)
+		if err != nil || n < 1 || n > 31 {
+			return nil, "", fmt.Errorf("%w: %q", ErrInvalidDays, word)
+		}
+		s.Nth = n
+	} else {
+		s.Nth = ordinals[lower]
+		word, rest = nextField(rest)
+		if strings.ToLower(word) != "day" {
+			weekday, ok := weekdays[strings.ToLower(word)]
+			if !ok {
+				return nil, "", fmt.Errorf(
+					"%w: expected \"day\" or a weekday after %q, got %q",
+					ErrInvalidFormat, lower, word)
+			}
+			s.ByWeekday = true
+			s.Weekday = weekday
+		}
+	}
+
+	word, rest = nextField(rest)
+	if strings.ToLower(word) != "of" {
+		return nil, "", fmt.Errorf(
+			"%w: expected \"of\" and a month, got %q", ErrInvalidFormat, word)
+	}
+	rest, err := parseMonthSpec(&s, rest)
+	if err != nil {
+		return nil, "", err
+	}
+	if s.Nth > 30 && s.Hebrew {
+		return nil, "", fmt.Errorf("%w: Hebrew months have at most 30 days", ErrInvalidDays)
+	}
+	return s, rest, nil
+}
+
+// checkGregorian returns an error if the date does not exist.
+func checkGregorian(year, month, day int) error {
+	if month < 1 || month > 12 {
+		return fmt.Errorf("%w: %d", ErrInvalidMonth, month)
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if day < 1 || t.Day() != day {
+		return fmt.Errorf("%w: %s has no day %d", ErrInvalidDays, time.Month(month), day)
+	}
+	return nil
+}
+
+// parseMonthSpec parses the month after the "of" in a [Monthly] date,
+// sets it on s, and returns the rest of the line.
+// The month is either "each Hebrew month", "each Gregorian month",
+// the English name of a Gregorian month, or the name of a Hebrew month.
+func parseMonthSpec(s *Monthly, line string) (string, error) {
+	word, rest := nextField(line)
+	lower := strings.ToLower(word)
+
+	if lower == "each" || lower == "every" {
+		calendar, rest := nextField(rest)
+		month, rest := nextField(rest)
+		if strings.ToLower(month) != "month" {
+			calendar = ""
+		}
+		switch strings.ToLower(calendar) {
+		case "hebrew":
+			s.Hebrew = true
+			return rest, nil
+		case "gregorian":
+			return rest, nil
+		}
+		return "", fmt.Errorf(
+			"%w: expected \"%s Hebrew month\" or \"%s Gregorian month\"",
+			ErrInvalidMonth, lower, lower)
+	}
+
+	for m := time.January; m <= time.December; m++ {
+		if strings.EqualFold(word, m.String()) {
+			s.GregMonth = m
+			return rest, nil
+		}
+	}
+
+	// Months like "Adar II" take two words.
+	second, afterSecond := nextField(rest)
+	if second != "" && xhdate.IsMonthName(word+" "+second) {
+		month, _ := xhdate.ParseMonth(word + " " + second)
+		s.Hebrew = true
+		s.HebrewMonth = month
+		return afterSecond, nil
+	}
+
+	month, err := xhdate.ParseMonth(word)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidMonth, err)
+	}
+	s.Hebrew = true
+	s.HebrewMonth = month
+	return rest, nil
+}
+
+// optionKeys are the keys of the options which may follow the date
+// in an events file.
+var optionKeys = []string{"time", "days", "years", "flags", "category"}
+
+// parseOptions parses the `key=value` options at the start of line
+// into r, and sets the rest of the line as its Desc.
+// It reports whether there were any options.
+func parseOptions(r *EventRule, line string) (bool, error) {
+	found := false
+	for {
+		word, rest := nextField(line)
+		key, value, ok := strings.Cut(word, "=")
+		if !ok || !slices.Contains(optionKeys, strings.ToLower(key)) {
+			break
+		}
+		if err := r.setOption(strings.ToLower(key), value); err != nil {
+			return found, err
+		}
+		found = true
+		line = rest
+	}
+
+	r.Desc = strings.TrimSpace(line)
+	if r.Desc == "" {
+		return found, fmt.Errorf("%w: missing description", ErrInvalidFormat)
+	}
+	return found, nil
+}
+
+// setOption parses the value of the option named key into r.
+func (r *EventRule) setOption(key, value string) error {
+	switch key {
+	case "time":
+		t, err := parseTimeOfDay(value)
+		if err != nil {
+			return fmt.Errorf("%w: time=%s: %w", ErrInvalidOption, value, err)
+		}
+		r.Timed = true
+		r.Time = t
+
+	case "days":
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > 366 {
+			return fmt.Errorf("%w: days=%s: expected 1 to 366", ErrInvalidOption, value)
+		}
+		r.Days = days
+
+	case "years":
+		from, to := value, value
+		if m := yearsRe.FindStringSubmatch(value); m != nil {
+			from, to = m[1], m[2]
+		}
+		var err error
+		if r.FromYear, err = parseOptionalYear(from); err != nil {
+			return fmt.Errorf("%w: years=%s: %w", ErrInvalidOption, value, err)
+		}
+		if r.ToYear, err = parseOptionalYear(to); err != nil {
+			return fmt.Errorf("%w: years=%s: %w", ErrInvalidOption, value, err)
+		}
+		if r.ToYear != 0 && r.FromYear > r.ToYear {
+			return fmt.Errorf("%w: years=%s: range ends before it starts",
+				ErrInvalidOption, value)
+		}
+
+	case "flags":
+		for name := range strings.SplitSeq(value, ",") {
+			flag, ok := EventFlags[strings.ToUpper(name)]
+			if !ok {
+				return fmt.Errorf("%w: flags=%s: unknown flag %q",
+					ErrInvalidOption, value, name)
+			}
+			r.Flags |= flag
+		}
+
+	case "category":
+		for name := range strings.SplitSeq(value, ",") {
+			if name == "" {
+				return fmt.Errorf("%w: category=%s: empty category",
+					ErrInvalidOption, value)
+			}
+			r.Categories = append(r.Categories, name)
+		}
+	}
+	return nil
+}
+
+// parseTimeOfDay parses a time like 19:30 or 7:30pm
+// into the duration since midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	m := timeRe.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return 0, errors.New("expected HH:MM")
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	switch m[3] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, errors.New("invalid hour")
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, errors.New("invalid time")
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// parseOptionalYear parses a year, which may be empty for no limit.
+func parseOptionalYear(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	year, err := strconv.Atoi(s)
+	if err != nil || year < 1 {
+		return 0, fmt.Errorf("invalid year %q", s)
+	}
+	return year, nil
+}
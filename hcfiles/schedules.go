@@ -0,0 +1,226 @@
+package hcfiles
+
+import (
+	"time"
+
+	"github.com/hebcal/greg"
+	"github.com/hebcal/hdate"
+)
+
+// Last is the [Monthly.Nth] of the last day or weekday of a month.
+const Last = -1
+
+// Schedule selects the days on which an [EventRule] starts.
+// The implementations are the types of this package,
+// like [HebrewAnnual] and [Weekly].
+type Schedule interface {
+	// IsHebrew reports whether the schedule follows the Hebrew calendar,
+	// which is also the calendar of the rule's FromYear and ToYear.
+	IsHebrew() bool
+
+	matches(d calDay) bool
+}
+
+// calDay is a day on both calendars.
+type calDay struct {
+	hdate.HDate
+	GregYear  int
+	GregMonth time.Month
+	GregDay   int
+}
+
+// newCalDay returns the calDay of hd.
+// If noJulian is set, dates before 1752 use the proleptic Gregorian calendar,
+// like [hebcal.CalOptions.NoJulian].
+func newCalDay(hd hdate.HDate, noJulian bool) calDay {
+	var gy, gd int
+	var gm time.Month
+	if noJulian {
+		gy, gm, gd = hd.ProlepticGreg()
+	} else {
+		gy, gm, gd = hd.Greg()
+	}
+	return calDay{HDate: hd, GregYear: gy, GregMonth: gm, GregDay: gd}
+}
+
+// HebrewAnnual is a yearly date on the Hebrew calendar, like "Cheshvan 3".
+// Like [hebcal.UserEvent], it is skipped in years
+// when the month is too short for the day.
+type HebrewAnnual struct {
+	Month hdate.HMonth
+	Day   int
+}
+
+func (s HebrewAnnual) IsHebrew() bool { return true }
+
+func (s HebrewAnnual) matches(d calDay) bool {
+	year := d.Year()
+	if s.Day > hdate.DaysInMonth(s.Month, year) {
+		return false
+	}
+	want := hdate.New(year, s.Month, s.Day)
+	return want.Abs() == d.Abs()
+}
+
+// GregorianAnnual is a yearly date on the Gregorian calendar,
+// like "07-04" for July 4th.
+type GregorianAnnual struct {
+	Month time.Month
+	Day   int
+}
+
+func (s GregorianAnnual) IsHebrew() bool { return false }
+
+func (s GregorianAnnual) matches(d calDay) bool {
+	return d.GregMonth == s.Month && d.GregDay == s.Day
+}
+
+// GregorianDate is a single date on the Gregorian calendar,
+// like "2026-03-15".
+type GregorianDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+func (s GregorianDate) IsHebrew() bool { return false }
+
+func (s GregorianDate) matches(d calDay) bool {
+	return d.GregYear == s.Year && d.GregMonth == s.Month && d.GregDay == s.Day
+}
+
+// Weekly is a day of the week, like "every Tuesday".
+type Weekly struct {
+	Weekday time.Weekday
+}
+
+func (s Weekly) IsHebrew() bool { return false }
+
+func (s Weekly) matches(d calDay) bool { return d.Weekday() == s.Weekday }
+
+// Monthly is a day of a month, or of every month,
+// on either calendar, like "first Shabbat of Cheshvan",
+// "last day of each Hebrew month" or "day 15 of each Gregorian month".
+type Monthly struct {
+	// Hebrew selects Hebrew months instead of Gregorian ones.
+	Hebrew bool
+
+	// HebrewMonth is the Hebrew month, or 0 for every Hebrew month.
+	// Adar II stands for Adar in non-leap years.
+	HebrewMonth hdate.HMonth
+
+	// GregMonth is the Gregorian month, or 0 for every Gregorian month.
+	GregMonth time.Month
+
+	// Nth counts from 1, or is [Last].
+	// It counts the Weekday if ByWeekday is set,
+	// or else the days of the month.
+	Nth       int
+	ByWeekday bool
+	Weekday   time.Weekday
+}
+
+func (s Monthly) IsHebrew() bool { return s.Hebrew }
+
+func (s Monthly) matches(d calDay) bool {
+	var day, daysInMonth int
+	if s.Hebrew {
+		if s.HebrewMonth != 0 && d.Month() != s.hebrewMonthIn(d.Year()) {
+			return false
+		}
+		day, daysInMonth = d.Day(), d.DaysInMonth()
+	} else {
+		if s.GregMonth != 0 && d.GregMonth != s.GregMonth {
+			return false
+		}
+		day, daysInMonth = d.GregDay, greg.DaysIn(d.GregMonth, d.GregYear)
+	}
+
+	if !s.ByWeekday {
+		if s.Nth == Last {
+			return day == daysInMonth
+		}
+		return day == s.Nth
+	}
+
+	if d.Weekday() != s.Weekday {
+		return false
+	}
+	if s.Nth == Last {
+		return day+7 > daysInMonth
+	}
+	return (day-1)/7+1 == s.Nth
+}
+
+// hebrewMonthIn returns the HebrewMonth as it is in year,
+// which is Adar I for Adar II in non-leap years.
+func (s Monthly) hebrewMonthIn(year int) hdate.HMonth {
+	if s.HebrewMonth == hdate.Adar2 && !hdate.IsLeapYear(year) {
+		return hdate.Adar1
+	}
+	return s.HebrewMonth
+}
+
+// YahrzeitDate is the yearly anniversary of a Hebrew date of death,
+// observed according to the Adar and Day30 customs.
+type YahrzeitDate struct {
+	Death hdate.HDate
+	Adar  AdarCustom
+	Day30 Day30Custom
+}
+
+func (s YahrzeitDate) IsHebrew() bool { return true }
+
+func (s YahrzeitDate) matches(d calDay) bool {
+	for _, hd := range s.Observed(d.Year()) {
+		if hd.Abs() == d.Abs() {
+			return true
+		}
+	}
+	return false
+}
+
+// Observed returns the days in the Hebrew year hyear
+// on which the yahrzeit is observed, in order.
+// There are none until the year after the death,
+// and two for a death in Adar observed in both Adars of a leap year.
+//
+// The default customs are those of [hdate.GetYahrzeit].
+func (s YahrzeitDate) Observed(hyear int) []hdate.HDate {
+	deathYear := s.Death.Year()
+	if hyear <= deathYear {
+		return nil
+	}
+	month, day := s.Death.Month(), s.Death.Day()
+
+	if month == hdate.Adar1 && !hdate.IsLeapYear(deathYear) &&
+		hdate.IsLeapYear(hyear) {
+		switch s.Adar {
+		case AdarII:
+			return []hdate.HDate{hdate.New(hyear, hdate.Adar2, day)}
+		case AdarBoth:
+			return []hdate.HDate{
+				hdate.New(hyear, hdate.Adar1, day),
+				hdate.New(hyear, hdate.Adar2, day),
+			}
+		}
+	}
+
+	if day == 30 && (month == hdate.Cheshvan || month == hdate.Kislev) &&
+		s.Day30 != Day30FirstYear {
+		switch {
+		case hdate.DaysInMonth(month, hyear) == 30:
+			return []hdate.HDate{hdate.New(hyear, month, 30)}
+		case s.Day30 == Day30Last:
+			return []hdate.HDate{hdate.New(hyear, month, 29)}
+		default:
+			return []hdate.HDate{hdate.New(hyear, month+1, 1)}
+		}
+	}
+
+	observed, err := hdate.GetYahrzeit(hyear, s.Death)
+	if err != nil {
+		return nil
+	}
+	return []hdate.HDate{observed}
+}
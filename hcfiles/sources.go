@@ -0,0 +1,132 @@
+package hcfiles
+
+import (
+	"fmt"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/event"
+	"github.com/hebcal/hebcal-go/hebcal"
+)
+
+// Extras are what events and yahrzeit files add to a calendar
+// beyond the [hebcal.CalOptions].
+type Extras struct {
+	// Rules are the entries of the events files
+	// which need the extended syntax.
+	Rules EventRules
+
+	// Sources records which files the other entries came from.
+	Sources Sources
+}
+
+// Sources maps the entries of events and yahrzeit files
+// to the names of the files they came from.
+// The entries of [EventRules] record their own Source.
+type Sources struct {
+	UserEvents map[hebcal.UserEvent]string
+	Yahrzeits  map[hebcal.UserYahrzeit]string
+}
+
+// AddUserEvents records that the entries came from fileName.
+// Entries which were already added keep their first file.
+func (s *Sources) AddUserEvents(fileName string, entries ...hebcal.UserEvent) {
+	if s.UserEvents == nil {
+		s.UserEvents = make(map[hebcal.UserEvent]string, len(entries))
+	}
+	for _, entry := range entries {
+		if _, ok := s.UserEvents[entry]; !ok {
+			s.UserEvents[entry] = fileName
+		}
+	}
+}
+
+// AddYahrzeits records that the entries came from fileName,
+// like [Sources.AddUserEvents].
+func (s *Sources) AddYahrzeits(fileName string, entries ...hebcal.UserYahrzeit) {
+	if s.Yahrzeits == nil {
+		s.Yahrzeits = make(map[hebcal.UserYahrzeit]string, len(entries))
+	}
+	for _, entry := range entries {
+		if _, ok := s.Yahrzeits[entry]; !ok {
+			s.Yahrzeits[entry] = fileName
+		}
+	}
+}
+
+// Of returns the name of the file which ev came from,
+// or the empty string if it did not come from a file.
+//
+// [FileEvent]s, and [hebcal.TimedEvent]s linked to them, know their Source.
+// The [event.UserEvent]s generated by hebcal are matched by
+// their description and date to the entries which produce them.
+func (s Sources) Of(ev event.CalEvent) string {
+	switch ev := ev.(type) {
+	case FileEvent:
+		return ev.Source
+	case hebcal.TimedEvent:
+		if ev.LinkedEvent != nil {
+			return s.Of(ev.LinkedEvent)
+		}
+	case event.UserEvent:
+		year, abs := ev.Date.Year(), ev.Date.Abs()
+		for entry, fileName := range s.UserEvents {
+			if entry.Desc != ev.Desc ||
+				entry.Day > hdate.DaysInMonth(entry.Month, year) {
+				continue
+			}
+			if hdate.ToRD(year, entry.Month, entry.Day) == abs {
+				return fileName
+			}
+		}
+		for entry, fileName := range s.Yahrzeits {
+			if entry.Name != ev.Desc {
+				continue
+			}
+			observed, err := hdate.GetYahrzeit(year, hdate.FromTime(entry.Date))
+			if err == nil && observed.Abs() == abs {
+				return fileName
+			}
+		}
+	}
+	return ""
+}
+
+// AddYahrzeits adds the entries of the yahrzeit file fileName
+// to opts and e.
+// Entries which hebcal can observe by itself
+// become [hebcal.UserYahrzeit]s in opts.Yahrzeits,
+// and the others become Rules with a [YahrzeitDate] schedule.
+// Times of death are resolved with opts.Location; see [Yahrzeit.DeathDate].
+func (e *Extras) AddYahrzeits(
+	opts *hebcal.CalOptions,
+	fileName string,
+	entries ...Yahrzeit,
+) error {
+	for _, entry := range entries {
+		death, err := entry.DeathDate(opts.Location)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", fileName, entry.Name, err)
+		}
+		if entry.hasDefaultCustoms() {
+			yahrzeit := hebcal.UserYahrzeit{Date: death.Gregorian(), Name: entry.Name}
+			opts.Yahrzeits = append(opts.Yahrzeits, yahrzeit)
+			e.Sources.AddYahrzeits(fileName, yahrzeit)
+			continue
+		}
+		e.Rules = append(e.Rules, EventRule{
+			Schedule: YahrzeitDate{Death: death, Adar: entry.Adar, Day30: entry.Day30},
+			Desc:     entry.Name,
+			Days:     1,
+			Source:   fileName,
+		})
+	}
+	return nil
+}
+
+// RulesOrNil returns the Rules, or nil if e is nil.
+func (e *Extras) RulesOrNil() EventRules {
+	if e == nil {
+		return nil
+	}
+	return e.Rules
+}
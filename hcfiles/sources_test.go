@@ -0,0 +1,98 @@
+package hcfiles_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/zmanim"
+
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestSources_Of(t *testing.T) {
+	birthday := hebcal.UserEvent{Month: hdate.Cheshvan, Day: 13, Desc: "Birthday"}
+	yahrzeit := hebcal.UserYahrzeit{Date: date(1967, time.October, 8), Name: "Yahrzeit"}
+	var sources hcfiles.Sources
+	sources.AddUserEvents("family.txt", birthday)
+	sources.AddYahrzeits("yahrzeits/father.txt", yahrzeit)
+
+	opts := hebcal.CalOptions{
+		Year:       2025,
+		NoHolidays: true,
+		UserEvents: []hebcal.UserEvent{
+			birthday,
+			{Month: hdate.Kislev, Day: 1, Desc: "Untracked"},
+		},
+		Yahrzeits: []hebcal.UserYahrzeit{yahrzeit},
+	}
+	rules := hcfiles.EventRules{{
+		Schedule: hcfiles.GregorianAnnual{Month: time.July, Day: 4},
+		Desc:     "Picnic",
+		Days:     1,
+		Source:   "shul.txt",
+	}}
+
+	events, err := hcfiles.HebrewCalendar(&opts, rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(events))
+	for _, ev := range events {
+		got = append(got, ev.Render("en")+" <- "+sources.Of(ev))
+	}
+	test.CheckSlice(t, "sources", []string{
+		"Picnic <- shul.txt",
+		"Yahrzeit <- yahrzeits/father.txt",
+		"Birthday <- family.txt",
+		"Untracked <- ",
+	}, got)
+}
+
+func TestExtras_AddYahrzeits(t *testing.T) {
+	opts := hebcal.CalOptions{
+		Location:   zmanim.LookupCity("New York"),
+		Start:      hdate.New(5784, hdate.Adar1, 1),
+		End:        hdate.New(5784, hdate.Adar2, 29),
+		NoHolidays: true,
+	}
+	var extras hcfiles.Extras
+	err := extras.AddYahrzeits(&opts, "yahrzeit.txt",
+		hcfiles.Yahrzeit{
+			Date:  date(1967, time.October, 8),
+			Timed: true,
+			Time:  21 * time.Hour,
+			Name:  "Joe Shmo",
+		},
+		hcfiles.Yahrzeit{
+			HebrewDate: hdate.New(5783, hdate.Adar1, 7),
+			Adar:       hcfiles.AdarBoth,
+			Name:       "Moshe",
+		},
+	)
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "opts.Yahrzeits", []hebcal.UserYahrzeit{
+		{Date: date(1967, time.October, 9), Name: "Joe Shmo"},
+	}, opts.Yahrzeits)
+
+	events, err := hcfiles.HebrewCalendar(&opts, extras.Rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0, len(events))
+	for _, ev := range events {
+		got = append(got, ev.GetDate().String()+" "+ev.Render("en")+
+			" <- "+extras.Sources.Of(ev))
+	}
+	test.CheckSlice(t, "events", []string{
+		"7 Adar I 5784 Moshe <- yahrzeit.txt",
+		"7 Adar II 5784 Moshe <- yahrzeit.txt",
+	}, got)
+
+	err = extras.AddYahrzeits(&hebcal.CalOptions{}, "yahrzeit.txt",
+		hcfiles.Yahrzeit{Date: date(1967, time.October, 8), Timed: true, Name: "Joe"})
+	test.CheckErr(t, err,
+		"yahrzeit.txt: Joe: a time of death needs a location, to find the sunset")
+}
@@ -1,20 +1,187 @@
 package hcfiles
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/hebcal/greg"
+	"github.com/hebcal/hdate"
 	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/zmanim"
+
+	"github.com/chaimleib/hebcalfmt/xhdate"
+)
+
+var (
+	gregRe        = regexp.MustCompile(`^(\d+)\s+(\d+)\s+(\d+)\s+(.+)$`)
+	hebYahrzeitRe = regexp.MustCompile(`^\D.*?\s+\d+\s+\d+\s+.+$`)
+)
+
+// maxMonthWords is the most words which the name of a Hebrew month spans,
+// as in "Adar - II".
+const maxMonthWords = 3
+
+// AdarCustom selects the Adar in which a yahrzeit is observed in leap years,
+// for a death in Adar of a non-leap year.
+type AdarCustom int
+
+const (
+	// AdarI observes the yahrzeit in Adar I, like [hdate.GetYahrzeit].
+	AdarI AdarCustom = iota
+	// AdarII observes the yahrzeit in Adar II.
+	AdarII
+	// AdarBoth observes the yahrzeit in both Adar I and Adar II.
+	AdarBoth
+)
+
+// Day30Custom selects the day on which a yahrzeit is observed
+// for a death on the 30th of Cheshvan or Kislev,
+// in years when that month has only 29 days.
+type Day30Custom int
+
+const (
+	// Day30FirstYear follows the first anniversary, like [hdate.GetYahrzeit]:
+	// if that month had only 29 days, the yahrzeit is always on the 29th;
+	// otherwise it is on the 1st of the next month in short years.
+	Day30FirstYear Day30Custom = iota
+	// Day30Last observes the yahrzeit on the 29th of the month.
+	Day30Last
+	// Day30Next observes the yahrzeit on the 1st of the next month.
+	Day30Next
 )
 
-var gregRe = regexp.MustCompile(`^(\d+)\s+(\d+)\s+(\d+)\s+(.+)$`)
+// Yahrzeit is an entry of a yahrzeit file.
+type Yahrzeit struct {
+	Name string
+
+	// Date is the Gregorian date of death.
+	// It is zero if the Hebrew date of death was given instead as HebrewDate.
+	Date       time.Time
+	HebrewDate hdate.HDate
+
+	// AfterSunset is set if the death was after sunset on Date,
+	// so that it was already the next Hebrew day.
+	AfterSunset bool
+
+	// If Timed is set, the death was at Time past midnight on Date,
+	// in the time zone of the location,
+	// and the Hebrew day depends on the sunset there.
+	Timed bool
+	Time  time.Duration
+
+	// Adar and Day30 select the customs for observing the yahrzeit.
+	Adar  AdarCustom
+	Day30 Day30Custom
+}
+
+// DeathDate returns the Hebrew date of death of y.
+// A Timed death is compared to the sunset on its Date at loc,
+// and it is an error if loc is nil.
+func (y Yahrzeit) DeathDate(loc *zmanim.Location) (hdate.HDate, error) {
+	if y.Date.IsZero() {
+		return y.HebrewDate, nil
+	}
+	hd := hdate.FromTime(y.Date)
+	switch {
+	case y.AfterSunset:
+		return hd.Next(), nil
+
+	case y.Timed:
+		if loc == nil {
+			return hdate.HDate{}, errors.New(
+				"a time of death needs a location, to find the sunset")
+		}
+		z := zmanim.New(loc, y.Date)
+		// Build the time from the clock, as the day may be shorter or longer
+		// than 24 hours when daylight saving time begins or ends.
+		year, month, day := y.Date.Date()
+		hour, minute := int(y.Time/time.Hour), int(y.Time%time.Hour/time.Minute)
+		death := time.Date(year, month, day, hour, minute, 0, 0, z.TimeZone)
+		if sunset := z.Sunset(); !sunset.IsZero() && !death.Before(sunset) {
+			return hd.Next(), nil
+		}
+	}
+	return hd, nil
+}
+
+// hasDefaultCustoms reports whether hebcal observes y by itself
+// with a [hebcal.UserYahrzeit].
+func (y Yahrzeit) hasDefaultCustoms() bool {
+	return y.Adar == AdarI && y.Day30 == Day30FirstYear
+}
+
+// String returns y as a line of a yahrzeits file.
+func (y Yahrzeit) String() string {
+	month, day, year := y.dateFields()
+	fields := []string{month, day, year}
+	if y.AfterSunset {
+		fields = append(fields, "after-sunset")
+	}
+	for _, key := range []string{"time", "adar", "day30"} {
+		if value := y.option(key); value != "" {
+			fields = append(fields, key+"="+value)
+		}
+	}
+	return strings.Join(append(fields, y.Name), " ")
+}
+
+// dateFields returns the month, day and year of the date of death.
+// The month is a number for Gregorian dates, or else a name.
+func (y Yahrzeit) dateFields() (month, day, year string) {
+	if y.Date.IsZero() {
+		hd := y.HebrewDate
+		month = hd.Month().String()
+		if hd.Month() == hdate.Adar1 && !hd.IsLeapYear() {
+			month = "Adar"
+		}
+		return month, strconv.Itoa(hd.Day()), strconv.Itoa(hd.Year())
+	}
+	gy, gm, gd := y.Date.Date()
+	return strconv.Itoa(int(gm)), strconv.Itoa(gd), strconv.Itoa(gy)
+}
+
+// option returns the value of the option named key for y,
+// or the empty string if it has the default value.
+func (y Yahrzeit) option(key string) string {
+	switch key {
+	case "time":
+		if y.Timed {
+			return fmt.Sprintf("%d:%02d", int(y.Time/time.Hour), int(y.Time%time.Hour/time.Minute))
+		}
+	case "adar":
+		switch y.Adar {
+		case AdarII:
+			return "2"
+		case AdarBoth:
+			return "both"
+		}
+	case "day30":
+		switch y.Day30 {
+		case Day30Last:
+			return "29"
+		case Day30Next:
+			return "1"
+		}
+	}
+	return ""
+}
+
+// WriteYahrzeits writes the entries as lines
+// which [ParseYahrzeitEntries] understands.
+func WriteYahrzeits(w io.Writer, entries []Yahrzeit) error {
+	for _, y := range entries {
+		if _, err := fmt.Fprintln(w, y); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 // ParseYahrzeits parses an [io.Reader] of event lines
 // and returns a slice of [hebcal.UserYahrzeit] entries.
@@ -25,62 +192,250 @@ var gregRe = regexp.MustCompile(`^(\d+)\s+(\d+)\s+(\d+)\s+(.+)$`)
 //	MM DD YYYY Description
 //
 // Description is a newline-terminated string.
+//
+// Lines in the other formats of [ParseYahrzeitEntries] are also accepted,
+// with the date of death moved to the next day if it was after sunset.
+// Lines with the other options are checked, but left out of the result,
+// since a [hebcal.UserYahrzeit] cannot hold them.
 func ParseYahrzeits(
 	f io.Reader,
 	fileName string,
 ) ([]hebcal.UserYahrzeit, error) {
-	lineNumber := 0
-	var errs []error
-	lineErr := func(err error) {
-		errs = append(errs, SyntaxError{
-			Err:        err,
-			FileName:   fileName,
-			LineNumber: lineNumber,
-		})
-	}
-
-	entries := make([]hebcal.UserYahrzeit, 0, 10)
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line0 := scanner.Text()
-		line := strings.TrimSpace(line0)
-		lineNumber++
-
-		fields := gregRe.FindStringSubmatch(line)
-		if len(fields) != 5 {
-			lineErr(
-				fmt.Errorf(
-					"%w: expected 5 capture fields, got %d",
-					ErrInvalidFormat,
-					len(fields),
-				),
-			)
+	entries, err := ParseYahrzeitEntries(f, fileName)
+	if err != nil {
+		return nil, err
+	}
+	yahrzeits := make([]hebcal.UserYahrzeit, 0, len(entries))
+	for _, y := range entries {
+		if y.Timed || !y.hasDefaultCustoms() {
 			continue
 		}
+		// Only Timed entries need a location.
+		death, _ := y.DeathDate(nil)
+		yahrzeits = append(yahrzeits,
+			hebcal.UserYahrzeit{Date: death.Gregorian(), Name: y.Name})
+	}
+	return yahrzeits, nil
+}
 
-		month0, _ := strconv.Atoi(fields[1])
-		if month0 < 1 || month0 > 12 {
-			lineErr(ErrInvalidMonth)
-			continue
+// ParseYahrzeitEntries parses an [io.Reader] of yahrzeit lines
+// and returns the [Yahrzeit] entries.
+// In case of an error, fileName helps with debugging.
+//
+// The lines are in one of the following formats:
+//
+//	MM DD YYYY Description
+//	MMMM DD YYYY Description
+//
+// The first uses the Gregorian date of death.
+// The second uses the Hebrew date of death,
+// where MMMM is a string identifying the Hebrew month, like "Adar I".
+// Description is a newline-terminated string.
+//
+// Between the date and the Description, these options may be given:
+//
+//	after-sunset          the death was after sunset on the Gregorian date
+//	time=HH:MM            the time of death on the Gregorian date,
+//	                      compared to the sunset at the location
+//	adar=1|2|both         the Adar in which to observe a death
+//	                      in Adar of a non-leap year, in leap years;
+//	                      the default is 1
+//	day30=29|1            the day on which to observe a death
+//	                      on the 30th of Cheshvan or Kislev,
+//	                      in years when the month has 29 days;
+//	                      the default follows the first anniversary
+//
+// Blank lines and lines starting with # are skipped.
+// ParseYahrzeitEntries fails on `#include` lines; see [YahrzeitsParser].
+func ParseYahrzeitEntries(f io.Reader, fileName string) ([]Yahrzeit, error) {
+	return YahrzeitsParser(nil)(f, fileName)
+}
+
+// YahrzeitsParser returns a function like [ParseYahrzeitEntries]
+// which also follows `#include` lines, like [EventsParser].
+func YahrzeitsParser(files fs.FS) func(io.Reader, string) ([]Yahrzeit, error) {
+	return func(f io.Reader, fileName string) ([]Yahrzeit, error) {
+		entries := make([]Yahrzeit, 0, 10)
+		s := lineScanner{
+			files: files,
+			parseLine: func(line string) error {
+				entry, err := parseYahrzeitLine(line)
+				if err != nil {
+					return err
+				}
+				entries = append(entries, entry)
+				return nil
+			},
 		}
+		s.scan(f, fileName, nil)
 
-		day, _ := strconv.Atoi(fields[2])
-		year, _ := strconv.Atoi(fields[3])
-		month := time.Month(month0)
-		if day < 1 || day > greg.DaysIn(month, year) {
-			lineErr(ErrInvalidDays)
-			continue
+		if len(s.errs) != 0 {
+			return nil, fmt.Errorf("ParseYahrzeits: %w", errors.Join(s.errs...))
 		}
+		return entries, nil
+	}
+}
 
-		gregDate := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
-		entries = append(
-			entries,
-			hebcal.UserYahrzeit{Date: gregDate, Name: fields[4]},
+// parseYahrzeitLine parses a line of a yahrzeit file.
+func parseYahrzeitLine(line string) (Yahrzeit, error) {
+	fields := gregRe.FindStringSubmatch(line)
+	if fields == nil && hebYahrzeitRe.MatchString(line) {
+		return parseHebrewYahrzeit(line)
+	}
+	if len(fields) != 5 {
+		return Yahrzeit{}, fmt.Errorf(
+			"%w: expected 5 capture fields, got %d",
+			ErrInvalidFormat,
+			len(fields),
 		)
 	}
 
-	if len(errs) != 0 {
-		return nil, fmt.Errorf("ParseYahrzeits: %w", errors.Join(errs...))
+	month0, _ := strconv.Atoi(fields[1])
+	if month0 < 1 || month0 > 12 {
+		return Yahrzeit{}, ErrInvalidMonth
+	}
+
+	day, _ := strconv.Atoi(fields[2])
+	year, _ := strconv.Atoi(fields[3])
+	month := time.Month(month0)
+	if day < 1 || day > greg.DaysIn(month, year) {
+		return Yahrzeit{}, ErrInvalidDays
+	}
+
+	y := Yahrzeit{Date: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+	if err := parseYahrzeitOptions(&y, fields[4]); err != nil {
+		return Yahrzeit{}, err
+	}
+	return y, nil
+}
+
+// parseHebrewYahrzeit parses a yahrzeit line matched by hebYahrzeitRe,
+// which starts with a Hebrew date like `Adar II 15 5785`.
+//
+// The month may span several words, like "Adar 2",
+// so the longest run of leading words which spells a month,
+// as judged by [xhdate.IsMonthName], and which is followed by
+// a day and a year, is taken as the month.
+// Failing that, the month is the words before the first day and year,
+// so that errors are reported against all of them.
+func parseHebrewYahrzeit(line string) (Yahrzeit, error) {
+	// rests[i] is the line after its first i words.
+	var words []string
+	rests := []string{line}
+	for rest := line; len(words) < maxMonthWords+2; {
+		var word string
+		word, rest = nextField(rest)
+		if word == "" {
+			break
+		}
+		words = append(words, word)
+		rests = append(rests, rest)
+	}
+	isNumber := func(s string) bool {
+		_, err := strconv.Atoi(s)
+		return err == nil
+	}
+	// n is the number of words in the month.
+	n := 0
+	for i := min(maxMonthWords, len(words)-2); i > 1; i-- {
+		if xhdate.IsMonthName(strings.Join(words[:i], " ")) &&
+			isNumber(words[i]) && isNumber(words[i+1]) {
+			n = i
+			break
+		}
+	}
+	for i := 1; n == 0 && i+1 < len(words); i++ {
+		if isNumber(words[i]) && isNumber(words[i+1]) {
+			n = i
+		}
+	}
+	if n == 0 {
+		return Yahrzeit{}, fmt.Errorf(
+			"%w: expected a month, day and year", ErrInvalidFormat)
+	}
+	if rests[n+2] == "" {
+		return Yahrzeit{}, fmt.Errorf("%w: missing description", ErrInvalidFormat)
+	}
+
+	month, err := xhdate.ParseMonth(strings.Join(words[:n], " "))
+	if err != nil {
+		return Yahrzeit{}, fmt.Errorf("%w: %w", ErrInvalidMonth, err)
+	}
+
+	day, _ := strconv.Atoi(words[n])
+	year, _ := strconv.Atoi(words[n+1])
+	if year < 1 {
+		return Yahrzeit{}, fmt.Errorf("%w: invalid year %d", ErrInvalidFormat, year)
+	}
+	if day < 1 || day > hdate.DaysInMonth(month, year) {
+		return Yahrzeit{}, fmt.Errorf(
+			"%w: %s %d has no day %d", ErrInvalidDays, month, year, day)
+	}
+
+	y := Yahrzeit{HebrewDate: hdate.New(year, month, day)}
+	if err := parseYahrzeitOptions(&y, rests[n+2]); err != nil {
+		return Yahrzeit{}, err
+	}
+	if y.AfterSunset || y.Timed {
+		return Yahrzeit{}, fmt.Errorf(
+			"%w: after-sunset and time= need a Gregorian date", ErrInvalidOption)
+	}
+	return y, nil
+}
+
+// parseYahrzeitOptions parses the options at the start of line into y,
+// and sets the rest of the line as its Name.
+func parseYahrzeitOptions(y *Yahrzeit, line string) error {
+	for {
+		word, rest := nextField(line)
+		key, value, ok := strings.Cut(strings.ToLower(word), "=")
+		switch {
+		case key == "after-sunset" && !ok:
+			y.AfterSunset = true
+
+		case key == "time" && ok:
+			t, err := parseTimeOfDay(value)
+			if err != nil {
+				return fmt.Errorf("%w: time=%s: %w", ErrInvalidOption, value, err)
+			}
+			y.Timed = true
+			y.Time = t
+
+		case key == "adar" && ok:
+			switch value {
+			case "1", "i":
+				y.Adar = AdarI
+			case "2", "ii":
+				y.Adar = AdarII
+			case "both":
+				y.Adar = AdarBoth
+			default:
+				return fmt.Errorf(
+					"%w: adar=%s: expected 1, 2 or both", ErrInvalidOption, value)
+			}
+
+		case key == "day30" && ok:
+			switch value {
+			case "29":
+				y.Day30 = Day30Last
+			case "1":
+				y.Day30 = Day30Next
+			default:
+				return fmt.Errorf(
+					"%w: day30=%s: expected 29 or 1", ErrInvalidOption, value)
+			}
+
+		default:
+			y.Name = strings.TrimSpace(line)
+			if y.Name == "" {
+				return fmt.Errorf("%w: missing description", ErrInvalidFormat)
+			}
+			if y.AfterSunset && y.Timed {
+				return fmt.Errorf(
+					"%w: after-sunset and time= are exclusive", ErrInvalidOption)
+			}
+			return nil
+		}
+		line = rest
 	}
-	return entries, nil
 }
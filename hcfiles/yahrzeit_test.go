@@ -7,7 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hebcal/hdate"
 	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/zmanim"
 
 	"github.com/chaimleib/hebcalfmt/hcfiles"
 	"github.com/chaimleib/hebcalfmt/test"
@@ -52,6 +54,24 @@ func TestParseYahrzeits(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name:    "comments and blank lines",
+			Content: "# Family\n\n02 03 2004 Joe Shmo\n",
+			Want: []hebcal.UserYahrzeit{
+				{Date: date(2004, time.February, 3), Name: "Joe Shmo"},
+			},
+		},
+		{
+			Name: "entries with options",
+			Content: "10 8 1967 after-sunset Joe Shmo\n" +
+				"Adar 15 5785 Jane Doe\n" +
+				"10 8 1967 time=21:05 Left Out\n" +
+				"Adar 15 5785 adar=both Also Left Out",
+			Want: []hebcal.UserYahrzeit{
+				{Date: date(1967, time.October, 9), Name: "Joe Shmo"},
+				{Date: date(2025, time.March, 15), Name: "Jane Doe"},
+			},
+		},
 		{
 			Name:    "invalid line",
 			Content: "INVALID",
@@ -124,3 +144,458 @@ func TestParseYahrzeits(t *testing.T) {
 		})
 	}
 }
+
+func TestParseYahrzeitEntries(t *testing.T) {
+	const fileName = "testYahrzeit.txt"
+	cases := []struct {
+		Name    string
+		Content string
+		WantErr string
+		Want    []hcfiles.Yahrzeit
+	}{
+		{Name: "empty", Content: "", WantErr: "", Want: nil},
+		{
+			Name:    "basic",
+			Content: "02 03 2004 Joe Shmo",
+			WantErr: "",
+			Want: []hcfiles.Yahrzeit{
+				{
+					Date: date(2004, time.February, 3),
+					Name: "Joe Shmo",
+				},
+			},
+		},
+		{
+			Name:    "multiple entries",
+			Content: "02 03 2004 Joe Shmo\n5 6 2001 Jane Doe",
+			WantErr: "",
+			Want: []hcfiles.Yahrzeit{
+				{
+					Date: date(2004, time.February, 3),
+					Name: "Joe Shmo",
+				},
+				{
+					Date: date(2001, time.May, 6),
+					Name: "Jane Doe",
+				},
+			},
+		},
+		{
+			Name:    "comments and blank lines",
+			Content: "# Family\n\n02 03 2004 Joe Shmo\n",
+			Want: []hcfiles.Yahrzeit{
+				{Date: date(2004, time.February, 3), Name: "Joe Shmo"},
+			},
+		},
+		{
+			Name:    "invalid line",
+			Content: "INVALID",
+			WantErr: fmt.Sprintf(
+				"ParseYahrzeits: %v",
+				hcfiles.SyntaxError{
+					Err: fmt.Errorf(
+						"%w: expected 5 capture fields, got 0",
+						hcfiles.ErrInvalidFormat,
+					),
+					FileName:   fileName,
+					LineNumber: 1,
+				},
+			),
+			Want: nil,
+		},
+		{
+			Name:    "invalid lines",
+			Content: "INVALID\nWRONG",
+			WantErr: fmt.Sprintf(
+				"ParseYahrzeits: %v",
+				errors.Join(
+					hcfiles.SyntaxError{
+						Err: fmt.Errorf(
+							"%w: expected 5 capture fields, got 0",
+							hcfiles.ErrInvalidFormat,
+						),
+						FileName:   fileName,
+						LineNumber: 1,
+					},
+					hcfiles.SyntaxError{
+						Err: fmt.Errorf(
+							"%w: expected 5 capture fields, got 0",
+							hcfiles.ErrInvalidFormat,
+						),
+						FileName:   fileName,
+						LineNumber: 2,
+					},
+				),
+			),
+			Want: nil,
+		},
+		{
+			Name:    "invalid month",
+			Content: "13 03 2004 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err:        hcfiles.ErrInvalidMonth,
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+			Want: nil,
+		},
+		{
+			Name:    "invalid day",
+			Content: "2 29 2001 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err:        hcfiles.ErrInvalidDays,
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+			Want: nil,
+		},
+		{
+			Name:    "after sunset",
+			Content: "10 8 1967 after-sunset Joe Shmo",
+			Want: []hcfiles.Yahrzeit{{
+				Date:        date(1967, time.October, 8),
+				AfterSunset: true,
+				Name:        "Joe Shmo",
+			}},
+		},
+		{
+			Name:    "time of death",
+			Content: "10 8 1967 time=9:15pm Joe Shmo",
+			Want: []hcfiles.Yahrzeit{{
+				Date:  date(1967, time.October, 8),
+				Timed: true,
+				Time:  21*time.Hour + 15*time.Minute,
+				Name:  "Joe Shmo",
+			}},
+		},
+		{
+			Name:    "Hebrew date",
+			Content: "Cheshvan 30 5720 day30=1 Jane Doe",
+			Want: []hcfiles.Yahrzeit{{
+				HebrewDate: hdate.New(5720, hdate.Cheshvan, 30),
+				Day30:      hcfiles.Day30Next,
+				Name:       "Jane Doe",
+			}},
+		},
+		{
+			Name:    "two-word Hebrew month",
+			Content: "Adar I 30 5784 day30=29 Ploni 2",
+			Want: []hcfiles.Yahrzeit{{
+				HebrewDate: hdate.New(5784, hdate.Adar1, 30),
+				Day30:      hcfiles.Day30Last,
+				Name:       "Ploni 2",
+			}},
+		},
+		{
+			Name:    "Adar with a number",
+			Content: "Adar 2 15 5784 Jane Doe\nAdar 1 15 5784 Joe Shmo\nAdar 2 5785 Moshe",
+			Want: []hcfiles.Yahrzeit{
+				{HebrewDate: hdate.New(5784, hdate.Adar2, 15), Name: "Jane Doe"},
+				{HebrewDate: hdate.New(5784, hdate.Adar1, 15), Name: "Joe Shmo"},
+				{HebrewDate: hdate.New(5785, hdate.Adar1, 2), Name: "Moshe"},
+			},
+		},
+		{
+			Name:    "Adar custom",
+			Content: "Adar 7 5783 adar=both Moshe\n2 28 2023 ADAR=2 Moshe",
+			Want: []hcfiles.Yahrzeit{
+				{
+					HebrewDate: hdate.New(5783, hdate.Adar1, 7),
+					Adar:       hcfiles.AdarBoth,
+					Name:       "Moshe",
+				},
+				{
+					Date: date(2023, time.February, 28),
+					Adar: hcfiles.AdarII,
+					Name: "Moshe",
+				},
+			},
+		},
+		{
+			Name:    "invalid Hebrew month",
+			Content: "Foo 3 5780 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: %w",
+					hcfiles.ErrInvalidMonth,
+					errors.New(`unknown Hebrew month: "Foo"`),
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid two-word Hebrew month",
+			Content: "Foo Bar 3 5780 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: %w",
+					hcfiles.ErrInvalidMonth,
+					errors.New(`unknown Hebrew month: "Foo Bar"`),
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "Hebrew date without description",
+			Content: "Adar 2 15 5784",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err:        fmt.Errorf("%w: missing description", hcfiles.ErrInvalidFormat),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid Hebrew day",
+			Content: "Cheshvan 30 5721 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: Cheshvan 5721 has no day 30",
+					hcfiles.ErrInvalidDays,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "sunset with Hebrew date",
+			Content: "Nisan 3 5780 after-sunset Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: after-sunset and time= need a Gregorian date",
+					hcfiles.ErrInvalidOption,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "sunset and time",
+			Content: "10 8 1967 after-sunset time=20:00 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: after-sunset and time= are exclusive",
+					hcfiles.ErrInvalidOption,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid time",
+			Content: "10 8 1967 time=25:00 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: time=25:00: invalid time",
+					hcfiles.ErrInvalidOption,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid Adar custom",
+			Content: "10 8 1967 adar=3 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: adar=3: expected 1, 2 or both",
+					hcfiles.ErrInvalidOption,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "invalid day30 custom",
+			Content: "10 8 1967 day30=30 Joe Shmo",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: day30=30: expected 29 or 1",
+					hcfiles.ErrInvalidOption,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+		{
+			Name:    "missing description",
+			Content: "10 8 1967 after-sunset",
+			WantErr: "ParseYahrzeits: " + hcfiles.SyntaxError{
+				Err: fmt.Errorf(
+					"%w: missing description",
+					hcfiles.ErrInvalidFormat,
+				),
+				FileName:   fileName,
+				LineNumber: 1,
+			}.Error(),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			f := strings.NewReader(c.Content)
+			got, err := hcfiles.ParseYahrzeitEntries(f, fileName)
+			test.CheckErr(t, err, c.WantErr)
+			test.CheckSlice(t, "yahrzeits", c.Want, got)
+		})
+	}
+}
+
+func TestYahrzeit_DeathDate(t *testing.T) {
+	// Sunset in New York on October 8, 1967 was at 6:27pm.
+	newYork := zmanim.LookupCity("New York")
+	cases := []struct {
+		Name     string
+		Yahrzeit hcfiles.Yahrzeit
+		Location *zmanim.Location
+		Want     hdate.HDate
+		WantErr  string
+	}{
+		{
+			Name:     "Gregorian",
+			Yahrzeit: hcfiles.Yahrzeit{Date: date(1967, time.October, 8)},
+			Want:     hdate.New(5728, hdate.Tishrei, 4),
+		},
+		{
+			Name: "after sunset",
+			Yahrzeit: hcfiles.Yahrzeit{
+				Date:        date(1967, time.October, 8),
+				AfterSunset: true,
+			},
+			Want: hdate.New(5728, hdate.Tishrei, 5),
+		},
+		{
+			Name: "before sunset",
+			Yahrzeit: hcfiles.Yahrzeit{
+				Date:  date(1967, time.October, 8),
+				Timed: true,
+				Time:  18*time.Hour + 20*time.Minute,
+			},
+			Location: newYork,
+			Want:     hdate.New(5728, hdate.Tishrei, 4),
+		},
+		{
+			Name: "timed after sunset",
+			Yahrzeit: hcfiles.Yahrzeit{
+				Date:  date(1967, time.October, 8),
+				Timed: true,
+				Time:  18*time.Hour + 30*time.Minute,
+			},
+			Location: newYork,
+			Want:     hdate.New(5728, hdate.Tishrei, 5),
+		},
+		{
+			// Sunset was at 6:56pm, on the day that clocks sprang forward.
+			Name: "before sunset on a DST change",
+			Yahrzeit: hcfiles.Yahrzeit{
+				Date:  date(2025, time.March, 9),
+				Timed: true,
+				Time:  18*time.Hour + 30*time.Minute,
+			},
+			Location: newYork,
+			Want:     hdate.FromTime(date(2025, time.March, 9)),
+		},
+		{
+			Name: "timed without location",
+			Yahrzeit: hcfiles.Yahrzeit{
+				Date:  date(1967, time.October, 8),
+				Timed: true,
+				Time:  18 * time.Hour,
+			},
+			WantErr: "a time of death needs a location, to find the sunset",
+		},
+		{
+			Name:     "Hebrew",
+			Yahrzeit: hcfiles.Yahrzeit{HebrewDate: hdate.New(5720, hdate.Cheshvan, 30)},
+			Location: newYork,
+			Want:     hdate.New(5720, hdate.Cheshvan, 30),
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := c.Yahrzeit.DeathDate(c.Location)
+			test.CheckErr(t, err, c.WantErr)
+			test.CheckHDate(t, "death date", c.Want, got)
+		})
+	}
+}
+
+func TestYahrzeitDate_Observed(t *testing.T) {
+	adar := hdate.New(5783, hdate.Adar1, 7)
+	cheshvan := hdate.New(5720, hdate.Cheshvan, 30)
+	cases := []struct {
+		Name     string
+		Schedule hcfiles.YahrzeitDate
+		Year     int
+		Want     []hdate.HDate
+	}{
+		{
+			Name:     "before the first anniversary",
+			Schedule: hcfiles.YahrzeitDate{Death: adar},
+			Year:     5783,
+			Want:     nil,
+		},
+		{
+			Name:     "Adar in a non-leap year",
+			Schedule: hcfiles.YahrzeitDate{Death: adar, Adar: hcfiles.AdarBoth},
+			Year:     5785,
+			Want:     []hdate.HDate{hdate.New(5785, hdate.Adar1, 7)},
+		},
+		{
+			Name:     "Adar I",
+			Schedule: hcfiles.YahrzeitDate{Death: adar},
+			Year:     5784,
+			Want:     []hdate.HDate{hdate.New(5784, hdate.Adar1, 7)},
+		},
+		{
+			Name:     "Adar II",
+			Schedule: hcfiles.YahrzeitDate{Death: adar, Adar: hcfiles.AdarII},
+			Year:     5784,
+			Want:     []hdate.HDate{hdate.New(5784, hdate.Adar2, 7)},
+		},
+		{
+			Name:     "both Adars",
+			Schedule: hcfiles.YahrzeitDate{Death: adar, Adar: hcfiles.AdarBoth},
+			Year:     5784,
+			Want: []hdate.HDate{
+				hdate.New(5784, hdate.Adar1, 7),
+				hdate.New(5784, hdate.Adar2, 7),
+			},
+		},
+		{
+			Name:     "30 Cheshvan after a short first year",
+			Schedule: hcfiles.YahrzeitDate{Death: cheshvan},
+			Year:     5722,
+			Want:     []hdate.HDate{hdate.New(5722, hdate.Cheshvan, 29)},
+		},
+		{
+			Name:     "30 Cheshvan on the 1st",
+			Schedule: hcfiles.YahrzeitDate{Death: cheshvan, Day30: hcfiles.Day30Next},
+			Year:     5722,
+			Want:     []hdate.HDate{hdate.New(5722, hdate.Kislev, 1)},
+		},
+		{
+			Name:     "30 Cheshvan on the 29th",
+			Schedule: hcfiles.YahrzeitDate{Death: cheshvan, Day30: hcfiles.Day30Last},
+			Year:     5722,
+			Want:     []hdate.HDate{hdate.New(5722, hdate.Cheshvan, 29)},
+		},
+		{
+			Name:     "30 Cheshvan in a long year",
+			Schedule: hcfiles.YahrzeitDate{Death: cheshvan, Day30: hcfiles.Day30Last},
+			Year:     5783,
+			Want:     []hdate.HDate{hdate.New(5783, hdate.Cheshvan, 30)},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got := c.Schedule.Observed(c.Year)
+			if len(got) != len(c.Want) {
+				t.Fatalf("want %v, got %v", c.Want, got)
+			}
+			for i := range got {
+				test.CheckHDate(t, fmt.Sprintf("day %d", i), c.Want[i], got[i])
+			}
+		})
+	}
+}
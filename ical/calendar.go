@@ -0,0 +1,200 @@
+package ical
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405"
+)
+
+// Event is a single VEVENT.
+type Event struct {
+	// UID identifies the event across regenerations of the calendar.
+	// See [UID].
+	UID string
+
+	// Summary is the title of the event.
+	Summary string
+
+	// Description is optional longer text about the event.
+	Description string
+
+	// Categories are optional labels for the event.
+	Categories []string
+
+	// Start is when the event occurs.
+	// If AllDay is set, only the calendar date of Start is used.
+	Start time.Time
+
+	// AllDay marks an event lasting the whole day, with no time of day.
+	AllDay bool
+
+	// Alarm, if positive, adds a display reminder
+	// this long before Start.
+	Alarm time.Duration
+}
+
+// Calendar holds the data for a VCALENDAR object.
+type Calendar struct {
+	// ProdID identifies the program which generated the calendar.
+	ProdID string
+
+	// Name is an optional display name for the calendar.
+	Name string
+
+	// TZ sets the time zone for timed events.
+	// Unless it is nil or UTC, a VTIMEZONE is generated for it,
+	// covering the span of the Events.
+	TZ *time.Location
+
+	// Stamp is the creation time of the calendar data.
+	Stamp time.Time
+
+	Events []Event
+}
+
+// WriteTo writes the Calendar to w in iCalendar format.
+func (c Calendar) WriteTo(w io.Writer) (int64, error) {
+	iw := NewWriter(w)
+
+	iw.Begin("VCALENDAR")
+	iw.Prop("VERSION", "2.0")
+	iw.Text("PRODID", c.ProdID)
+	iw.Prop("CALSCALE", "GREGORIAN")
+	if c.Name != "" {
+		iw.Text("X-WR-CALNAME", c.Name)
+	}
+
+	tzid := c.tzid()
+	if tzid != "" {
+		iw.Text("X-WR-TIMEZONE", tzid)
+		first, last := c.span()
+		WriteTimezone(iw, c.TZ, first.AddDate(0, 0, -1), last.AddDate(0, 0, 1))
+	}
+
+	stamp := c.Stamp.UTC().Format(dateTimeFormat) + "Z"
+	for _, e := range c.Events {
+		c.writeEvent(iw, e, stamp, tzid)
+	}
+
+	iw.End("VCALENDAR")
+	return iw.N, iw.Err
+}
+
+// WriteEvent writes a single VEVENT to w.
+// TZID parameters will reference the Calendar's TZ,
+// which must be defined elsewhere in the output.
+func (c Calendar) WriteEvent(w io.Writer, e Event) (int64, error) {
+	iw := NewWriter(w)
+	stamp := c.Stamp.UTC().Format(dateTimeFormat) + "Z"
+	c.writeEvent(iw, e, stamp, c.tzid())
+	return iw.N, iw.Err
+}
+
+// tzid returns the TZID to use for timed events,
+// or "" if times should be written in UTC.
+func (c Calendar) tzid() string {
+	if c.TZ == nil || c.TZ == time.UTC || c.TZ.String() == "UTC" {
+		return ""
+	}
+	return c.TZ.String()
+}
+
+// span returns the times of the first and last events.
+func (c Calendar) span() (first, last time.Time) {
+	for i, e := range c.Events {
+		if i == 0 || e.Start.Before(first) {
+			first = e.Start
+		}
+		if i == 0 || e.Start.After(last) {
+			last = e.Start
+		}
+	}
+	return first, last
+}
+
+func (c Calendar) writeEvent(iw *Writer, e Event, stamp, tzid string) {
+	iw.Begin("VEVENT")
+	iw.Text("UID", e.UID)
+	iw.Prop("DTSTAMP", stamp)
+
+	if e.AllDay {
+		y, m, d := e.Start.Date()
+		day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		iw.Prop("DTSTART", day.Format(dateFormat), "VALUE=DATE")
+		iw.Prop("DTEND", day.AddDate(0, 0, 1).Format(dateFormat), "VALUE=DATE")
+	} else if tzid == "" {
+		iw.Prop("DTSTART", e.Start.UTC().Format(dateTimeFormat)+"Z")
+	} else {
+		iw.Prop(
+			"DTSTART",
+			e.Start.In(c.TZ).Format(dateTimeFormat),
+			"TZID="+paramValue(tzid),
+		)
+	}
+
+	iw.Text("SUMMARY", e.Summary)
+	if e.Description != "" {
+		iw.Text("DESCRIPTION", e.Description)
+	}
+	if len(e.Categories) != 0 {
+		cats := make([]string, len(e.Categories))
+		for i, cat := range e.Categories {
+			cats[i] = EscapeText(cat)
+		}
+		iw.Prop("CATEGORIES", strings.Join(cats, ","))
+	}
+	iw.Prop("TRANSP", "TRANSPARENT")
+
+	if e.Alarm > 0 {
+		iw.Begin("VALARM")
+		iw.Prop("ACTION", "DISPLAY")
+		iw.Text("DESCRIPTION", e.Summary)
+		iw.Prop("TRIGGER", "-"+Duration(e.Alarm))
+		iw.End("VALARM")
+	}
+
+	iw.End("VEVENT")
+}
+
+// Duration formats d as an RFC 5545 DURATION value, like PT1H30M.
+// Negative durations are formatted as their absolute value.
+func Duration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+
+	result := "PT"
+	if h != 0 {
+		result += fmt.Sprintf("%dH", h)
+	}
+	if m != 0 {
+		result += fmt.Sprintf("%dM", m)
+	}
+	if s != 0 || (h == 0 && m == 0) {
+		result += fmt.Sprintf("%dS", s)
+	}
+	return result
+}
+
+// paramValue quotes a parameter value if it contains special characters.
+func paramValue(s string) string {
+	for _, r := range s {
+		switch r {
+		case ';', ':', ',':
+			return `"` + s + `"`
+		}
+	}
+	return s
+}
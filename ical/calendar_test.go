@@ -0,0 +1,182 @@
+package ical_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/ical"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestCalendar_WriteTo(t *testing.T) {
+	phoenix, err := time.LoadLocation("America/Phoenix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stamp := time.Date(2025, time.December, 14, 1, 2, 3, 0, time.UTC)
+	events := []ical.Event{
+		{
+			UID:     "a@test",
+			Summary: "29th of Kislev, 5786",
+			Start:   time.Date(2025, time.December, 19, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+		},
+		{
+			UID:         "b@test",
+			Summary:     "Candle lighting",
+			Description: "Shabbat",
+			Categories:  []string{"LIGHT_CANDLES", "a,b"},
+			Start:       time.Date(2025, time.December, 19, 17, 5, 0, 0, phoenix),
+			Alarm:       10 * time.Minute,
+		},
+	}
+
+	cases := []struct {
+		Name string
+		Cal  ical.Calendar
+		Want string
+	}{
+		{
+			Name: "empty",
+			Cal:  ical.Calendar{ProdID: "-//test//EN", Stamp: stamp},
+			Want: `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//test//EN
+CALSCALE:GREGORIAN
+END:VCALENDAR
+`,
+		},
+		{
+			Name: "UTC",
+			Cal: ical.Calendar{
+				ProdID: "-//test//EN",
+				Name:   "Test",
+				TZ:     time.UTC,
+				Stamp:  stamp,
+				Events: events[1:],
+			},
+			Want: `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//test//EN
+CALSCALE:GREGORIAN
+X-WR-CALNAME:Test
+BEGIN:VEVENT
+UID:b@test
+DTSTAMP:20251214T010203Z
+DTSTART:20251220T000500Z
+SUMMARY:Candle lighting
+DESCRIPTION:Shabbat
+CATEGORIES:LIGHT_CANDLES,a\,b
+TRANSP:TRANSPARENT
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Candle lighting
+TRIGGER:-PT10M
+END:VALARM
+END:VEVENT
+END:VCALENDAR
+`,
+		},
+		{
+			Name: "with time zone",
+			Cal: ical.Calendar{
+				ProdID: "-//test//EN",
+				TZ:     phoenix,
+				Stamp:  stamp,
+				Events: events,
+			},
+			Want: `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//test//EN
+CALSCALE:GREGORIAN
+X-WR-TIMEZONE:America/Phoenix
+BEGIN:VTIMEZONE
+TZID:America/Phoenix
+BEGIN:STANDARD
+DTSTART:20251217T000000
+TZOFFSETFROM:-0700
+TZOFFSETTO:-0700
+TZNAME:MST
+END:STANDARD
+END:VTIMEZONE
+BEGIN:VEVENT
+UID:a@test
+DTSTAMP:20251214T010203Z
+DTSTART;VALUE=DATE:20251219
+DTEND;VALUE=DATE:20251220
+SUMMARY:29th of Kislev\, 5786
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:b@test
+DTSTAMP:20251214T010203Z
+DTSTART;TZID=America/Phoenix:20251219T170500
+SUMMARY:Candle lighting
+DESCRIPTION:Shabbat
+CATEGORIES:LIGHT_CANDLES,a\,b
+TRANSP:TRANSPARENT
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:Candle lighting
+TRIGGER:-PT10M
+END:VALARM
+END:VEVENT
+END:VCALENDAR
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			var buf strings.Builder
+			n, err := c.Cal.WriteTo(&buf)
+			test.CheckErr(t, err, "")
+			want := strings.ReplaceAll(c.Want, "\n", "\r\n")
+			test.CheckString(t, "output", want, buf.String())
+			test.CheckComparable(t, "n", int64(buf.Len()), n)
+		})
+	}
+}
+
+func TestCalendar_WriteEvent(t *testing.T) {
+	cal := ical.Calendar{
+		TZ:    time.FixedZone("Test/Zone", -5*60*60),
+		Stamp: time.Date(2025, time.December, 14, 0, 0, 0, 0, time.UTC),
+	}
+	var buf strings.Builder
+	_, err := cal.WriteEvent(&buf, ical.Event{
+		UID:     "c@test",
+		Summary: "Havdalah",
+		Start:   time.Date(2025, time.December, 20, 18, 0, 0, 0, cal.TZ),
+	})
+	test.CheckErr(t, err, "")
+
+	want := strings.ReplaceAll(`BEGIN:VEVENT
+UID:c@test
+DTSTAMP:20251214T000000Z
+DTSTART;TZID=Test/Zone:20251220T180000
+SUMMARY:Havdalah
+TRANSP:TRANSPARENT
+END:VEVENT
+`, "\n", "\r\n")
+	test.CheckString(t, "output", want, buf.String())
+}
+
+func TestDuration(t *testing.T) {
+	cases := []struct {
+		Input time.Duration
+		Want  string
+	}{
+		{0, "PT0S"},
+		{10 * time.Minute, "PT10M"},
+		{-10 * time.Minute, "PT10M"},
+		{90 * time.Minute, "PT1H30M"},
+		{time.Hour + 5*time.Second, "PT1H5S"},
+		{1500 * time.Millisecond, "PT2S"},
+	}
+	for _, c := range cases {
+		t.Run(c.Input.String(), func(t *testing.T) {
+			test.CheckString(t, "duration", c.Want, ical.Duration(c.Input))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Package ical encodes and decodes calendar data
+// in the iCalendar format described by RFC 5545.
+package ical
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLineOctets is the longest a content line may be, not counting the CRLF,
+// before it must be folded onto a continuation line.
+const MaxLineOctets = 75
+
+// EscapeText escapes s for use as a TEXT property value.
+// Backslashes, semicolons, commas and newlines get backslash escapes.
+func EscapeText(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch r {
+		case '\\', ';', ',':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			// dropped; CRLF gets escaped as a single \n
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FoldLine splits a content line into chunks of at most [MaxLineOctets],
+// without splitting any UTF-8 sequences.
+// Each chunk after the first starts with a single space,
+// which marks it as a continuation of the previous line.
+// The line terminators are not included.
+func FoldLine(line string) []string {
+	if len(line) <= MaxLineOctets {
+		return []string{line}
+	}
+
+	var chunks []string
+	for len(line) > MaxLineOctets {
+		cut := MaxLineOctets
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		chunks = append(chunks, line[:cut])
+		// The continuation space counts against the next chunk's length.
+		line = " " + line[cut:]
+	}
+	return append(chunks, line)
+}
+
+// UID builds a stable unique identifier from the given parts.
+// The same parts always produce the same UID,
+// so that calendar clients update events instead of duplicating them
+// when a feed gets regenerated.
+func UID(domain string, parts ...string) string {
+	h := sha1.New()
+	for _, p := range parts {
+		io.WriteString(h, p)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:20] + "@" + domain
+}
+
+// Writer emits content lines, folding them and adding CRLF terminators.
+// After the first write error, further writes are skipped,
+// and the error is kept in Err.
+type Writer struct {
+	w   io.Writer
+	N   int64
+	Err error
+}
+
+// NewWriter returns a [Writer] which outputs to w.
+func NewWriter(w io.Writer) *Writer { return &Writer{w: w} }
+
+// Line writes a single unfolded content line.
+func (w *Writer) Line(line string) {
+	for _, chunk := range FoldLine(line) {
+		if w.Err != nil {
+			return
+		}
+		var n int
+		n, w.Err = io.WriteString(w.w, chunk+"\r\n")
+		w.N += int64(n)
+	}
+}
+
+// Prop writes a property line with the given name and raw value.
+// params are added as-is, and should have the form `NAME=VALUE`.
+func (w *Writer) Prop(name, value string, params ...string) {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, p := range params {
+		b.WriteByte(';')
+		b.WriteString(p)
+	}
+	b.WriteByte(':')
+	b.WriteString(value)
+	w.Line(b.String())
+}
+
+// Text writes a property line with a TEXT value, escaping it.
+func (w *Writer) Text(name, value string, params ...string) {
+	w.Prop(name, EscapeText(value), params...)
+}
+
+// Begin writes a BEGIN line for the named component.
+func (w *Writer) Begin(component string) { w.Prop("BEGIN", component) }
+
+// End writes an END line for the named component.
+func (w *Writer) End(component string) { w.Prop("END", component) }
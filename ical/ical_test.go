@@ -0,0 +1,121 @@
+package ical_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/ical"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestEscapeText(t *testing.T) {
+	cases := []struct {
+		Input, Want string
+	}{
+		{"", ""},
+		{"Candle lighting", "Candle lighting"},
+		{"Kriat Shema, sof zeman", `Kriat Shema\, sof zeman`},
+		{`a;b\c`, `a\;b\\c`},
+		{"line1\nline2", `line1\nline2`},
+		{"line1\r\nline2", `line1\nline2`},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			test.CheckString(t, "escaped", c.Want, ical.EscapeText(c.Input))
+		})
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	long := strings.Repeat("x", 80)
+	// Each Hebrew letter is 2 bytes in UTF-8.
+	hebrew := "SUMMARY:" + strings.Repeat("ש", 40)
+	cases := []struct {
+		Name  string
+		Input string
+		Want  []string
+	}{
+		{Name: "empty", Input: "", Want: []string{""}},
+		{Name: "short", Input: "BEGIN:VEVENT", Want: []string{"BEGIN:VEVENT"}},
+		{
+			Name:  "exactly max",
+			Input: strings.Repeat("x", ical.MaxLineOctets),
+			Want:  []string{strings.Repeat("x", ical.MaxLineOctets)},
+		},
+		{
+			Name:  "long ASCII",
+			Input: long,
+			Want:  []string{long[:75], " " + long[75:]},
+		},
+		{
+			Name:  "does not split runes",
+			Input: hebrew,
+			Want:  []string{hebrew[:74], " " + hebrew[74:]},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got := ical.FoldLine(c.Input)
+			test.CheckSlice(t, "chunks", c.Want, got)
+			for _, chunk := range got {
+				if len(chunk) > ical.MaxLineOctets {
+					t.Errorf("chunk too long (%d): %q", len(chunk), chunk)
+				}
+			}
+		})
+	}
+}
+
+func TestUID(t *testing.T) {
+	a := ical.UID("example.com", "2025-12-19", "Candle lighting")
+	b := ical.UID("example.com", "2025-12-19", "Candle lighting")
+	c := ical.UID("example.com", "2025-12-19", "Havdalah")
+	// Separators keep parts from running together.
+	d := ical.UID("example.com", "2025-12-19Candle", " lighting")
+
+	if a != b {
+		t.Errorf("UIDs for the same parts differ: %q != %q", a, b)
+	}
+	if a == c || a == d {
+		t.Errorf("UIDs for different parts are the same: %q", a)
+	}
+	test.CheckRegexp(t, "UID", `^[0-9a-f]{20}@example\.com$`, a)
+}
+
+type errWriter struct{ n int }
+
+func (w *errWriter) Write(b []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("test: write failed")
+	}
+	w.n--
+	return len(b), nil
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := ical.NewWriter(&buf)
+	w.Begin("VEVENT")
+	w.Text("SUMMARY", "a, b", "LANGUAGE=en")
+	w.Prop("DTSTART", "20251219", "VALUE=DATE")
+	w.End("VEVENT")
+
+	const want = "BEGIN:VEVENT\r\n" +
+		"SUMMARY;LANGUAGE=en:a\\, b\r\n" +
+		"DTSTART;VALUE=DATE:20251219\r\n" +
+		"END:VEVENT\r\n"
+	test.CheckString(t, "output", want, buf.String())
+	test.CheckComparable(t, "N", int64(len(want)), w.N)
+	test.CheckErr(t, w.Err, "")
+
+	t.Run("write error", func(t *testing.T) {
+		w := ical.NewWriter(&errWriter{n: 1})
+		w.Begin("VCALENDAR")
+		w.Begin("VEVENT")
+		w.End("VEVENT")
+		test.CheckErr(t, w.Err, "test: write failed")
+		test.CheckComparable(t, "N", int64(len("BEGIN:VCALENDAR\r\n")), w.N)
+	})
+}
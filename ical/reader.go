@@ -0,0 +1,145 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prop is a content line read by [ReadProps].
+type Prop struct {
+	// Name is the property name, in upper case, like "DTSTART".
+	Name string
+
+	// Params holds the parameters, like TZID or VALUE,
+	// by their upper-case names. Quotes are removed from their values.
+	Params map[string]string
+
+	// Value is the raw value. TEXT values need [UnescapeText].
+	Value string
+
+	// Line is the line number where the content line starts.
+	Line int
+}
+
+// ReadProps reads the content lines of r, unfolding continuation lines,
+// and parses them into [Prop]s.
+// Blank lines are skipped.
+func ReadProps(r io.Reader) ([]Prop, error) {
+	var (
+		props     []Prop
+		current   strings.Builder
+		startLine int
+	)
+	flush := func() error {
+		if current.Len() == 0 {
+			return nil
+		}
+		prop, err := parseProp(current.String())
+		if err != nil {
+			return fmt.Errorf("line %d: %w", startLine, err)
+		}
+		prop.Line = startLine
+		props = append(props, prop)
+		current.Reset()
+		return nil
+	}
+
+	s := bufio.NewScanner(r)
+	for lineNumber := 1; s.Scan(); lineNumber++ {
+		line := strings.TrimRight(s.Text(), "\r")
+		if line != "" && (line[0] == ' ' || line[0] == '\t') {
+			current.WriteString(line[1:])
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		if line != "" {
+			current.WriteString(line)
+			startLine = lineNumber
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// parseProp parses an unfolded content line, like
+//
+//	DTSTART;TZID="America/New_York":20250101T090000
+func parseProp(line string) (Prop, error) {
+	end := strings.IndexAny(line, ";:")
+	if end <= 0 {
+		return Prop{}, fmt.Errorf("invalid content line %q", line)
+	}
+	prop := Prop{Name: strings.ToUpper(line[:end])}
+	line = line[end:]
+
+	for line[0] == ';' {
+		line = line[1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return Prop{}, fmt.Errorf("invalid parameter in %s", prop.Name)
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			closing := strings.IndexByte(line[1:], '"')
+			if closing < 0 {
+				return Prop{}, fmt.Errorf("unterminated quote in %s", prop.Name)
+			}
+			value, line = line[1:closing+1], line[closing+2:]
+		} else {
+			end := strings.IndexAny(line, ";:")
+			if end < 0 {
+				return Prop{}, fmt.Errorf("missing value of %s", prop.Name)
+			}
+			value, line = line[:end], line[end:]
+		}
+		if prop.Params == nil {
+			prop.Params = make(map[string]string)
+		}
+		prop.Params[name] = value
+
+		if line == "" {
+			return Prop{}, fmt.Errorf("missing value of %s", prop.Name)
+		}
+	}
+
+	if line[0] != ':' {
+		return Prop{}, fmt.Errorf("invalid content line for %s", prop.Name)
+	}
+	prop.Value = line[1:]
+	return prop, nil
+}
+
+// UnescapeText reverses [EscapeText] for a TEXT property value.
+func UnescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
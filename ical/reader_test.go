@@ -0,0 +1,95 @@
+package ical_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/ical"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestReadProps(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Input   string
+		Want    []ical.Prop
+		WantErr string
+	}{
+		{Name: "empty", Input: ""},
+		{
+			Name: "folded lines and params",
+			Input: "BEGIN:VEVENT\r\n" +
+				"\r\n" +
+				"dtstart;VALUE=DATE:20250704\r\n" +
+				"SUMMARY;LANGUAGE=en:Independence\r\n" +
+				"  Day\r\n" +
+				`ATTENDEE;CN="Ploni, Ben";ROLE=CHAIR:mailto:ben@example.com` + "\n" +
+				"END:VEVENT\n",
+			Want: []ical.Prop{
+				{Name: "BEGIN", Value: "VEVENT", Line: 1},
+				{
+					Name:   "DTSTART",
+					Params: map[string]string{"VALUE": "DATE"},
+					Value:  "20250704",
+					Line:   3,
+				},
+				{
+					Name:   "SUMMARY",
+					Params: map[string]string{"LANGUAGE": "en"},
+					Value:  "Independence Day",
+					Line:   4,
+				},
+				{
+					Name:   "ATTENDEE",
+					Params: map[string]string{"CN": "Ploni, Ben", "ROLE": "CHAIR"},
+					Value:  "mailto:ben@example.com",
+					Line:   6,
+				},
+				{Name: "END", Value: "VEVENT", Line: 7},
+			},
+		},
+		{
+			Name:    "missing colon",
+			Input:   "BEGIN:VCALENDAR\nVERSION\n",
+			WantErr: `line 2: invalid content line "VERSION"`,
+		},
+		{
+			Name:    "unterminated quote",
+			Input:   `ATTENDEE;CN="Ploni:mailto:ben@example.com`,
+			WantErr: "line 1: unterminated quote in ATTENDEE",
+		},
+		{
+			Name:    "missing param value",
+			Input:   "DTSTART;VALUE",
+			WantErr: "line 1: invalid parameter in DTSTART",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := ical.ReadProps(strings.NewReader(c.Input))
+			test.CheckErr(t, err, c.WantErr)
+			if !reflect.DeepEqual(c.Want, got) {
+				t.Errorf("props:\nwant %#v\ngot  %#v", c.Want, got)
+			}
+		})
+	}
+}
+
+func TestUnescapeText(t *testing.T) {
+	cases := []struct {
+		Input, Want string
+	}{
+		{"", ""},
+		{"Candle lighting", "Candle lighting"},
+		{`Kriat Shema\, sof zeman`, "Kriat Shema, sof zeman"},
+		{`a\;b\\c`, `a;b\c`},
+		{`line1\nline2\Nline3`, "line1\nline2\nline3"},
+		{`trailing\`, `trailing\`},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			test.CheckString(t, "unescaped", c.Want, ical.UnescapeText(c.Input))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package ical
+
+import (
+	"fmt"
+	"time"
+)
+
+// Transition marks an instant when a time zone's UTC offset changes.
+type Transition struct {
+	// At is the first instant using the new offset.
+	At time.Time
+
+	// Name is the abbreviation used after the transition, like "EDT".
+	Name string
+
+	OffsetFrom int // seconds east of UTC before the transition
+	OffsetTo   int // seconds east of UTC after the transition
+	IsDST      bool
+}
+
+// Transitions lists the offset changes of tz between start and end.
+// Time zone rules are not exposed by the [time] package,
+// so the changes are found by sampling every few hours
+// and then narrowing down to the second.
+func Transitions(tz *time.Location, start, end time.Time) []Transition {
+	const step = 6 * time.Hour
+
+	var result []Transition
+	prev := start.In(tz)
+	_, prevOffset := prev.Zone()
+	for t := prev.Add(step); !t.After(end.Add(step)); t = t.Add(step) {
+		_, offset := t.Zone()
+		if offset == prevOffset {
+			prev = t
+			continue
+		}
+
+		// Binary search for the first second with the new offset.
+		lo, hi := prev, t
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+			if _, midOffset := mid.Zone(); midOffset == prevOffset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		name, _ := hi.Zone()
+		result = append(result, Transition{
+			At:         hi,
+			Name:       name,
+			OffsetFrom: prevOffset,
+			OffsetTo:   offset,
+			IsDST:      hi.IsDST(),
+		})
+
+		prev, prevOffset = t, offset
+	}
+	return result
+}
+
+// WriteTimezone writes a VTIMEZONE component for tz,
+// which is accurate for times between start and end.
+// Each offset change in that span is listed as its own observance,
+// so no recurrence rules are needed.
+func WriteTimezone(iw *Writer, tz *time.Location, start, end time.Time) {
+	iw.Begin("VTIMEZONE")
+	iw.Text("TZID", tz.String())
+
+	// The observance in effect at the start of the span.
+	y, m, d := start.In(tz).Date()
+	first := time.Date(y, m, d, 0, 0, 0, 0, tz)
+	name, offset := first.Zone()
+	writeObservance(iw, Transition{
+		At:         first,
+		Name:       name,
+		OffsetFrom: offset,
+		OffsetTo:   offset,
+		IsDST:      first.IsDST(),
+	})
+
+	for _, tr := range Transitions(tz, start, end) {
+		writeObservance(iw, tr)
+	}
+
+	iw.End("VTIMEZONE")
+}
+
+func writeObservance(iw *Writer, tr Transition) {
+	component := "STANDARD"
+	if tr.IsDST {
+		component = "DAYLIGHT"
+	}
+
+	// DTSTART is expressed in the local time which was in effect
+	// before the transition.
+	local := tr.At.UTC().Add(time.Duration(tr.OffsetFrom) * time.Second)
+
+	iw.Begin(component)
+	iw.Prop("DTSTART", local.Format(dateTimeFormat))
+	iw.Prop("TZOFFSETFROM", UTCOffset(tr.OffsetFrom))
+	iw.Prop("TZOFFSETTO", UTCOffset(tr.OffsetTo))
+	if tr.Name != "" {
+		iw.Text("TZNAME", tr.Name)
+	}
+	iw.End(component)
+}
+
+// UTCOffset formats seconds east of UTC as an RFC 5545 UTC-OFFSET value,
+// like -0500 or +013045.
+func UTCOffset(secs int) string {
+	sign := '+'
+	if secs < 0 {
+		sign = '-'
+		secs = -secs
+	}
+	h, m, s := secs/3600, secs/60%60, secs%60
+	if s != 0 {
+		return fmt.Sprintf("%c%02d%02d%02d", sign, h, m, s)
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, h, m)
+}
@@ -0,0 +1,106 @@
+package ical_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/ical"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestTransitions(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	got := ical.Transitions(ny, start, end)
+	want := []ical.Transition{
+		{
+			At:         time.Date(2026, time.March, 8, 7, 0, 0, 0, time.UTC),
+			Name:       "EDT",
+			OffsetFrom: -5 * 60 * 60,
+			OffsetTo:   -4 * 60 * 60,
+			IsDST:      true,
+		},
+		{
+			At:         time.Date(2026, time.November, 1, 6, 0, 0, 0, time.UTC),
+			Name:       "EST",
+			OffsetFrom: -4 * 60 * 60,
+			OffsetTo:   -5 * 60 * 60,
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("want %d transitions, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if !want[i].At.Equal(got[i].At) {
+			t.Errorf("[%d].At's did not match - want:\n%s\ngot:\n%s",
+				i, want[i].At, got[i].At)
+		}
+		got[i].At = want[i].At
+		test.CheckComparable(t, "transition", want[i], got[i])
+	}
+
+	t.Run("no transitions", func(t *testing.T) {
+		got := ical.Transitions(time.UTC, start, end)
+		if len(got) != 0 {
+			t.Errorf("want no transitions for UTC, got: %+v", got)
+		}
+	})
+}
+
+func TestWriteTimezone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf strings.Builder
+	iw := ical.NewWriter(&buf)
+	ical.WriteTimezone(
+		iw,
+		ny,
+		time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC),
+		time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC),
+	)
+	test.CheckErr(t, iw.Err, "")
+
+	want := strings.ReplaceAll(`BEGIN:VTIMEZONE
+TZID:America/New_York
+BEGIN:DAYLIGHT
+DTSTART:20261001T000000
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0400
+TZNAME:EDT
+END:DAYLIGHT
+BEGIN:STANDARD
+DTSTART:20261101T020000
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0500
+TZNAME:EST
+END:STANDARD
+END:VTIMEZONE
+`, "\n", "\r\n")
+	test.CheckString(t, "output", want, buf.String())
+}
+
+func TestUTCOffset(t *testing.T) {
+	cases := []struct {
+		Input int
+		Want  string
+	}{
+		{0, "+0000"},
+		{2 * 60 * 60, "+0200"},
+		{-5 * 60 * 60, "-0500"},
+		{5*60*60 + 30*60, "+0530"},
+		{-(60*60 + 30*60 + 45), "-013045"},
+	}
+	for _, c := range cases {
+		t.Run(c.Want, func(t *testing.T) {
+			test.CheckString(t, "offset", c.Want, ical.UTCOffset(c.Input))
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package repl
+
+import (
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Complete returns the completions of the last word of line:
+//
+//   - function names, like `hdateFromTime`
+//   - variables, like `$d`
+//   - keys of the data, like `$.z`
+//   - fields and methods of values, like `$d.Gregorian` or `$.z.Sunrise`;
+//     the value before the last dot is computed to find these
+//
+// Each completion is the whole word, and they are sorted.
+func (s *Session) Complete(line string) []string {
+	word := lastWord(line)
+
+	var candidates []string
+	switch dot := strings.LastIndex(word, "."); {
+	case dot >= 0:
+		base, err := s.value(word[:dot])
+		if err != nil {
+			return nil
+		}
+		for _, member := range members(base) {
+			candidates = append(candidates, word[:dot+1]+member)
+		}
+	case strings.HasPrefix(word, "$"):
+		candidates = append(s.Vars(), "$.")
+	default:
+		candidates = s.funcs
+	}
+
+	var result []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			result = append(result, c)
+		}
+	}
+	slices.Sort(result)
+	return slices.Compact(result)
+}
+
+// lastWord returns the variable, field chain or function name
+// at the end of line.
+func lastWord(line string) string {
+	i := len(line)
+	for i > 0 {
+		c := line[i-1]
+		if c != '$' && c != '.' && c != '_' &&
+			!('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			break
+		}
+		i--
+	}
+	return line[i:]
+}
+
+// value computes the pipeline expr after the declarations,
+// and returns the resulting value.
+// An empty expr, or `$`, is the data.
+func (s *Session) value(expr string) (reflect.Value, error) {
+	if expr == "" || expr == "$" {
+		return reflect.ValueOf(s.env.Data), nil
+	}
+
+	var got any
+	capture := func(v any) string {
+		got = v
+		return ""
+	}
+	text := s.prefix() + "{{replCapture (" + expr + ")}}"
+	err := s.run(text, map[string]any{"replCapture": capture}, io.Discard)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(got), nil
+}
+
+// members returns the names which can follow a dot after v in a template:
+// the keys of a map, or the exported fields and methods of other values.
+func members(v reflect.Value) []string {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+
+	var names []string
+	if v.Kind() == reflect.Map {
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		for _, key := range v.MapKeys() {
+			names = append(names, key.String())
+		}
+		return names
+	}
+
+	t := v.Type()
+	for i := range t.NumMethod() {
+		names = append(names, t.Method(i).Name)
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		for _, field := range reflect.VisibleFields(t) {
+			if field.IsExported() && !field.Anonymous {
+				names = append(names, field.Name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,284 @@
+// Package repl evaluates template pipelines interactively, line by line,
+// in the same environment that templates run in.
+// This shortens the edit/run loop while developing templates.
+package repl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+)
+
+// TemplateName names the lines in error messages,
+// and in places like `$.configSource` which expect a template path.
+const TemplateName = "<repl>"
+
+// Prompt is printed before reading each line.
+const Prompt = "> "
+
+// Env is what the lines run in.
+type Env struct {
+	// Tmpl holds the FuncMap, as from [templating.BuildDataText].
+	// It is cloned for each line, and never executed itself.
+	Tmpl *template.Template
+
+	// Data is the `$` of the lines.
+	Data map[string]any
+
+	// Imports is where files imported by the lines are found;
+	// see [templating.ImportFuncs].
+	Imports fs.FS
+}
+
+// Loader builds the [Env] of a [Session].
+// It is called again when the config is reloaded.
+type Loader func() (Env, error)
+
+// Session holds the state of a REPL:
+// the Env, and the lines which declared or assigned variables.
+// Since the values of variables only live while a template runs,
+// these lines are run again before each new line.
+type Session struct {
+	load  Loader
+	env   Env
+	funcs []string
+
+	decls []string // the declaring and assigning lines
+	vars  []string // the names of the variables, in order of declaration
+}
+
+// New returns a Session in the Env from load.
+func New(load Loader) (*Session, error) {
+	env, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{load: load, env: env, funcs: templating.FuncNames()}, nil
+}
+
+// declRe matches lines which declare or assign a variable,
+// like `$d := hdateFromTime $.now` or `$d = $d.Next`,
+// capturing the name of the variable and the operator.
+var declRe = regexp.MustCompile(`^\$(\w+)\s*(:?=)`)
+
+// errRe matches the position prefix of template errors,
+// capturing the line number and any column.
+var errRe = regexp.MustCompile(
+	`^template: ` + regexp.QuoteMeta(TemplateName) + `:(\d+):(?:(\d+):)? `)
+
+// Error is a problem with a line.
+// If the column of the problem is known, Col is its 1-based index in Line.
+type Error struct {
+	Line string
+	Col  int
+	Err  error
+}
+
+// Error shows the line with a caret under the column of the problem,
+// followed by the message.
+func (e Error) Error() string {
+	if e.Col < 1 {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s\n%s^\n%v", e.Line, strings.Repeat(" ", e.Col-1), e.Err)
+}
+
+func (e Error) Unwrap() error { return e.Err }
+
+// Eval runs line, and returns what it printed.
+//
+// A line is a pipeline, like `hdateFromTime $.now`,
+// unless it contains `{{`, in which case it is template text.
+// A pipeline which declares or assigns a variable, like `$d := $.now`,
+// prints nothing, and the variable is kept for later lines.
+func (s *Session) Eval(line string) (string, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", nil
+	}
+
+	text, offset := s.wrap(line)
+	var out bytes.Buffer
+	if err := s.run(text, nil, &out); err != nil {
+		return "", s.lineError(line, offset, err)
+	}
+
+	if m := declRe.FindStringSubmatch(line); m != nil && offset != 0 {
+		s.decls = append(s.decls, line)
+		if m[2] == ":=" && !slices.Contains(s.vars, "$"+m[1]) {
+			s.vars = append(s.vars, "$"+m[1])
+		}
+	}
+	return out.String(), nil
+}
+
+// wrap returns the template text which runs line after the declarations,
+// and the offset of line in the action around it, if any.
+func (s *Session) wrap(line string) (string, int) {
+	if strings.Contains(line, "{{") {
+		return s.prefix() + line, 0
+	}
+	return s.prefix() + "{{" + line + "}}", len("{{")
+}
+
+// prefix returns the template text which redeclares the variables,
+// each on its own line, printing nothing.
+func (s *Session) prefix() string {
+	var b strings.Builder
+	for _, decl := range s.decls {
+		fmt.Fprintf(&b, "{{- %s -}}\n", decl)
+	}
+	return b.String()
+}
+
+// run parses and executes text in a clone of the Env,
+// with the extra funcs, if any.
+func (s *Session) run(text string, funcs template.FuncMap, w io.Writer) error {
+	tmpl, err := s.env.Tmpl.Clone()
+	if err != nil {
+		return err
+	}
+	if funcs != nil {
+		tmpl = tmpl.Funcs(funcs)
+	}
+	if _, err := templating.ParseText(s.env.Imports, tmpl, text); err != nil {
+		return err
+	}
+	return tmpl.Execute(w, s.env.Data)
+}
+
+// lineError points err at the column of line where it happened,
+// if it happened in line rather than in a declaration or an import.
+// offset is the position of line in the text which ran.
+func (s *Session) lineError(line string, offset int, err error) error {
+	msg := err.Error()
+	m := errRe.FindStringSubmatchIndex(msg)
+	if m == nil {
+		return err
+	}
+	lineNum, _ := strconv.Atoi(msg[m[2]:m[3]])
+	if lineNum != len(s.decls)+1 {
+		return err
+	}
+
+	// The name of the template says nothing here.
+	rest := strings.TrimPrefix(msg[m[1]:], fmt.Sprintf("executing %q ", TemplateName))
+	e := Error{Line: line, Err: errors.New(rest)}
+	if m[4] >= 0 {
+		col, _ := strconv.Atoi(msg[m[4]:m[5]])
+		e.Col = col - offset + 1
+	}
+	return e
+}
+
+// Vars returns the names of the variables which were declared,
+// like `$d`, in order of declaration.
+func (s *Session) Vars() []string {
+	return slices.Clone(s.vars)
+}
+
+// Reload builds a new Env with the Loader, like after editing the config,
+// and runs the declarations again in it.
+// Declarations which now fail are dropped, and reported in the error.
+// If the Loader fails, the Session is unchanged.
+func (s *Session) Reload() error {
+	env, err := s.load()
+	if err != nil {
+		return err
+	}
+	s.env = env
+
+	decls := s.decls
+	s.decls, s.vars = nil, nil
+	var errs []error
+	for _, decl := range decls {
+		if _, err := s.Eval(decl); err != nil {
+			errs = append(errs, fmt.Errorf("dropped %s: %w", decl, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run reads lines from r, and writes their results to w,
+// until r ends or the :quit command.
+// Problems with lines are written to w, rather than ending the loop.
+// See [Help] for the commands.
+func (s *Session) Run(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(w, Prompt)
+		if !scanner.Scan() {
+			fmt.Fprintln(w)
+			return scanner.Err()
+		}
+		line := scanner.Text()
+
+		// A tab typed at the end of the line asks for completions.
+		if before, ok := strings.CutSuffix(line, "\t"); ok {
+			writeLines(w, s.Complete(before))
+			continue
+		}
+
+		cmd, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch cmd {
+		case ":quit", ":q":
+			return nil
+		case ":help":
+			fmt.Fprintln(w, Help)
+		case ":vars":
+			writeLines(w, s.Vars())
+		case ":complete":
+			writeLines(w, s.Complete(arg))
+		case ":reload":
+			if err := s.Reload(); err != nil {
+				fmt.Fprintln(w, err)
+			} else {
+				fmt.Fprintln(w, "reloaded")
+			}
+		default:
+			if strings.HasPrefix(cmd, ":") {
+				fmt.Fprintf(w, "unknown command %s; for the commands, type :help\n", cmd)
+				continue
+			}
+			out, err := s.Eval(line)
+			if err != nil {
+				fmt.Fprintln(w, err)
+				continue
+			}
+			fmt.Fprint(w, out)
+			if out != "" && !strings.HasSuffix(out, "\n") {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+}
+
+// writeLines writes each line to w.
+func writeLines(w io.Writer, lines []string) {
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// Help describes how to use the REPL.
+const Help = `Enter a pipeline, like hdateFromTime $.now, to print its value.
+Variables declared like $d := hdateFromTime $.now are kept for later lines.
+Lines with {{ are template text, like {{range 3}}{{.}} {{end}}.
+End a line with a tab to list the completions of its last word.
+
+COMMANDS:
+  :complete text  list the completions of the last word of text
+  :vars           list the variables
+  :reload         reload the config files, and declare the variables again
+  :help           show this help
+  :quit           exit, like the end of the input`
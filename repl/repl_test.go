@@ -0,0 +1,188 @@
+package repl_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/repl"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+// newSession returns a Session in Jerusalem on 2025-12-21,
+// whose Loader fails once loadErr is set.
+func newSession(t *testing.T, loadErr *error) *repl.Session {
+	t.Helper()
+	files := fstest.MapFS{
+		"lib.tmpl": &fstest.MapFile{Data: []byte(`{{define "greet"}}Shalom, {{.}}{{end}}`)},
+	}
+	city := "Jerusalem"
+	load := func() (repl.Env, error) {
+		if loadErr != nil && *loadErr != nil {
+			return repl.Env{}, *loadErr
+		}
+		cfg := &config.Config{
+			City: city,
+			Now:  time.Date(2025, 12, 21, 0, 0, 0, 0, time.UTC),
+		}
+		tmpl, data, err := templating.BuildDataText(cfg, files, repl.TemplateName, "")
+		if err != nil {
+			return repl.Env{}, err
+		}
+		// Each load moves to another city, to show what :reload does.
+		city = "Phoenix"
+		return repl.Env{Tmpl: tmpl, Data: data, Imports: files}, nil
+	}
+
+	s, err := repl.New(load)
+	test.CheckErr(t, err, "")
+	return s
+}
+
+func TestSession_Eval(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Lines []string
+		Want  string
+		Err   string
+	}{
+		{Name: "empty", Lines: []string{"  "}},
+		{Name: "pipeline", Lines: []string{"hdateFromTime $.now"}, Want: "1 Tevet 5786"},
+		{Name: "data", Lines: []string{"$.location.Name"}, Want: "Jerusalem"},
+		{
+			Name:  "variables",
+			Lines: []string{"$d := hdateFromTime $.now", "$d = $d.Next", "$d"},
+			Want:  "2 Tevet 5786",
+		},
+		{
+			Name:  "template text",
+			Lines: []string{"$n := 3", "{{range $n}}{{.}} {{end}}"},
+			Want:  "0 1 2 ",
+		},
+		{Name: "import", Lines: []string{`{{import "lib.tmpl"}}{{template "greet" "world"}}`}, Want: "Shalom, world"},
+		{
+			Name:  "execution error",
+			Lines: []string{"$d := 5", "hdateFromTime $d"},
+			Err:   "hdateFromTime $d\n              ^\nat <$d>: wrong type for value; expected time.Time; got int",
+		},
+		{
+			Name:  "parse error",
+			Lines: []string{"nosuch 1"},
+			Err:   `function "nosuch" not defined`,
+		},
+		{
+			Name:  "undeclared variable",
+			Lines: []string{"$x"},
+			Err:   "undefined variable \"$x\"",
+		},
+		{
+			Name:  "failed declaration is forgotten",
+			Lines: []string{"$d := nosuch", "$d := 1", "$d"},
+			Want:  "1",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.Logger(t)
+			s := newSession(t, nil)
+
+			var got string
+			var err error
+			// Only the last line is checked;
+			// the lines before it set up the variables.
+			for _, line := range c.Lines {
+				got, err = s.Eval(line)
+			}
+			test.CheckErr(t, err, c.Err)
+			test.CheckString(t, "output", c.Want, got)
+		})
+	}
+}
+
+func TestSession_Complete(t *testing.T) {
+	test.Logger(t)
+	s := newSession(t, nil)
+	for _, line := range []string{"$d := hdateFromTime $.now", "$day := 1"} {
+		_, err := s.Eval(line)
+		test.CheckErr(t, err, "")
+	}
+
+	cases := []struct {
+		Line string
+		Want []string
+	}{
+		{Line: "hdateFromT", Want: []string{"hdateFromTime"}},
+		{Line: "prin", Want: []string{"print", "printf", "println"}},
+		{Line: "hdateFromTime $", Want: []string{"$.", "$d", "$day"}},
+		{Line: "$da", Want: []string{"$day"}},
+		{Line: "$.lo", Want: []string{"$.location"}},
+		{Line: "$.location.Na", Want: []string{"$.location.Name"}},
+		{Line: "($.z.Sunr", Want: []string{"$.z.Sunrise", "$.z.SunriseOffset"}},
+		{Line: "$d.Gregorian.Wee", Want: []string{"$d.Gregorian.Weekday"}},
+		{Line: "$d.Mo", Want: []string{"$d.Month", "$d.MonthName"}},
+		{Line: "$nosuch.", Want: nil},
+		{Line: "nosuchfunc", Want: nil},
+	}
+	for _, c := range cases {
+		t.Run(c.Line, func(t *testing.T) {
+			test.CheckSlice(t, "completions", c.Want, s.Complete(c.Line))
+		})
+	}
+}
+
+func TestSession_Reload(t *testing.T) {
+	test.Logger(t)
+	var loadErr error
+	s := newSession(t, &loadErr)
+	for _, line := range []string{"$city := $.location.Name", "$n := 1"} {
+		_, err := s.Eval(line)
+		test.CheckErr(t, err, "")
+	}
+
+	test.CheckErr(t, s.Reload(), "")
+	got, err := s.Eval("$city")
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "city after reload", "Phoenix", got)
+	test.CheckSlice(t, "vars", []string{"$city", "$n"}, s.Vars())
+
+	loadErr = errors.New("broken config")
+	test.CheckErr(t, s.Reload(), "broken config")
+	got, err = s.Eval("$n")
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "n after failed reload", "1", got)
+}
+
+func TestSession_Run(t *testing.T) {
+	test.Logger(t)
+	s := newSession(t, nil)
+	input := strings.Join([]string{
+		"$d := hdateFromTime $.now",
+		"$d",
+		"$d.Gregorian.Weekd\t",
+		":complete hdateFromT",
+		":vars",
+		"nosuch",
+		":nosuch",
+		":reload",
+		":quit",
+		"not run",
+	}, "\n")
+
+	var buf bytes.Buffer
+	err := s.Run(strings.NewReader(input), &buf)
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "output", "> "+
+		"> 1 Tevet 5786\n"+
+		"> $d.Gregorian.Weekday\n"+
+		"> hdateFromTime\n"+
+		"> $d\n"+
+		"> function \"nosuch\" not defined\n"+
+		"> unknown command :nosuch; for the commands, type :help\n"+
+		"> reloaded\n"+
+		"> ", buf.String())
+}
@@ -0,0 +1,351 @@
+// Package server renders hebcalfmt templates in response to HTTP requests.
+package server
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/daterange"
+	"github.com/chaimleib/hebcalfmt/fsys"
+	"github.com/chaimleib/hebcalfmt/templating"
+)
+
+// TemplateExt is the file extension which request paths are mapped to.
+// A request for /zmanim.html is served by the template zmanim.html.tmpl.
+const TemplateExt = ".tmpl"
+
+// DateParam is the query parameter holding the date range spec,
+// with the arguments separated by spaces, like `date=12+19+2025`.
+// All other query parameters are config keys, as accepted by [config.Config.Set],
+// which must be among the [QueryKeys],
+// or else parameters declared in the front matter of the template,
+// like `--param` on the command line.
+const DateParam = "date"
+
+// QueryKeys are the config keys which query parameters may set.
+// Keys naming files, like `events_file` and `template_path`, are left out,
+// so that clients cannot make the server read files of their choosing.
+var QueryKeys = []string{
+	"language", "city", "geo", "geo.lat", "geo.lon", "timezone", "shiurim",
+	"today", "chag_only", "no_julian", "hour24", "sunrise_sunset",
+	"candle_lighting", "daily_zmanim", "molad", "weekly_abbreviated",
+	"add_hebrew_dates", "add_hebrew_dates_for_events", "is_hebrew_year",
+	"yom_kippur_katan", "shabbat_mevarchim", "no_holidays", "no_rosh_chodesh",
+	"il", "no_modern", "no_minor_fast", "no_special_shabbat",
+	"omer", "sedrot", "daily_sedra",
+	"candle_lighting_mins", "havdalah_mins", "havdalah_deg", "num_years",
+}
+
+// ProfileParam is the query parameter selecting a named profile
+// from the config, like `profile=israel`.
+// It is applied before the other query parameters.
+const ProfileParam = "profile"
+
+// FixedMaxAge is how long clients may cache a response
+// for a date range which was given explicitly.
+const FixedMaxAge = 24 * time.Hour
+
+// ContentTypes maps template extensions to the Content-Type header to use.
+// Extensions not listed here fall back to [mime.TypeByExtension],
+// and then to plain text.
+var ContentTypes = map[string]string{
+	".csv":  "text/csv; charset=utf-8",
+	".htm":  "text/html; charset=utf-8",
+	".html": "text/html; charset=utf-8",
+	".ics":  "text/calendar; charset=utf-8",
+	".json": "application/json",
+	".md":   "text/markdown; charset=utf-8",
+	".txt":  "text/plain; charset=utf-8",
+	".xml":  "application/xml; charset=utf-8",
+}
+
+// Server is an [http.Handler] which executes the templates in Files.
+//
+// Templates are read and parsed again on every request,
+// so edits to the files take effect without restarting the server.
+type Server struct {
+	// Files holds the templates to serve.
+	Files fs.FS
+
+	// Config holds the base settings.
+	// Query parameters override these on a per-request basis.
+	Config *config.Config
+
+	// BaseLayers names the layers of Config, like the system config file,
+	// which the config in the front matter of a template overrides,
+	// along with the defaults.
+	// See [config.Config.MergeJSONUnder].
+	BaseLayers []string
+
+	// Now returns the current time. If nil, [time.Now] is used.
+	Now func() time.Time
+
+	// BuildData loads and parses a template,
+	// like [templating.BuildData].
+	BuildData func(
+		cfg *config.Config,
+		files fs.FS,
+		tmplPath string,
+	) (*template.Template, map[string]any, error)
+}
+
+// httpError is an error with an HTTP status code.
+type httpError struct {
+	Code int
+	Err  error
+}
+
+func (e httpError) Error() string { return e.Err.Error() }
+func (e httpError) Unwrap() error { return e.Err }
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlPath, tmplPath, ok := TemplatePath(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	cfg, err := s.RequestConfig(r, tmplPath)
+	if err != nil {
+		writeError(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	body, err := s.render(cfg, tmplPath)
+	if err != nil {
+		writeError(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", ContentType(urlPath))
+	h.Set("Cache-Control", CacheControl(cfg))
+	h.Set("ETag", ETag(cfg.DateRange, body))
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(body))
+}
+
+// writeError responds with the message of err,
+// and the status code of an httpError in it, or else code.
+// Server errors are logged.
+func writeError(w http.ResponseWriter, r *http.Request, err error, code int) {
+	if he := (httpError{}); errors.As(err, &he) {
+		code = he.Code
+	}
+	if code == http.StatusInternalServerError {
+		log.Printf("%s %s: %v", r.Method, r.URL, err)
+	}
+	http.Error(w, err.Error(), code)
+}
+
+// render executes the template at tmplPath into a buffer,
+// so that errors can be reported before anything is sent.
+func (s *Server) render(cfg *config.Config, tmplPath string) ([]byte, error) {
+	tmpl, data, err := s.BuildData(cfg, s.Files, tmplPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RequestConfig copies the base Config and applies the query parameters
+// of r to it, including the profile and the date range spec.
+// If a parameter is repeated, the last value is used.
+//
+// Unless tmplPath is empty, the config in the front matter
+// of the template at tmplPath goes beneath the base Config,
+// above its BaseLayers, like on the command line.
+// Paths to secondary files which it sets are relative to the template.
+// Query parameters which are not config keys
+// set the parameters which the template declares, in cfg.Params.
+func (s *Server) RequestConfig(r *http.Request, tmplPath string) (*config.Config, error) {
+	cfg := *s.Config
+	cfg.Now = time.Now()
+	if s.Now != nil {
+		cfg.Now = s.Now()
+	}
+
+	var fm templating.FrontMatter
+	if tmplPath != "" {
+		var err error
+		if fm, err = s.mergeTemplateConfig(&cfg, tmplPath); err != nil {
+			return nil, err
+		}
+	}
+
+	query := r.URL.Query()
+	if name := query.Get(ProfileParam); name != "" {
+		withProfile, err := cfg.WithProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		cfg = *withProfile
+	}
+
+	cfg.Params = maps.Clone(cfg.Params)
+	for key, values := range query {
+		if key == DateParam || key == ProfileParam {
+			continue
+		}
+		value := values[len(values)-1]
+		isParam := func(p templating.Param) bool { return p.Name == key }
+		switch {
+		case slices.Contains(QueryKeys, key):
+			if err := cfg.SetFrom(config.SourceQuery, key, value); err != nil {
+				return nil, err
+			}
+		case slices.ContainsFunc(fm.Params, isParam):
+			if cfg.Params == nil {
+				cfg.Params = make(map[string]string)
+			}
+			cfg.Params[key] = value
+		default:
+			// Report unknown keys as such, and known keys as not allowed.
+			if err := new(config.Config).Set(key, value); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("config key %q cannot be set by a query parameter", key)
+		}
+	}
+
+	if _, err := fm.ParamValues(cfg.Params); err != nil {
+		return nil, err
+	}
+
+	normalized, err := cfg.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	cfg = *normalized
+
+	args := strings.Fields(query.Get(DateParam))
+	dr, err := daterange.FromArgs(args, cfg.IsHebrewYear, cfg.Now)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DateRange = dr
+	if cfg.Today && dr.Source.Defaulted() {
+		cfg.DateRange = daterange.FromTime(cfg.Now)
+	}
+
+	// Catch bad settings here, so they get reported as client errors.
+	if _, err := cfg.CalOptions(); err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
+
+// mergeTemplateConfig applies the config in the front matter
+// of the template at tmplPath beneath the base layers of cfg,
+// and returns the front matter.
+// Problems with the template are server errors,
+// except that a missing template is not found.
+func (s *Server) mergeTemplateConfig(
+	cfg *config.Config,
+	tmplPath string,
+) (templating.FrontMatter, error) {
+	fm, err := templating.ReadFrontMatter(s.Files, tmplPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fm, httpError{http.StatusNotFound, err}
+	}
+	if err != nil {
+		return fm, httpError{http.StatusInternalServerError, err}
+	}
+	if len(fm.Config) == 0 {
+		return fm, nil
+	}
+
+	err = cfg.MergeJSONUnder(bytes.NewReader(fm.Config), tmplPath, s.BaseLayers...)
+	if err != nil {
+		return fm, httpError{
+			http.StatusInternalServerError,
+			fmt.Errorf("failed to load config: %w", err),
+		}
+	}
+	cfg.SetLayerFS(tmplPath, fsys.WrapFS{FS: s.Files, BaseDir: path.Dir(tmplPath)})
+	return fm, nil
+}
+
+// TemplatePath maps a URL path to the path of a template file.
+// Paths ending in a slash are served by an index.html template.
+// It returns false if the path is not valid for an [fs.FS].
+func TemplatePath(urlPath string) (cleanPath, tmplPath string, ok bool) {
+	cleanPath = path.Clean("/" + urlPath)
+	if strings.HasSuffix(urlPath, "/") {
+		cleanPath = path.Join(cleanPath, "index.html")
+	}
+	cleanPath = strings.TrimPrefix(cleanPath, "/")
+	if cleanPath == "" || !fs.ValidPath(cleanPath) {
+		return "", "", false
+	}
+	return cleanPath, cleanPath + TemplateExt, true
+}
+
+// ContentType infers the Content-Type from the extension of urlPath.
+func ContentType(urlPath string) string {
+	ext := strings.ToLower(path.Ext(urlPath))
+	if ct, ok := ContentTypes[ext]; ok {
+		return ct
+	}
+	if ct := mime.TypeByExtension(ext); ext != "" && ct != "" {
+		return ct
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// CacheControl decides how long a response may be cached.
+// If no date range was given in the request,
+// the result depends on today's date,
+// so it expires at the next midnight in the configured location.
+// Otherwise, it expires after [FixedMaxAge].
+func CacheControl(cfg *config.Config) string {
+	maxAge := FixedMaxAge
+	if cfg.DateRange == nil || len(cfg.DateRange.Source.Args) == 0 {
+		now := cfg.Now
+		if loc, err := cfg.Location(); err == nil {
+			if tz, err := time.LoadLocation(loc.TimeZoneId); err == nil {
+				now = now.In(tz)
+			}
+		}
+		y, m, d := now.Date()
+		midnight := time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+		maxAge = midnight.Sub(now)
+	}
+	return fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+}
+
+// ETag builds a strong entity tag from the date range and the response body.
+func ETag(dr *daterange.DateRange, body []byte) string {
+	h := sha1.New()
+	if dr != nil {
+		fmt.Fprintf(h, "%s..%s", dr.Start(false), dr.End(false))
+	}
+	h.Write([]byte{0})
+	h.Write(body)
+	return `"` + hex.EncodeToString(h.Sum(nil))[:20] + `"`
+}
@@ -0,0 +1,404 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/daterange"
+	"github.com/chaimleib/hebcalfmt/server"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func newServer(files fs.FS) *server.Server {
+	cfg := config.Default
+	cfg.Language = "en"
+	cfg.Profiles = map[string]json.RawMessage{
+		"phoenix": json.RawMessage(`{"city": "Phoenix"}`),
+	}
+	return &server.Server{
+		Files:  files,
+		Config: &cfg,
+		Now: func() time.Time {
+			return time.Date(2025, time.December, 14, 12, 0, 0, 0, time.UTC)
+		},
+		BuildData: templating.BuildData,
+	}
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	fdata := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"date.txt.tmpl":        fdata(`{{$.dateRange.StartOrToday false}} {{$.language}}`),
+		"city.json.tmpl":       fdata(`{"city": "{{$.location.Name}}"}`),
+		"sub/index.html.tmpl":  fdata(`<p>index</p>`),
+		"executeError.md.tmpl": fdata(`{{printf $.tz "INVALID FORMAT"}}`),
+		"parseError.tmpl.tmpl": fdata(`{{INVALID`),
+		"noext.tmpl":           fdata(`plain`),
+		"front.json.tmpl": fdata("{{/*---\nconfig:\n  city: Jerusalem\n---*/}}" +
+			`{"city": "{{$.location.Name}}"}`),
+		"frontError.txt.tmpl": fdata("{{/*---\nconfig:\n  city: [1]\n---*/}}"),
+		"greet.txt.tmpl": fdata("{{/*---\nparams:\n" +
+			"- {name: greeting, default: hello}\n" +
+			"- {name: times, type: int, default: 1}\n" +
+			"---*/}}{{$.params.greeting}} {{$.params.times}}"),
+	}
+
+	cases := []struct {
+		Name            string
+		Method          string
+		Target          string
+		WantCode        int
+		Want            string
+		WantMode        test.WantMode
+		WantContentType string
+		WantCache       string
+	}{
+		{
+			Name:            "defaults",
+			Target:          "/date.txt",
+			WantCode:        http.StatusOK,
+			Want:            "24 Kislev 5786 en",
+			WantContentType: "text/plain; charset=utf-8",
+			// Expires at midnight in New York.
+			WantCache: "public, max-age=61200",
+		},
+		{
+			Name:            "date and language",
+			Target:          "/date.txt?date=12+19+2025&language=he",
+			WantCode:        http.StatusOK,
+			Want:            "29 Kislev 5786 he",
+			WantContentType: "text/plain; charset=utf-8",
+			WantCache:       "public, max-age=86400",
+		},
+		{
+			Name:            "today",
+			Target:          "/date.txt?today=true",
+			WantCode:        http.StatusOK,
+			Want:            "24 Kislev 5786 en",
+			WantContentType: "text/plain; charset=utf-8",
+			WantCache:       "public, max-age=61200",
+		},
+		{
+			Name:            "city",
+			Target:          "/city.json?city=Phoenix",
+			WantCode:        http.StatusOK,
+			Want:            `{"city": "Phoenix"}`,
+			WantContentType: "application/json",
+			// Expires at midnight in Phoenix.
+			WantCache: "public, max-age=68400",
+		},
+		{
+			Name:            "profile",
+			Target:          "/city.json?profile=phoenix",
+			WantCode:        http.StatusOK,
+			Want:            `{"city": "Phoenix"}`,
+			WantContentType: "application/json",
+		},
+		{
+			Name:            "profile with override",
+			Target:          "/city.json?city=Jerusalem&profile=phoenix",
+			WantCode:        http.StatusOK,
+			Want:            `{"city": "Jerusalem"}`,
+			WantContentType: "application/json",
+		},
+		{
+			Name:            "front matter config",
+			Target:          "/front.json",
+			WantCode:        http.StatusOK,
+			Want:            `{"city": "Jerusalem"}`,
+			WantContentType: "application/json",
+		},
+		{
+			Name:            "query over front matter config",
+			Target:          "/front.json?city=Phoenix",
+			WantCode:        http.StatusOK,
+			Want:            `{"city": "Phoenix"}`,
+			WantContentType: "application/json",
+		},
+		{
+			Name:            "profile over front matter config",
+			Target:          "/front.json?profile=phoenix",
+			WantCode:        http.StatusOK,
+			Want:            `{"city": "Phoenix"}`,
+			WantContentType: "application/json",
+		},
+		{
+			Name:     "front matter config error",
+			Target:   "/frontError.txt",
+			WantCode: http.StatusInternalServerError,
+			Want: "front matter of frontError.txt.tmpl: config: json: " +
+				"cannot unmarshal array into Go struct field Config.city of type string\n",
+		},
+		{
+			Name:     "param defaults",
+			Target:   "/greet.txt",
+			WantCode: http.StatusOK,
+			Want:     "hello 1",
+		},
+		{
+			Name:     "params",
+			Target:   "/greet.txt?greeting=yo&times=2&times=3",
+			WantCode: http.StatusOK,
+			Want:     "yo 3",
+		},
+		{
+			Name:     "invalid param",
+			Target:   "/greet.txt?times=INVALID",
+			WantCode: http.StatusBadRequest,
+			Want:     "invalid value for parameter times: ",
+			WantMode: test.WantPrefix,
+		},
+		{
+			Name:     "undeclared param",
+			Target:   "/date.txt?greeting=yo",
+			WantCode: http.StatusBadRequest,
+			Want:     "unknown config key: \"greeting\"\n",
+		},
+		{
+			Name:     "unknown profile",
+			Target:   "/city.json?profile=INVALID",
+			WantCode: http.StatusBadRequest,
+			Want:     "unknown profile: \"INVALID\"\n",
+		},
+		{
+			Name:            "index",
+			Target:          "/sub/",
+			WantCode:        http.StatusOK,
+			Want:            "<p>index</p>",
+			WantContentType: "text/html; charset=utf-8",
+		},
+		{
+			Name:            "no extension",
+			Target:          "/noext",
+			WantCode:        http.StatusOK,
+			Want:            "plain",
+			WantContentType: "text/plain; charset=utf-8",
+		},
+		{
+			Name:     "HEAD",
+			Method:   http.MethodHead,
+			Target:   "/noext",
+			WantCode: http.StatusOK,
+		},
+		{
+			Name:     "method not allowed",
+			Method:   http.MethodPost,
+			Target:   "/noext",
+			WantCode: http.StatusMethodNotAllowed,
+			Want:     "method not allowed\n",
+		},
+		{
+			Name:     "root",
+			Target:   "/",
+			WantCode: http.StatusNotFound,
+			Want:     "open index.html.tmpl: file does not exist\n",
+		},
+		{
+			Name:     "missing",
+			Target:   "/missing.txt",
+			WantCode: http.StatusNotFound,
+			Want:     "open missing.txt.tmpl: file does not exist\n",
+		},
+		{
+			Name:     "unknown config key",
+			Target:   "/date.txt?INVALID=1",
+			WantCode: http.StatusBadRequest,
+			Want:     "unknown config key: \"INVALID\"\n",
+		},
+		{
+			Name:     "events file",
+			Target:   "/date.txt?date=2025&events_file=/etc/passwd",
+			WantCode: http.StatusBadRequest,
+			Want:     "config key \"events_file\" cannot be set by a query parameter\n",
+		},
+		{
+			Name:     "yahrzeits file",
+			Target:   "/date.txt?yahrzeits_file=/etc/passwd",
+			WantCode: http.StatusBadRequest,
+			Want:     "config key \"yahrzeits_file\" cannot be set by a query parameter\n",
+		},
+		{
+			Name:     "template path",
+			Target:   "/date.txt?template_path=/",
+			WantCode: http.StatusBadRequest,
+			Want:     "config key \"template_path\" cannot be set by a query parameter\n",
+		},
+		{
+			Name:     "invalid date",
+			Target:   "/date.txt?date=13+2025",
+			WantCode: http.StatusBadRequest,
+			Want:     "invalid month: 13\n",
+			WantMode: test.WantPrefix,
+		},
+		{
+			Name:     "invalid language",
+			Target:   "/date.txt?language=INVALID",
+			WantCode: http.StatusBadRequest,
+			Want:     "unknown language: \"INVALID\"\n",
+		},
+		{
+			Name:     "invalid city",
+			Target:   "/date.txt?city=INVALID",
+			WantCode: http.StatusBadRequest,
+			Want:     "failed to resolve place configs: unknown city: \"INVALID\"\n",
+		},
+		{
+			Name:     "parse error",
+			Target:   "/parseError.tmpl",
+			WantCode: http.StatusInternalServerError,
+			Want:     "template: parseError.tmpl.tmpl:1: function \"INVALID\" not defined\n",
+		},
+		{
+			Name:     "execute error",
+			Target:   "/executeError.md",
+			WantCode: http.StatusInternalServerError,
+			Want:     "template: executeError.md.tmpl:1:10: executing \"executeError.md.tmpl\" at <$.tz>: wrong type for value; expected string; got *time.Location\n",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.Logger(t)
+			method := c.Method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req := httptest.NewRequest(method, c.Target, nil)
+			rec := httptest.NewRecorder()
+
+			newServer(files).ServeHTTP(rec, req)
+
+			test.CheckComparable(t, "status", c.WantCode, rec.Code)
+			test.CheckStringMode(t, "body", c.Want, rec.Body.String(), c.WantMode)
+			if c.WantContentType != "" {
+				test.CheckString(t, "Content-Type",
+					c.WantContentType, rec.Header().Get("Content-Type"))
+			}
+			if c.WantCache != "" {
+				test.CheckString(t, "Cache-Control",
+					c.WantCache, rec.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestServer_ServeHTTP_notModified(t *testing.T) {
+	s := newServer(fstest.MapFS{
+		"a.txt.tmpl": &fstest.MapFile{Data: []byte("a")},
+	})
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/a.txt", nil))
+	etag := rec.Header().Get("ETag")
+	test.CheckRegexp(t, "ETag", `^"[0-9a-f]{20}"$`, etag)
+
+	req := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	test.CheckComparable(t, "status", http.StatusNotModified, rec.Code)
+}
+
+func TestServer_RequestConfig(t *testing.T) {
+	s := newServer(fstest.MapFS{})
+	req := httptest.NewRequest(
+		http.MethodGet, "/?date=Kislev+5786&candle_lighting=true&city=a&city=Phoenix", nil)
+	cfg, err := s.RequestConfig(req, "")
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "City", "Phoenix", cfg.City)
+	test.CheckComparable(t, "CandleLighting", true, cfg.CandleLighting)
+	test.CheckComparable(t, "RangeType", daterange.RangeTypeMonth, cfg.DateRange.RangeType)
+
+	// The base config is unchanged.
+	test.CheckString(t, "base City", "", s.Config.City)
+}
+
+// TestQueryKeys makes sure that each new config key
+// is either allowed in queries, or left out on purpose.
+func TestQueryKeys(t *testing.T) {
+	notAllowed := []string{"profiles", "profile", "events_file", "yahrzeits_file", "template_path"}
+	typ := reflect.TypeFor[config.Config]()
+	for i := range typ.NumField() {
+		key, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if key == "" || key == "-" || slices.Contains(notAllowed, key) {
+			continue
+		}
+		if !slices.Contains(server.QueryKeys, key) {
+			t.Errorf("config key %q is missing from QueryKeys", key)
+		}
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	cases := []struct {
+		Input             string
+		WantClean, WantTo string
+		WantOK            bool
+	}{
+		{"/a.html", "a.html", "a.html.tmpl", true},
+		{"/dir/", "dir/index.html", "dir/index.html.tmpl", true},
+		{"/", "index.html", "index.html.tmpl", true},
+		{"/../../etc/passwd", "etc/passwd", "etc/passwd.tmpl", true},
+		{"", "", "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			clean, tmplPath, ok := server.TemplatePath(c.Input)
+			test.CheckComparable(t, "ok", c.WantOK, ok)
+			test.CheckString(t, "cleanPath", c.WantClean, clean)
+			test.CheckString(t, "tmplPath", c.WantTo, tmplPath)
+		})
+	}
+}
+
+func TestContentType(t *testing.T) {
+	cases := []struct {
+		Input, Want string
+	}{
+		{"zmanim.html", "text/html; charset=utf-8"},
+		{"cal.ICS", "text/calendar; charset=utf-8"},
+		{"feed.json", "application/json"},
+		{"logo.png", "image/png"},
+		{"README", "text/plain; charset=utf-8"},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			test.CheckString(t, "Content-Type", c.Want, server.ContentType(c.Input))
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	now := time.Date(2025, time.December, 14, 0, 0, 0, 0, time.UTC)
+	dec, err := daterange.FromArgs([]string{"12", "2025"}, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jan, err := daterange.FromArgs([]string{"1", "2026"}, false, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := server.ETag(dec, []byte("body"))
+	test.CheckRegexp(t, "ETag", `^"[0-9a-f]{20}"$`, a)
+	if a != server.ETag(dec, []byte("body")) {
+		t.Error("ETag is not deterministic")
+	}
+	if a == server.ETag(jan, []byte("body")) {
+		t.Error("ETag did not change with the date range")
+	}
+	if a == server.ETag(dec, []byte("other")) {
+		t.Error("ETag did not change with the body")
+	}
+}
@@ -0,0 +1,115 @@
+package templating
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hebcal/hdate"
+
+	"github.com/chaimleib/hebcalfmt/xhdate"
+)
+
+// AnniversaryFuncs is a map of templating functions
+// which calculate yahrzeits and other Hebrew anniversaries
+// of any date, whether or not hebcal put them in the date range.
+//
+// Each takes the original date as an [hdate.HDate], a [time.Time],
+// or a string like "2004-02-03" or "7 Adar 5783".
+// For Gregorian dates, an optional afterSunset flag
+// moves the date to the next Hebrew day.
+var AnniversaryFuncs = map[string]any{
+	"yahrzeit":       Yahrzeit,
+	"hebrewBirthday": HebrewBirthday,
+	"barMitzvahDate": BarMitzvahDate,
+	"batMitzvahDate": BatMitzvahDate,
+}
+
+// Yahrzeit returns the day in the Hebrew year hyear
+// on which the yahrzeit of a death on date is observed.
+// See [hdate.GetYahrzeit] for the rules
+// for Adar in leap years and the 30th of Cheshvan and Kislev.
+func Yahrzeit(date any, hyear int, afterSunset ...bool) (hdate.HDate, error) {
+	hd, err := AnniversaryDate(date, afterSunset...)
+	if err != nil {
+		return hdate.HDate{}, err
+	}
+	return hdate.GetYahrzeit(hyear, hd)
+}
+
+// HebrewBirthday returns the Hebrew birthday in the Hebrew year hyear
+// of someone born on date.
+// It also works for other anniversaries, like weddings.
+// See [hdate.GetBirthdayOrAnniversary] for the rules
+// for Adar in leap years and the 30th of Cheshvan, Kislev and Adar I.
+func HebrewBirthday(date any, hyear int, afterSunset ...bool) (hdate.HDate, error) {
+	hd, err := AnniversaryDate(date, afterSunset...)
+	if err != nil {
+		return hdate.HDate{}, err
+	}
+	return hdate.GetBirthdayOrAnniversary(hyear, hd)
+}
+
+// BarMitzvahDate returns the 13th Hebrew birthday
+// of a boy born on date, like [HebrewBirthday].
+func BarMitzvahDate(date any, afterSunset ...bool) (hdate.HDate, error) {
+	hd, err := AnniversaryDate(date, afterSunset...)
+	if err != nil {
+		return hdate.HDate{}, err
+	}
+	return hdate.GetBirthdayOrAnniversary(hd.Year()+13, hd)
+}
+
+// BatMitzvahDate returns the 12th Hebrew birthday
+// of a girl born on date, like [HebrewBirthday].
+func BatMitzvahDate(date any, afterSunset ...bool) (hdate.HDate, error) {
+	hd, err := AnniversaryDate(date, afterSunset...)
+	if err != nil {
+		return hdate.HDate{}, err
+	}
+	return hdate.GetBirthdayOrAnniversary(hd.Year()+12, hd)
+}
+
+// AnniversaryDate returns the Hebrew date of an original date
+// given to the [AnniversaryFuncs].
+// If afterSunset is true, a Gregorian date moves to the next Hebrew day.
+func AnniversaryDate(date any, afterSunset ...bool) (hdate.HDate, error) {
+	if len(afterSunset) > 1 {
+		return hdate.HDate{}, fmt.Errorf(
+			"expected at most one afterSunset flag, got %d", len(afterSunset))
+	}
+	sunset := len(afterSunset) == 1 && afterSunset[0]
+
+	var hd hdate.HDate
+	switch date := date.(type) {
+	case hdate.HDate:
+		if sunset {
+			return hdate.HDate{}, errors.New(
+				"afterSunset only applies to Gregorian dates")
+		}
+		return date, nil
+
+	case time.Time:
+		hd = hdate.FromTime(date)
+
+	case string:
+		if t, err := time.Parse(time.DateOnly, date); err == nil {
+			hd = hdate.FromTime(t)
+			break
+		}
+		if sunset {
+			return hdate.HDate{}, errors.New(
+				"afterSunset only applies to Gregorian dates")
+		}
+		return xhdate.Parse(date)
+
+	default:
+		return hdate.HDate{}, fmt.Errorf(
+			"expected a date, got %T", date)
+	}
+
+	if sunset {
+		hd = hd.Next()
+	}
+	return hd, nil
+}
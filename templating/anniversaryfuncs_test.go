@@ -0,0 +1,124 @@
+package templating_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hebcal/hdate"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestYahrzeit(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Date        any
+		Year        int
+		AfterSunset []bool
+		Want        hdate.HDate
+		Err         string
+	}{
+		{
+			Name: "Gregorian time",
+			Date: time.Date(1967, time.October, 8, 0, 0, 0, 0, time.UTC),
+			Year: 5787,
+			Want: hdate.New(5787, hdate.Tishrei, 4),
+		},
+		{
+			Name:        "Gregorian string after sunset",
+			Date:        "1967-10-08",
+			Year:        5787,
+			AfterSunset: []bool{true},
+			Want:        hdate.New(5787, hdate.Tishrei, 5),
+		},
+		{
+			Name:        "not after sunset",
+			Date:        "1967-10-08",
+			Year:        5787,
+			AfterSunset: []bool{false},
+			Want:        hdate.New(5787, hdate.Tishrei, 4),
+		},
+		{
+			Name: "Adar in a leap year",
+			Date: hdate.New(5783, hdate.Adar1, 7),
+			Year: 5787,
+			Want: hdate.New(5787, hdate.Adar1, 7),
+		},
+		{
+			Name: "short Cheshvan after a short first year",
+			Date: "30 Cheshvan 5720",
+			Year: 5722,
+			Want: hdate.New(5722, hdate.Cheshvan, 29),
+		},
+		{
+			Name: "year before the death",
+			Date: hdate.New(5783, hdate.Adar1, 7),
+			Year: 5700,
+			Err:  "year 5700 occurs on or before original date",
+		},
+		{
+			Name:        "Hebrew date after sunset",
+			Date:        hdate.New(5783, hdate.Adar1, 7),
+			Year:        5787,
+			AfterSunset: []bool{true},
+			Err:         "afterSunset only applies to Gregorian dates",
+		},
+		{
+			Name:        "Hebrew string after sunset",
+			Date:        "7 Adar 5783",
+			Year:        5787,
+			AfterSunset: []bool{true},
+			Err:         "afterSunset only applies to Gregorian dates",
+		},
+		{
+			Name:        "too many flags",
+			Date:        "1967-10-08",
+			Year:        5787,
+			AfterSunset: []bool{true, true},
+			Err:         "expected at most one afterSunset flag, got 2",
+		},
+		{
+			Name: "not a date",
+			Date: 42,
+			Year: 5787,
+			Err:  "expected a date, got int",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := templating.Yahrzeit(c.Date, c.Year, c.AfterSunset...)
+			test.CheckErr(t, err, c.Err)
+			test.CheckHDate(t, "yahrzeit", c.Want, got)
+		})
+	}
+}
+
+func TestHebrewBirthday(t *testing.T) {
+	got, err := templating.HebrewBirthday(hdate.New(5783, hdate.Adar1, 7), 5787)
+	test.CheckErr(t, err, "")
+	test.CheckHDate(t, "Adar in a leap year", hdate.New(5787, hdate.Adar2, 7), got)
+
+	got, err = templating.HebrewBirthday("2023-02-27", 5787, true)
+	test.CheckErr(t, err, "")
+	test.CheckHDate(t, "after sunset", hdate.New(5787, hdate.Adar2, 7), got)
+
+	_, err = templating.HebrewBirthday("not a date", 5787)
+	test.CheckErr(t, err, `invalid year in Hebrew date "not a date": strconv.Atoi: parsing "date": invalid syntax`)
+}
+
+func TestBarMitzvahDate(t *testing.T) {
+	got, err := templating.BarMitzvahDate(hdate.New(5784, hdate.Adar1, 30))
+	test.CheckErr(t, err, "")
+	test.CheckHDate(t, "Adar I 30", hdate.New(5797, hdate.Nisan, 1), got)
+
+	got, err = templating.BarMitzvahDate("2011-03-10", true)
+	test.CheckErr(t, err, "")
+	test.CheckHDate(t, "after sunset", hdate.New(5784, hdate.Adar2, 5), got)
+}
+
+func TestBatMitzvahDate(t *testing.T) {
+	got, err := templating.BatMitzvahDate(hdate.New(5780, hdate.Tishrei, 1))
+	test.CheckErr(t, err, "")
+	test.CheckHDate(t, "Rosh Hashana", hdate.New(5792, hdate.Tishrei, 1), got)
+}
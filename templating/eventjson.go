@@ -0,0 +1,157 @@
+package templating
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/event"
+	"github.com/hebcal/hebcal-go/hebcal"
+)
+
+// EventJSON is the serialized form of an [event.CalEvent]
+// used by the json and ndjson output formats.
+//
+// Example:
+//
+//	{
+//	  "date": "2025-12-19",
+//	  "hdate": {"hy": 5786, "hm": "Kislev", "hd": 29},
+//	  "desc": "Candle lighting",
+//	  "title": "Candle lighting",
+//	  "flags": ["LIGHT_CANDLES"],
+//	  "time": "2025-12-19T16:12:00-05:00",
+//	  "tz": "America/New_York"
+//	}
+type EventJSON struct {
+	// Date is the Gregorian date in YYYY-MM-DD format.
+	Date string `json:"date"`
+
+	// HDate is the Hebrew date, with the month name in English.
+	HDate hdate.HDate `json:"hdate"`
+
+	// Desc is the untranslated description of the event,
+	// which is stable across languages.
+	Desc string `json:"desc"`
+
+	// Title is the description rendered in the configured language.
+	Title string `json:"title"`
+
+	// Flags lists the names of the event's flags,
+	// as found in the `$.event` template constants.
+	Flags []string `json:"flags"`
+
+	// Time is set for timed events, in RFC 3339 format with a UTC offset.
+	Time string `json:"time,omitempty"`
+
+	// TZ is the IANA time zone name of Time.
+	TZ string `json:"tz,omitempty"`
+}
+
+// NewEventJSON converts ev to an [EventJSON],
+// rendering the title in lang.
+func NewEventJSON(lang string, ev event.CalEvent) EventJSON {
+	y, m, d := ev.GetDate().Greg()
+	result := EventJSON{
+		Date:  fmt.Sprintf("%04d-%02d-%02d", y, m, d),
+		HDate: ev.GetDate(),
+		Desc:  eventDesc(ev),
+		Title: eventTitle(lang, ev),
+		Flags: FlagNames(ev.GetFlags()),
+	}
+	if timed, ok := ev.(hebcal.TimedEvent); ok {
+		result.Time = timed.EventTime.Format(time.RFC3339)
+		result.TZ = timed.EventTime.Location().String()
+	}
+	return result
+}
+
+// namedFlag is an entry of [EventConsts].
+type namedFlag struct {
+	Name string
+	Flag event.HolidayFlags
+}
+
+// knownFlags returns the entries of [EventConsts],
+// sorted from lowest bit to highest.
+var knownFlags = sync.OnceValue(func() []namedFlag {
+	known := make([]namedFlag, 0, len(EventConsts))
+	for name, flag := range EventConsts {
+		known = append(known, namedFlag{name, flag.(event.HolidayFlags)})
+	}
+	slices.SortFunc(known, func(a, b namedFlag) int {
+		return cmp.Compare(a.Flag, b.Flag)
+	})
+	return known
+})
+
+// FlagNames decodes flags into the names used in [EventConsts],
+// ordered from lowest bit to highest.
+func FlagNames(flags event.HolidayFlags) []string {
+	result := []string{}
+	for _, nf := range knownFlags() {
+		if flags&nf.Flag != 0 {
+			result = append(result, nf.Name)
+		}
+	}
+	return result
+}
+
+// WriteJSON writes events to w as an indented JSON array of [EventJSON].
+func WriteJSON(w io.Writer, lang string, events []event.CalEvent) error {
+	result := make([]EventJSON, 0, len(events))
+	for _, ev := range events {
+		result = append(result, NewEventJSON(lang, ev))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(result)
+}
+
+// WriteNDJSON writes events to w as newline-delimited JSON,
+// with one [EventJSON] object per line.
+func WriteNDJSON(w io.Writer, lang string, events []event.CalEvent) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, ev := range events {
+		if err := enc.Encode(NewEventJSON(lang, ev)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventDesc returns the untranslated description of ev.
+func eventDesc(ev event.CalEvent) string {
+	switch ev := ev.(type) {
+	case hebcal.TimedEvent:
+		return ev.Desc
+	case event.HolidayEvent:
+		return ev.Desc
+	case event.UserEvent:
+		return ev.Desc
+	default:
+		return ev.Render("en")
+	}
+}
+
+// eventTitle renders ev in lang.
+// Unlike [hebcal.TimedEvent.Render], the time is left off,
+// since it is usually shown separately.
+func eventTitle(lang string, ev event.CalEvent) string {
+	timed, ok := ev.(hebcal.TimedEvent)
+	if !ok {
+		return ev.Render(lang)
+	}
+	if timed.LinkedEvent != nil {
+		return timed.LinkedEvent.Render(lang)
+	}
+	return Translate(lang, timed.Desc)
+}
@@ -0,0 +1,168 @@
+package templating_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/event"
+	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/zmanim"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestFlagNames(t *testing.T) {
+	cases := []struct {
+		Name  string
+		Flags event.HolidayFlags
+		Want  []string
+	}{
+		{Name: "none", Want: []string{}},
+		{Name: "one", Flags: event.CHAG, Want: []string{"CHAG"}},
+		{
+			Name:  "sorted by bit",
+			Flags: event.MINOR_HOLIDAY | event.CHANUKAH_CANDLES,
+			Want:  []string{"CHANUKAH_CANDLES", "MINOR_HOLIDAY"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.CheckSlice(t, "flags", c.Want, templating.FlagNames(c.Flags))
+		})
+	}
+}
+
+func TestNewEventJSON(t *testing.T) {
+	hd := hdate.New(5786, hdate.Kislev, 29)
+	opts := &hebcal.CalOptions{Location: zmanim.LookupCity("Phoenix")}
+	phoenix, err := time.LoadLocation("America/Phoenix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	candleTime := time.Date(2025, time.December, 19, 17, 5, 0, 0, phoenix)
+
+	cases := []struct {
+		Name  string
+		Lang  string
+		Event event.CalEvent
+		Want  templating.EventJSON
+	}{
+		{
+			Name:  "Hebrew date",
+			Lang:  "en",
+			Event: event.NewHebrewDateEvent(hd),
+			Want: templating.EventJSON{
+				Date:  "2025-12-19",
+				HDate: hd,
+				Desc:  "29th of Kislev, 5786",
+				Title: "29th of Kislev, 5786",
+				Flags: []string{"HEBREW_DATE"},
+			},
+		},
+		{
+			Name: "holiday",
+			Lang: "he-x-NoNikud",
+			Event: event.HolidayEvent{
+				Date:  hd,
+				Desc:  "Chanukah: 6 Candles",
+				Flags: event.CHANUKAH_CANDLES | event.MINOR_HOLIDAY,
+			},
+			Want: templating.EventJSON{
+				Date:  "2025-12-19",
+				HDate: hd,
+				Desc:  "Chanukah: 6 Candles",
+				Title: "חנוכה: ו׳ נרות",
+				Flags: []string{"CHANUKAH_CANDLES", "MINOR_HOLIDAY"},
+			},
+		},
+		{
+			Name: "timed",
+			Lang: "en",
+			Event: hebcal.NewTimedEvent(
+				hd, "Candle lighting", event.LIGHT_CANDLES, candleTime, 0, nil, opts),
+			Want: templating.EventJSON{
+				Date:  "2025-12-19",
+				HDate: hd,
+				Desc:  "Candle lighting",
+				Title: "Candle lighting",
+				Flags: []string{"LIGHT_CANDLES"},
+				Time:  "2025-12-19T17:05:00-07:00",
+				TZ:    "America/Phoenix",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got := templating.NewEventJSON(c.Lang, c.Event)
+			test.CheckString(t, "Date", c.Want.Date, got.Date)
+			test.CheckComparable(t, "HDate", c.Want.HDate, got.HDate)
+			test.CheckString(t, "Desc", c.Want.Desc, got.Desc)
+			test.CheckString(t, "Title", c.Want.Title, got.Title)
+			test.CheckSlice(t, "Flags", c.Want.Flags, got.Flags)
+			test.CheckString(t, "Time", c.Want.Time, got.Time)
+			test.CheckString(t, "TZ", c.Want.TZ, got.TZ)
+		})
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("test: write failed")
+}
+
+func TestWriteJSON(t *testing.T) {
+	hd := hdate.New(5786, hdate.Kislev, 29)
+	events := []event.CalEvent{event.NewHebrewDateEvent(hd)}
+
+	var buf strings.Builder
+	err := templating.WriteJSON(&buf, "en", events)
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "output", `[
+  {
+    "date": "2025-12-19",
+    "hdate": {
+      "hy": 5786,
+      "hm": "Kislev",
+      "hd": 29
+    },
+    "desc": "29th of Kislev, 5786",
+    "title": "29th of Kislev, 5786",
+    "flags": [
+      "HEBREW_DATE"
+    ]
+  }
+]
+`, buf.String())
+
+	t.Run("empty", func(t *testing.T) {
+		var buf strings.Builder
+		err := templating.WriteJSON(&buf, "en", nil)
+		test.CheckErr(t, err, "")
+		test.CheckString(t, "output", "[]\n", buf.String())
+	})
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	hd := hdate.New(5786, hdate.Kislev, 29)
+	events := []event.CalEvent{
+		event.NewHebrewDateEvent(hd),
+		event.NewHebrewDateEvent(hd.Next()),
+	}
+
+	var buf strings.Builder
+	err := templating.WriteNDJSON(&buf, "en", events)
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "output", `{"date":"2025-12-19","hdate":{"hy":5786,"hm":"Kislev","hd":29},"desc":"29th of Kislev, 5786","title":"29th of Kislev, 5786","flags":["HEBREW_DATE"]}
+{"date":"2025-12-20","hdate":{"hy":5786,"hm":"Kislev","hd":30},"desc":"30th of Kislev, 5786","title":"30th of Kislev, 5786","flags":["HEBREW_DATE"]}
+`, buf.String())
+
+	t.Run("write error", func(t *testing.T) {
+		err := templating.WriteNDJSON(errWriter{}, "en", events)
+		test.CheckErr(t, err, "test: write failed")
+	})
+}
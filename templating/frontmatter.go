@@ -0,0 +1,103 @@
+package templating
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"regexp"
+
+	"github.com/chaimleib/hebcalfmt/config"
+)
+
+// frontMatterRe matches the front matter at the start of a template,
+// capturing its YAML document.
+var frontMatterRe = regexp.MustCompile(
+	`^\{\{-?\s*/\*---\r?\n((?s:.*?)\r?\n)?---\*/\s*-?\}\}`)
+
+// FrontMatter is the optional header of a template file:
+// a YAML document in a comment at the very start of the file,
+// between lines of three dashes.
+//
+//	{{- /*---
+//	description: Shows the zmanim of a day
+//	params:
+//	  - name: city
+//	    type: city
+//	    default: Phoenix
+//	    description: the city to show zmanim for
+//	config:
+//	  daily_zmanim: true
+//	---*/ -}}
+//
+// Since it is a comment, it prints nothing.
+type FrontMatter struct {
+	// Description says what the template shows,
+	// for `--info templates`.
+	Description string `json:"description"`
+
+	// Params declares the parameters of the template.
+	Params []Param `json:"params"`
+
+	// Config holds defaults for config keys, like `city`,
+	// so that the template works without a separate config file.
+	// It is an object like a config file,
+	// applied beneath the user's config files.
+	Config json.RawMessage `json:"config"`
+}
+
+// ParseFrontMatter returns the [FrontMatter] of a template,
+// or a zero FrontMatter if it has none.
+// In case of an error, fpath helps with debugging.
+func ParseFrontMatter(text []byte, fpath string) (FrontMatter, error) {
+	var fm FrontMatter
+	m := frontMatterRe.FindSubmatch(text)
+	if m == nil || len(bytes.TrimSpace(m[1])) == 0 {
+		return fm, nil
+	}
+
+	data, err := config.ToJSON(m[1], config.FormatYAML)
+	if err != nil {
+		return fm, fmt.Errorf("front matter of %s: %w", fpath, err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&fm); err != nil {
+		return FrontMatter{}, fmt.Errorf("front matter of %s: %w", fpath, err)
+	}
+
+	seen := make(map[string]bool, len(fm.Params))
+	for _, p := range fm.Params {
+		if err := p.check(); err != nil {
+			return FrontMatter{}, fmt.Errorf("front matter of %s: %w", fpath, err)
+		}
+		if seen[p.Name] {
+			return FrontMatter{}, fmt.Errorf(
+				"front matter of %s: parameter %s is declared twice", fpath, p.Name)
+		}
+		seen[p.Name] = true
+	}
+
+	if string(fm.Config) == "null" {
+		fm.Config = nil
+	}
+	if len(fm.Config) != 0 {
+		dec := json.NewDecoder(bytes.NewReader(fm.Config))
+		dec.DisallowUnknownFields()
+		var cfg config.Config
+		if err := dec.Decode(&cfg); err != nil {
+			return FrontMatter{}, fmt.Errorf("front matter of %s: config: %w", fpath, err)
+		}
+	}
+	return fm, nil
+}
+
+// ReadFrontMatter reads the template at fpath from files,
+// and returns its [FrontMatter], like [ParseFrontMatter].
+func ReadFrontMatter(files fs.FS, fpath string) (FrontMatter, error) {
+	text, err := fs.ReadFile(files, fpath)
+	if err != nil {
+		return FrontMatter{}, err
+	}
+	return ParseFrontMatter(text, fpath)
+}
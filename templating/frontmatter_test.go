@@ -0,0 +1,127 @@
+package templating_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Text    string
+		Want    templating.FrontMatter
+		WantErr string
+	}{
+		{Name: "none", Text: "Hebrew: {{hdateFromTime $.now}}"},
+		{Name: "empty", Text: "{{- /*---\n---*/ -}}\nhi"},
+		{
+			Name: "description",
+			Text: "{{- /*---\n# What this shows\ndescription: Today's date\n---*/ -}}\nhi",
+			Want: templating.FrontMatter{Description: "Today's date"},
+		},
+		{
+			Name: "without trim markers",
+			Text: "{{/*---\r\ndescription: Today's date\r\n---*/}}",
+			Want: templating.FrontMatter{Description: "Today's date"},
+		},
+		{
+			Name: "not at the start",
+			Text: "hi\n{{- /*---\ndescription: Today's date\n---*/ -}}",
+		},
+		{
+			Name: "params",
+			Text: "{{- /*---\nparams:\n  - name: city\n    type: city\n    default: Phoenix\n" +
+				"  - name: count\n    type: int\n    default: 3\n    description: how many\n" +
+				"  - name: label\n---*/ -}}",
+			Want: templating.FrontMatter{Params: []templating.Param{
+				{Name: "city", Type: "city", Default: "Phoenix"},
+				{Name: "count", Type: "int", Default: 3.0, Description: "how many"},
+				{Name: "label"},
+			}},
+		},
+		{
+			Name: "param with unquoted date default",
+			Text: "{{- /*---\nparams:\n  - name: start\n    type: date\n    default: 2025-12-14\n---*/ -}}",
+			Want: templating.FrontMatter{Params: []templating.Param{
+				{Name: "start", Type: "date", Default: "2025-12-14"},
+			}},
+		},
+		{
+			Name:    "param with invalid name",
+			Text:    "{{- /*---\nparams:\n  - name: start-date\n---*/ -}}",
+			WantErr: `front matter of test.tmpl: invalid parameter name "start-date"; use letters, digits and underscores`,
+		},
+		{
+			Name: "param with unknown type",
+			Text: "{{- /*---\nparams:\n  - name: when\n    type: datetime\n---*/ -}}",
+			WantErr: `front matter of test.tmpl: parameter when: unknown type "datetime"; ` +
+				`expected one of ["string" "int" "bool" "date" "hdate" "duration" "city"]`,
+		},
+		{
+			Name:    "param with invalid default",
+			Text:    "{{- /*---\nparams:\n  - name: city\n    type: city\n    default: Atlantis\n---*/ -}}",
+			WantErr: `front matter of test.tmpl: parameter city: invalid default: unknown city "Atlantis"`,
+		},
+		{
+			Name:    "param declared twice",
+			Text:    "{{- /*---\nparams:\n  - name: city\n  - name: city\n---*/ -}}",
+			WantErr: "front matter of test.tmpl: parameter city is declared twice",
+		},
+		{
+			Name: "config",
+			Text: "{{- /*---\nconfig:\n  city: Phoenix\n  candle_lighting: true\n---*/ -}}",
+			Want: templating.FrontMatter{
+				Config: json.RawMessage(`{"city":"Phoenix","candle_lighting":true}`),
+			},
+		},
+		{Name: "empty config", Text: "{{- /*---\nconfig:\n---*/ -}}"},
+		{
+			Name:    "config with unknown key",
+			Text:    "{{- /*---\nconfig:\n  citty: Phoenix\n---*/ -}}",
+			WantErr: `front matter of test.tmpl: config: json: unknown field "citty"`,
+		},
+		{
+			Name:    "config with invalid value",
+			Text:    "{{- /*---\nconfig:\n  candle_lighting: sometimes\n---*/ -}}",
+			WantErr: "front matter of test.tmpl: config: json: cannot unmarshal string into Go struct field Config.candle_lighting of type bool",
+		},
+		{
+			Name:    "unknown key",
+			Text:    "{{- /*---\ndescripton: Today's date\n---*/ -}}",
+			WantErr: `front matter of test.tmpl: json: unknown field "descripton"`,
+		},
+		{
+			Name:    "invalid YAML",
+			Text:    "{{- /*---\ndescription: [\n---*/ -}}",
+			WantErr: "front matter of test.tmpl: yaml: line 1: did not find expected node content",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := templating.ParseFrontMatter([]byte(c.Text), "test.tmpl")
+			test.CheckErr(t, err, c.WantErr)
+			if !reflect.DeepEqual(got, c.Want) {
+				t.Errorf("want %+v, got %+v", c.Want, got)
+			}
+		})
+	}
+}
+
+func TestReadFrontMatter(t *testing.T) {
+	files := fstest.MapFS{
+		"today.tmpl": &fstest.MapFile{
+			Data: []byte("{{- /*---\ndescription: Today's date\n---*/ -}}"),
+		},
+	}
+	got, err := templating.ReadFrontMatter(files, "today.tmpl")
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "description", "Today's date", got.Description)
+
+	_, err = templating.ReadFrontMatter(files, "missing.tmpl")
+	test.CheckErr(t, err, "open missing.tmpl: file does not exist")
+}
@@ -8,10 +8,16 @@ import (
 	"github.com/hebcal/hebcal-go/event"
 	"github.com/hebcal/hebcal-go/hebcal"
 	"github.com/hebcal/hebcal-go/omer"
+
+	"github.com/chaimleib/hebcalfmt/hcfiles"
 )
 
-// HebcalFuncs builds a map of templating functions from a [hebcal.CalOptions].
-func HebcalFuncs(opts *hebcal.CalOptions) map[string]any {
+// HebcalFuncs builds a map of templating functions from a [hebcal.CalOptions]
+// and the [hcfiles.Extras] of the events and yahrzeit files, which may be nil.
+func HebcalFuncs(
+	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
+) map[string]any {
 	return map[string]any{
 		// as<Type>Event converts [event.CalEvent]s to struct types.
 		// It returns nil if it fails.
@@ -19,18 +25,23 @@ func HebcalFuncs(opts *hebcal.CalOptions) map[string]any {
 		"asOmerEvent":    AsEvent[omer.OmerEvent],
 		"asTimedEvent":   AsEvent[hebcal.TimedEvent],
 		"asUserEvent":    AsEvent[event.UserEvent],
+		"asFileEvent":    AsEvent[hcfiles.FileEvent],
+
+		// eventSource returns the events or yahrzeit file
+		// which an event came from, or "" if none.
+		"eventSource": EventSource(extras),
 
 		// hebcal returns a slice of [event.CalEvent].
 		// Underlying types of that interface can be recovered
 		// using as<Kind>Event functions.
-		"hebcal": Hebcal(opts),
+		"hebcal": Hebcal(opts, extras),
 
 		// timedEvents returns a slice of [hebcal.TimedEvent]
-		"timedEvents":   TimedEvents(opts),
+		"timedEvents":   TimedEvents(opts, extras),
 		"eventsByFlags": EventsByFlags,
 
-		"dayHasFlags":          DayHasFlags(opts),
-		"dayIsShabbatOrYomTov": DayIsShabbatOrYomTov(opts),
+		"dayHasFlags":          DayHasFlags(opts, extras),
+		"dayIsShabbatOrYomTov": DayIsShabbatOrYomTov(opts, extras),
 	}
 }
 
@@ -51,8 +62,12 @@ func AsEvent[T event.CalEvent](e event.CalEvent) *T {
 // If one date is provided, only the events for that day are returned.
 // If two dates, all the events between them are returned,
 // including those on the end date.
+//
+// The events of the extras' rules, if any, follow those of hebcal on each day.
+// See [hcfiles.HebrewCalendar].
 func Hebcal(
 	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
 ) func(dates ...hdate.HDate) ([]event.CalEvent, error) {
 	return func(dates ...hdate.HDate) ([]event.CalEvent, error) {
 		optsCopy := *opts
@@ -60,7 +75,19 @@ func Hebcal(
 		if _, err := SetDates(opts)(dates...); err != nil {
 			return nil, err
 		}
-		return hebcal.HebrewCalendar(opts)
+		return hcfiles.HebrewCalendar(opts, extras.RulesOrNil())
+	}
+}
+
+// EventSource returns the name of the events or yahrzeit file
+// which an event came from, or the empty string if it did not come from one.
+// See [hcfiles.Sources.Of].
+func EventSource(extras *hcfiles.Extras) func(ev event.CalEvent) string {
+	return func(ev event.CalEvent) string {
+		if extras == nil {
+			return ""
+		}
+		return extras.Sources.Of(ev)
 	}
 }
 
@@ -105,6 +132,7 @@ func CompareTimedEvents(events []hebcal.TimedEvent) func(i, j int) bool {
 // including those on the end date.
 func TimedEvents(
 	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
 ) func(dates ...hdate.HDate) ([]hebcal.TimedEvent, error) {
 	return func(dates ...hdate.HDate) ([]hebcal.TimedEvent, error) {
 		optsCopy := *opts
@@ -113,7 +141,7 @@ func TimedEvents(
 			return nil, err
 		}
 
-		cal, err := hebcal.HebrewCalendar(opts)
+		cal, err := hcfiles.HebrewCalendar(opts, extras.RulesOrNil())
 		if err != nil {
 			return nil, err
 		}
@@ -162,12 +190,13 @@ func EventsByFlags(
 // on any of its events.
 func DayHasFlags(
 	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
 ) func(d hdate.HDate, flags ...event.HolidayFlags) (bool, error) {
 	return func(d hdate.HDate, flags ...event.HolidayFlags) (bool, error) {
 		mask := MergeFlags(flags...)
 
 		// Get the events occurring on d.
-		events, err := Hebcal(opts)(d)
+		events, err := Hebcal(opts, extras)(d)
 		if err != nil {
 			return false, err
 		}
@@ -186,9 +215,10 @@ func DayHasFlags(
 // It may be used by logic which determines candle lighting and havdalah times.
 func DayIsShabbatOrYomTov(
 	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
 ) func(d hdate.HDate) (bool, error) {
 	return func(d hdate.HDate) (bool, error) {
-		events, err := Hebcal(opts)(d)
+		events, err := Hebcal(opts, extras)(d)
 		if err != nil {
 			return false, err
 		}
@@ -15,6 +15,7 @@ import (
 	"github.com/hebcal/hebcal-go/sedra"
 	"github.com/hebcal/hebcal-go/zmanim"
 
+	"github.com/chaimleib/hebcalfmt/hcfiles"
 	"github.com/chaimleib/hebcalfmt/templating"
 	"github.com/chaimleib/hebcalfmt/test"
 )
@@ -181,6 +182,7 @@ func TestHebcal(t *testing.T) {
 	cases := []struct {
 		Name  string
 		Opts  hebcal.CalOptions
+		Rules hcfiles.EventRules
 		Dates []hdate.HDate
 		Want  []string
 		Err   string
@@ -190,6 +192,30 @@ func TestHebcal(t *testing.T) {
 			Dates: []hdate.HDate{hd, hd, hd},
 			Err:   "expected 0-2 dates, got 3",
 		},
+		{
+			Name: "rules after hebcal events",
+			Opts: hebcal.CalOptions{NoModern: true},
+			Rules: hcfiles.EventRules{
+				{Schedule: hcfiles.Weekly{Weekday: time.Friday}, Desc: "Oneg", Days: 1},
+				{
+					Schedule: hcfiles.GregorianAnnual{Month: time.December, Day: 17},
+					Desc:     "Retreat",
+					Days:     3,
+				},
+			},
+			Dates: []hdate.HDate{
+				hdate.FromGregorian(2020, time.December, 17),
+				hdate.FromGregorian(2020, time.December, 19),
+			},
+			Want: []string{
+				"2020-12-17 Chanukah: 8 Candles",
+				"2020-12-17 Retreat (day 1 of 3)",
+				"2020-12-18 Chanukah: 8th Day",
+				"2020-12-18 Oneg",
+				"2020-12-18 Retreat (day 2 of 3)",
+				"2020-12-19 Retreat (day 3 of 3)",
+			},
+		},
 		{
 			Name: "show year",
 			Opts: hebcal.CalOptions{
@@ -287,7 +313,7 @@ func TestHebcal(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
-			got, err := templating.Hebcal(&c.Opts)(c.Dates...)
+			got, err := templating.Hebcal(&c.Opts, &hcfiles.Extras{Rules: c.Rules})(c.Dates...)
 			test.CheckErr(t, err, c.Err)
 			gotStr := make([]string, 0, len(got))
 			for _, event := range got {
@@ -302,6 +328,50 @@ func TestHebcal(t *testing.T) {
 	}
 }
 
+func TestEventSource(t *testing.T) {
+	hd := hdate.New(5786, hdate.Kislev, 6)
+	extras := &hcfiles.Extras{}
+	extras.Sources.AddUserEvents("family.txt",
+		hebcal.UserEvent{Month: hdate.Kislev, Day: 6, Desc: "Anniversary"})
+
+	cases := []struct {
+		Name   string
+		Extras *hcfiles.Extras
+		Event  event.CalEvent
+		Want   string
+	}{
+		{
+			Name:  "nil extras",
+			Event: event.UserEvent{Date: hd, Desc: "Anniversary"},
+			Want:  "",
+		},
+		{
+			Name:   "user event",
+			Extras: extras,
+			Event:  event.UserEvent{Date: hd, Desc: "Anniversary"},
+			Want:   "family.txt",
+		},
+		{
+			Name:   "file event",
+			Extras: extras,
+			Event:  hcfiles.FileEvent{Date: hd, Desc: "Kiddush", Source: "shul.txt"},
+			Want:   "shul.txt",
+		},
+		{
+			Name:   "holiday",
+			Extras: extras,
+			Event:  event.NewHebrewDateEvent(hd),
+			Want:   "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got := templating.EventSource(c.Extras)(c.Event)
+			test.CheckString(t, "source", c.Want, got)
+		})
+	}
+}
+
 func TestCompareTimedEvents(t *testing.T) {
 	tz, err := time.LoadLocation("US/Central")
 	if err != nil {
@@ -716,7 +786,7 @@ func TestTimedEvents(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
-			events, err := templating.TimedEvents(c.Opts)(c.Dates...)
+			events, err := templating.TimedEvents(c.Opts, nil)(c.Dates...)
 			test.CheckErr(t, err, c.Err)
 
 			got := make([]string, 0, len(events))
@@ -865,7 +935,7 @@ func TestDayHasFlags(t *testing.T) {
 			if opts == nil {
 				opts = new(hebcal.CalOptions)
 			}
-			got, err := templating.DayHasFlags(opts)(c.Date, c.Flags...)
+			got, err := templating.DayHasFlags(opts, nil)(c.Date, c.Flags...)
 			test.CheckErr(t, err, c.Err)
 			if c.Want != got {
 				t.Errorf("want: %v got: %v", c.Want, got)
@@ -876,17 +946,29 @@ func TestDayHasFlags(t *testing.T) {
 
 func TestDayIsShabbatOrYomTov(t *testing.T) {
 	cases := []struct {
-		Name string
-		Opts *hebcal.CalOptions
-		Date hdate.HDate
-		Want bool
-		Err  string
+		Name  string
+		Opts  *hebcal.CalOptions
+		Rules hcfiles.EventRules
+		Date  hdate.HDate
+		Want  bool
+		Err   string
 	}{
 		{
 			Name: "RH",
 			Date: hdate.New(5687, hdate.Tishrei, 1),
 			Want: true,
 		},
+		{
+			Name: "rule with CHAG flag",
+			Rules: hcfiles.EventRules{{
+				Schedule: hcfiles.HebrewAnnual{Month: hdate.Kislev, Day: 25},
+				Desc:     "Local Yom Tov",
+				Days:     1,
+				Flags:    event.CHAG,
+			}},
+			Date: hdate.New(5687, hdate.Kislev, 25),
+			Want: true,
+		},
 		{
 			Name: "Chanukah is not Shabbos or Yom Tov",
 			Date: hdate.New(5687, hdate.Kislev, 25),
@@ -907,7 +989,7 @@ func TestDayIsShabbatOrYomTov(t *testing.T) {
 			if opts == nil {
 				opts = new(hebcal.CalOptions)
 			}
-			got, err := templating.DayIsShabbatOrYomTov(opts)(c.Date)
+			got, err := templating.DayIsShabbatOrYomTov(opts, &hcfiles.Extras{Rules: c.Rules})(c.Date)
 			test.CheckErr(t, err, c.Err)
 			if c.Want != got {
 				t.Errorf("want: %v got: %v", c.Want, got)
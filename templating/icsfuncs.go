@@ -0,0 +1,196 @@
+package templating
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hebcal/hebcal-go/event"
+	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/ical"
+)
+
+// ICSProdID identifies hebcalfmt as the producer of iCalendar data.
+const ICSProdID = "-//chaimleib//hebcalfmt//EN"
+
+// ICSDomain is the right-hand side of generated iCalendar UIDs.
+const ICSDomain = "hebcalfmt"
+
+// DefaultCandleAlarm is how long before candle-lighting
+// the iCalendar template functions set a reminder.
+const DefaultCandleAlarm = 10 * time.Minute
+
+// ICSFuncs builds a map of templating functions
+// which render events in the iCalendar format.
+// The output is stamped with now, which is `$.now`.
+func ICSFuncs(opts *hebcal.CalOptions, now time.Time) map[string]any {
+	return map[string]any{
+		// icsCalendar renders a full VCALENDAR with a VTIMEZONE.
+		"icsCalendar": ICSCalendar(opts, now),
+
+		// icsEvent renders a single VEVENT.
+		"icsEvent": ICSEvent(opts, now),
+	}
+}
+
+// ICSOptions controls how hebcal events are converted to iCalendar events.
+type ICSOptions struct {
+	// Language is used to render the event titles.
+	Language string
+
+	// TZ is the time zone for timed events.
+	TZ *time.Location
+
+	// LocationName distinguishes the UIDs of timed events in different places.
+	LocationName string
+
+	// CandleAlarm is how long before candle-lighting to set a reminder.
+	// If zero, no reminders are set.
+	CandleAlarm time.Duration
+
+	// Stamp is the creation time of the calendar.
+	Stamp time.Time
+}
+
+// NewICSOptions fills in an [ICSOptions] from the location in opts,
+// using [DefaultCandleAlarm] and the stamp.
+func NewICSOptions(
+	opts *hebcal.CalOptions,
+	lang string,
+	stamp time.Time,
+) (ICSOptions, error) {
+	result := ICSOptions{
+		Language:    lang,
+		TZ:          time.UTC,
+		CandleAlarm: DefaultCandleAlarm,
+		Stamp:       stamp,
+	}
+	if opts.Location != nil {
+		tz, err := time.LoadLocation(opts.Location.TimeZoneId)
+		if err != nil {
+			return result, err
+		}
+		result.TZ = tz
+		result.LocationName = opts.Location.Name
+	}
+	return result, nil
+}
+
+// Event converts a hebcal event into an [ical.Event].
+// [hebcal.TimedEvent]s become timed events;
+// all others are all-day events.
+func (o ICSOptions) Event(ev event.CalEvent) ical.Event {
+	y, m, d := ev.GetDate().Greg()
+	dateStr := fmt.Sprintf("%04d-%02d-%02d", y, m, d)
+
+	timed, ok := ev.(hebcal.TimedEvent)
+	if !ok {
+		return ical.Event{
+			UID:     ical.UID(ICSDomain, dateStr, ev.Render("en")),
+			Summary: ev.Render(o.Language),
+			Start:   time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+		}
+	}
+
+	result := ical.Event{
+		UID:     ical.UID(ICSDomain, dateStr, timed.Desc, o.LocationName),
+		Summary: eventTitle(o.Language, ev),
+		Start:   timed.EventTime,
+	}
+	const candleFlags = event.LIGHT_CANDLES |
+		event.LIGHT_CANDLES_TZEIS |
+		event.CHANUKAH_CANDLES
+	if timed.Flags&candleFlags != 0 {
+		result.Alarm = o.CandleAlarm
+	}
+	return result
+}
+
+// Calendar converts hebcal events into an [ical.Calendar].
+func (o ICSOptions) Calendar(events []event.CalEvent) ical.Calendar {
+	cal := ical.Calendar{
+		ProdID: ICSProdID,
+		TZ:     o.TZ,
+		Stamp:  o.Stamp,
+		Events: make([]ical.Event, 0, len(events)),
+	}
+	if o.LocationName != "" {
+		cal.Name = "Hebcal " + o.LocationName
+	}
+	for _, ev := range events {
+		cal.Events = append(cal.Events, o.Event(ev))
+	}
+	return cal
+}
+
+// ICSCalendar renders events as a VCALENDAR string,
+// using the location in opts for the time zone, stamped with now.
+// The events may be a []event.CalEvent, like those returned by [Hebcal],
+// or a []hebcal.TimedEvent, like those returned by [TimedEvents].
+func ICSCalendar(
+	opts *hebcal.CalOptions,
+	now time.Time,
+) func(lang string, events any) (string, error) {
+	return func(lang string, events any) (string, error) {
+		calEvents, err := asCalEvents(events)
+		if err != nil {
+			return "", err
+		}
+
+		o, err := NewICSOptions(opts, lang, now)
+		if err != nil {
+			return "", err
+		}
+
+		var b strings.Builder
+		if _, err := o.Calendar(calEvents).WriteTo(&b); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
+
+// ICSEvent renders a single event as a VEVENT string,
+// using the location in opts for the time zone, stamped with now.
+func ICSEvent(
+	opts *hebcal.CalOptions,
+	now time.Time,
+) func(lang string, ev event.CalEvent) (string, error) {
+	return func(lang string, ev event.CalEvent) (string, error) {
+		o, err := NewICSOptions(opts, lang, now)
+		if err != nil {
+			return "", err
+		}
+
+		var b strings.Builder
+		cal := o.Calendar(nil)
+		if _, err := cal.WriteEvent(&b, o.Event(ev)); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
+
+// asCalEvents normalizes the slice types returned by [Hebcal]
+// and [TimedEvents] to a []event.CalEvent.
+func asCalEvents(events any) ([]event.CalEvent, error) {
+	switch events := events.(type) {
+	case []event.CalEvent:
+		return events, nil
+
+	case []hebcal.TimedEvent:
+		result := make([]event.CalEvent, len(events))
+		for i, ev := range events {
+			result[i] = ev
+		}
+		return result, nil
+
+	default:
+		return nil, fmt.Errorf(
+			"expected []event.CalEvent or []hebcal.TimedEvent, got %T",
+			events,
+		)
+	}
+}
@@ -0,0 +1,237 @@
+package templating_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hebcal/hdate"
+	"github.com/hebcal/hebcal-go/event"
+	"github.com/hebcal/hebcal-go/hebcal"
+	"github.com/hebcal/hebcal-go/zmanim"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/ical"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestNewICSOptions(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Location *zmanim.Location
+		WantTZ   string
+		WantName string
+		Err      string
+	}{
+		{Name: "no location", WantTZ: "UTC"},
+		{
+			Name:     "city",
+			Location: zmanim.LookupCity("Phoenix"),
+			WantTZ:   "America/Phoenix",
+			WantName: "Phoenix",
+		},
+		{
+			Name:     "invalid TZ",
+			Location: &zmanim.Location{TimeZoneId: "INVALID ZONE"},
+			Err:      "unknown time zone INVALID ZONE",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			opts := &hebcal.CalOptions{Location: c.Location}
+			stamp := time.Date(2025, 12, 14, 8, 30, 0, 0, time.UTC)
+			got, err := templating.NewICSOptions(opts, "he", stamp)
+			test.CheckErr(t, err, c.Err)
+			if c.Err != "" {
+				return
+			}
+			test.CheckString(t, "TZ", c.WantTZ, got.TZ.String())
+			test.CheckString(t, "LocationName", c.WantName, got.LocationName)
+			test.CheckString(t, "Language", "he", got.Language)
+			test.CheckComparable(
+				t, "CandleAlarm", templating.DefaultCandleAlarm, got.CandleAlarm)
+			test.CheckComparable(t, "Stamp", stamp, got.Stamp)
+		})
+	}
+}
+
+func TestICSOptions_Event(t *testing.T) {
+	hd := hdate.New(5786, hdate.Kislev, 29)
+	phoenix, err := time.LoadLocation("America/Phoenix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &hebcal.CalOptions{Location: zmanim.LookupCity("Phoenix")}
+	o := templating.ICSOptions{
+		Language:     "en",
+		TZ:           phoenix,
+		LocationName: "Phoenix",
+		CandleAlarm:  15 * time.Minute,
+	}
+	candleTime := time.Date(2025, time.December, 19, 17, 5, 0, 0, phoenix)
+	chanukah := event.HolidayEvent{
+		Date:  hd,
+		Desc:  "Chanukah: 6 Candles",
+		Flags: event.CHANUKAH_CANDLES,
+	}
+
+	cases := []struct {
+		Name  string
+		Event event.CalEvent
+		Want  ical.Event
+	}{
+		{
+			Name:  "all-day",
+			Event: event.NewHebrewDateEvent(hd),
+			Want: ical.Event{
+				UID:     ical.UID(templating.ICSDomain, "2025-12-19", "29th of Kislev, 5786"),
+				Summary: "29th of Kislev, 5786",
+				Start:   time.Date(2025, time.December, 19, 0, 0, 0, 0, time.UTC),
+				AllDay:  true,
+			},
+		},
+		{
+			Name: "candle lighting",
+			Event: hebcal.NewTimedEvent(
+				hd, "Candle lighting", event.LIGHT_CANDLES, candleTime, 0, nil, opts),
+			Want: ical.Event{
+				UID:     ical.UID(templating.ICSDomain, "2025-12-19", "Candle lighting", "Phoenix"),
+				Summary: "Candle lighting",
+				Start:   candleTime,
+				Alarm:   15 * time.Minute,
+			},
+		},
+		{
+			Name: "linked event",
+			Event: hebcal.NewTimedEvent(
+				hd, chanukah.Desc, event.CHANUKAH_CANDLES, candleTime, 0, chanukah, opts),
+			Want: ical.Event{
+				UID:     ical.UID(templating.ICSDomain, "2025-12-19", "Chanukah: 6 Candles", "Phoenix"),
+				Summary: "Chanukah: 6 Candles",
+				Start:   candleTime,
+				Alarm:   15 * time.Minute,
+			},
+		},
+		{
+			Name: "zman without alarm",
+			Event: hebcal.NewTimedEvent(
+				hd, "Sunrise", event.ZMANIM, candleTime, 0, nil, opts),
+			Want: ical.Event{
+				UID:     ical.UID(templating.ICSDomain, "2025-12-19", "Sunrise", "Phoenix"),
+				Summary: "Sunrise",
+				Start:   candleTime,
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got := o.Event(c.Event)
+			test.CheckString(t, "UID", c.Want.UID, got.UID)
+			test.CheckString(t, "Summary", c.Want.Summary, got.Summary)
+			test.CheckComparable(t, "AllDay", c.Want.AllDay, got.AllDay)
+			test.CheckComparable(t, "Alarm", c.Want.Alarm, got.Alarm)
+			if !c.Want.Start.Equal(got.Start) {
+				t.Errorf("Start's did not match - want:\n%s\ngot:\n%s",
+					c.Want.Start, got.Start)
+			}
+		})
+	}
+}
+
+func TestICSCalendar(t *testing.T) {
+	now := time.Date(2025, 12, 14, 8, 30, 0, 0, time.UTC)
+	opts := &hebcal.CalOptions{
+		Location:       zmanim.LookupCity("Phoenix"),
+		CandleLighting: true,
+	}
+	hd := hdate.New(5786, hdate.Kislev, 29)
+	events, err := templating.TimedEvents(opts, nil)(hd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name   string
+		Events any
+		Want   string
+		Err    string
+	}{
+		{
+			Name:   "TimedEvents",
+			Events: events,
+			Want: `BEGIN:VCALENDAR
+...
+X-WR-CALNAME:Hebcal Phoenix
+X-WR-TIMEZONE:America/Phoenix
+BEGIN:VTIMEZONE
+...
+DTSTART;TZID=America/Phoenix:20251219T170500
+SUMMARY:Chanukah: 6 Candles
+...
+DTSTART;TZID=America/Phoenix:20251219T170500
+SUMMARY:Candle lighting
+...
+END:VCALENDAR
+`,
+		},
+		{
+			Name:   "wrong type",
+			Events: "INVALID",
+			Err:    "expected []event.CalEvent or []hebcal.TimedEvent, got string",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := templating.ICSCalendar(opts, now)("en", c.Events)
+			test.CheckErr(t, err, c.Err)
+			want := strings.ReplaceAll(c.Want, "\n", "\r\n")
+			test.CheckEllipsis(t, "output", want, got)
+		})
+	}
+}
+
+func TestICSEvent(t *testing.T) {
+	opts := &hebcal.CalOptions{}
+	now := time.Date(2025, 12, 14, 8, 30, 0, 0, time.UTC)
+	hd := hdate.New(5786, hdate.Kislev, 29)
+	got, err := templating.ICSEvent(opts, now)("en", event.NewHebrewDateEvent(hd))
+	test.CheckErr(t, err, "")
+
+	want := strings.ReplaceAll(`BEGIN:VEVENT
+UID:...@hebcalfmt
+DTSTAMP:20251214T083000Z
+DTSTART;VALUE=DATE:20251219
+DTEND;VALUE=DATE:20251220
+SUMMARY:29th of Kislev\, 5786
+TRANSP:TRANSPARENT
+END:VEVENT
+`, "\n", "\r\n")
+	test.CheckEllipsis(t, "output", want, got)
+
+	t.Run("invalid TZ", func(t *testing.T) {
+		opts := &hebcal.CalOptions{
+			Location: &zmanim.Location{TimeZoneId: "INVALID ZONE"},
+		}
+		_, err := templating.ICSEvent(opts, now)("en", event.NewHebrewDateEvent(hd))
+		test.CheckErr(t, err, "unknown time zone INVALID ZONE")
+	})
+}
+
+func TestICSFuncs_stampedWithNow(t *testing.T) {
+	test.Logger(t)
+	cfg := &config.Config{
+		City: "Phoenix",
+		Now:  time.Date(2025, 12, 14, 8, 30, 0, 0, time.UTC),
+	}
+	tmpl, data, err := templating.BuildDataText(cfg, fstest.MapFS{}, "<expr>",
+		`{{icsCalendar "en" (timedEvents (hdateFromTime $.now))}}`)
+	test.CheckErr(t, err, "")
+
+	var buf strings.Builder
+	test.CheckErr(t, tmpl.Execute(&buf, data), "")
+	if !strings.Contains(buf.String(), "\r\nDTSTAMP:20251214T083000Z\r\n") {
+		t.Errorf("expected the calendar to be stamped with $.now, got:\n%s", buf.String())
+	}
+}
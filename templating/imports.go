@@ -0,0 +1,277 @@
+package templating
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/fsys"
+)
+
+//go:embed library
+var libraryFiles embed.FS
+
+// Library holds the templates built into hebcalfmt,
+// which templates can import; see [ImportFuncs].
+var Library fs.FS = mustSub(libraryFiles, "library")
+
+// UserTemplates holds the user's own templates, if set.
+// They are searched for imports after the TemplatePath of the config,
+// and before the [Library].
+// The cli sets it to the templates directory of the user config,
+// like $HOME/.config/hebcalfmt/templates.
+var UserTemplates fs.FS
+
+// ImportFuncs is a map of templating functions
+// for sharing `define` blocks between template files.
+//
+//	{{import "dates.tmpl"}}
+//
+// parses the named file before the rest of the template,
+// so that its blocks can be used with `{{template "hdate" .}}`.
+// Blocks defined by the importing template take precedence.
+// The file is found on the search path; see [NewSearchPath].
+// Each file is imported once, even if several templates import it.
+//
+// Imports are resolved when the template is parsed,
+// so the name must be a quoted string.
+// When the template runs, import prints nothing.
+var ImportFuncs = map[string]any{
+	"import": func(name string) string { return "" },
+}
+
+// SearchPath is an [fs.FS] which opens each file
+// from the first of its file systems which has it.
+type SearchPath []fs.FS
+
+var _ fs.FS = SearchPath(nil)
+
+// Open opens name from the first file system which has it.
+// Errors other than [fs.ErrNotExist], like an attempt
+// to access files outside of a [fsys.WrapFS], end the search.
+func (sp SearchPath) Open(name string) (fs.File, error) {
+	for _, files := range sp {
+		f, err := files.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+// NewSearchPath returns where the imports of the template at tmplPath
+// are found, in order:
+//
+//  1. the directory of the template, in files
+//  2. the TemplatePath directories of the config,
+//     relative to the config file which set them
+//  3. [UserTemplates], if set
+//  4. the [Library]
+//
+// Each directory is a [fsys.WrapFS],
+// so imports cannot reach outside of it.
+func NewSearchPath(cfg *config.Config, files fs.FS, tmplPath string) SearchPath {
+	sp := SearchPath{fsys.WrapFS{FS: files, BaseDir: filepath.Dir(tmplPath)}}
+
+	cfgFiles := cfg.FilesFor("template_path")
+	if cfgFiles == nil {
+		cfgFiles = files
+	}
+	for _, dir := range cfg.TemplatePath {
+		sp = append(sp, fsys.WrapFS{FS: cfgFiles, BaseDir: dir})
+	}
+
+	if UserTemplates != nil {
+		sp = append(sp, UserTemplates)
+	}
+	return append(sp, Library)
+}
+
+// importer parses templates along with the files they import.
+type importer struct {
+	imports fs.FS
+	tmpl    *template.Template
+
+	// loaded holds the imported files which were parsed.
+	loaded map[string]bool
+
+	// chain holds the names of the templates being parsed,
+	// starting with the one which was not imported.
+	chain []string
+}
+
+// importRef is an import action found in a template.
+type importRef struct {
+	name     string
+	location string
+	pos      parse.Pos
+}
+
+// parse parses text as the template called name,
+// after parsing the files it imports.
+func (im *importer) parse(name, text string) error {
+	refs, err := im.findImports(name, text)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if im.loaded[ref.name] {
+			continue
+		}
+		if slices.Contains(im.chain, ref.name) {
+			return fmt.Errorf("template: %s: import cycle: %s",
+				ref.location,
+				strings.Join(append(im.chain, ref.name), " -> "))
+		}
+		if im.imports == nil {
+			return fmt.Errorf("template: %s: import %q: imports are not available",
+				ref.location, ref.name)
+		}
+
+		data, err := fs.ReadFile(im.imports, ref.name)
+		if err != nil {
+			return fmt.Errorf("template: %s: import: %w", ref.location, err)
+		}
+		im.chain = append(im.chain, ref.name)
+		err = im.parse(ref.name, string(data))
+		im.chain = im.chain[:len(im.chain)-1]
+		if err != nil {
+			return err
+		}
+		im.loaded[ref.name] = true
+	}
+
+	t := im.tmpl
+	if name != t.Name() {
+		t = t.New(name)
+	}
+	_, err = t.Parse(text)
+	return err
+}
+
+// findImports parses text in a copy of the template,
+// and returns the files which it imports.
+func (im *importer) findImports(name, text string) ([]importRef, error) {
+	probe, err := im.tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := probe.New(name).Parse(text); err != nil {
+		return nil, err
+	}
+
+	var refs []importRef
+	for _, t := range probe.Templates() {
+		if t.Tree == nil || t.Tree.ParseName != name || t.Tree.Root == nil {
+			continue
+		}
+		if err := walkImports(t.Tree, t.Tree.Root, &refs); err != nil {
+			return nil, err
+		}
+	}
+	// Templates() is unordered; import in the order of the source.
+	slices.SortStableFunc(refs, func(a, b importRef) int {
+		return int(a.pos - b.pos)
+	})
+	return refs, nil
+}
+
+// walkImports appends the import actions under node to refs.
+func walkImports(tree *parse.Tree, node parse.Node, refs *[]importRef) error {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, n := range node.Nodes {
+			if err := walkImports(tree, n, refs); err != nil {
+				return err
+			}
+		}
+
+	case *parse.ActionNode:
+		return walkImports(tree, node.Pipe, refs)
+
+	case *parse.TemplateNode:
+		if node.Pipe != nil {
+			return walkImports(tree, node.Pipe, refs)
+		}
+
+	case *parse.IfNode:
+		return walkBranch(tree, &node.BranchNode, refs)
+	case *parse.RangeNode:
+		return walkBranch(tree, &node.BranchNode, refs)
+	case *parse.WithNode:
+		return walkBranch(tree, &node.BranchNode, refs)
+
+	case *parse.PipeNode:
+		if node == nil {
+			return nil
+		}
+		for _, cmd := range node.Cmds {
+			if err := walkImports(tree, cmd, refs); err != nil {
+				return err
+			}
+		}
+
+	case *parse.ChainNode:
+		return walkImports(tree, node.Node, refs)
+
+	case *parse.CommandNode:
+		if ident, ok := node.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "import" {
+			location, _ := tree.ErrorContext(node)
+			if len(node.Args) != 2 {
+				return fmt.Errorf("template: %s: import expects one file name", location)
+			}
+			name, ok := node.Args[1].(*parse.StringNode)
+			if !ok {
+				return fmt.Errorf(
+					"template: %s: import expects a quoted file name, got %s",
+					location, node.Args[1])
+			}
+			*refs = append(*refs, importRef{
+				name:     name.Text,
+				location: location,
+				pos:      node.Position(),
+			})
+			return nil
+		}
+		for _, arg := range node.Args {
+			if err := walkImports(tree, arg, refs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkBranch walks the parts of an if, range or with action.
+func walkBranch(tree *parse.Tree, node *parse.BranchNode, refs *[]importRef) error {
+	for _, n := range []parse.Node{node.Pipe, node.List, node.ElseList} {
+		if err := walkImports(tree, n, refs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mustSub returns the subdirectory dir of files,
+// which must exist at compile time.
+func mustSub(files fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(files, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,122 @@
+package templating_test
+
+import (
+	"bytes"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/fsys"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestImports(t *testing.T) {
+	file := func(s string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(s)}
+	}
+	files := fstest.MapFS{
+		"tmpl/greet.tmpl":    file(`{{define "greet"}}Shalom, {{.}}{{end}}`),
+		"tmpl/shared.tmpl":   file(`{{import "greet.tmpl"}}{{define "shared"}}[{{template "greet" .}}]{{end}}`),
+		"tmpl/cycleA.tmpl":   file(`{{import "cycleB.tmpl"}}`),
+		"tmpl/cycleB.tmpl":   file(`{{import "cycleA.tmpl"}}`),
+		"tmpl/secret.tmpl":   file(`{{define "greet"}}secret{{end}}`),
+		"config/lib/x.tmpl":  file(`{{define "x"}}from template_path{{end}}`),
+		"config/tmpl/x.tmpl": file(`{{define "x"}}shadowed{{end}}`),
+		"user/u.tmpl":        file(`{{define "u"}}from the user{{end}}`),
+
+		"tmpl/simple.tmpl": file(`{{import "greet.tmpl"}}{{template "greet" "Reuven"}}`),
+		"tmpl/diamond.tmpl": file(
+			`{{- import "greet.tmpl"}}{{import "shared.tmpl" -}}
+			{{template "shared" "Leah"}}`),
+		"tmpl/override.tmpl": file(
+			`{{import "greet.tmpl"}}{{define "greet"}}Hi, {{.}}{{end}}{{template "greet" "Dan"}}`),
+		"tmpl/nested.tmpl": file(
+			`{{if true}}{{range $i := list 1}}{{import "greet.tmpl"}}{{end}}{{end}}` +
+				`{{template "greet" "Gad"}}`),
+		"tmpl/paths.tmpl": file(
+			`{{import "x.tmpl"}}{{import "u.tmpl"}}{{template "x"}}, {{template "u"}}`),
+		"tmpl/library.tmpl": file(
+			`{{import "dates.tmpl"}}{{template "weekday" (map "hdate" (hdateNew 5786 $.hdate.Shvat 3) "base" $)}}`),
+		"tmpl/cycle.tmpl":    file(`{{import "cycleA.tmpl"}}`),
+		"tmpl/missing.tmpl":  file(`{{import "nonexistent.tmpl"}}`),
+		"tmpl/outside.tmpl":  file("\n  {{import \"../config/lib/x.tmpl\"}}"),
+		"tmpl/variable.tmpl": file(`{{$name := "greet.tmpl"}}{{import $name}}`),
+		"tmpl/arity.tmpl":    file(`{{import}}`),
+		"config/hebcal.json": file(`{}`),
+	}
+	cases := []struct {
+		Name    string
+		Want    string
+		WantErr string
+	}{
+		{Name: "simple", Want: "Shalom, Reuven"},
+		{Name: "diamond", Want: "[Shalom, Leah]"},
+		{Name: "override", Want: "Hi, Dan"},
+		{Name: "nested", Want: "Shalom, Gad"},
+		{Name: "paths", Want: "from template_path, from the user"},
+		{Name: "library", Want: "Wednesday"},
+		{
+			Name:    "cycle",
+			WantErr: "template: cycleB.tmpl:1:2: import cycle: tmpl/cycle.tmpl -> cycleA.tmpl -> cycleB.tmpl -> cycleA.tmpl",
+		},
+		{
+			Name:    "missing",
+			WantErr: "template: tmpl/missing.tmpl:1:2: import: open nonexistent.tmpl: file does not exist",
+		},
+		{
+			Name:    "outside",
+			WantErr: "template: tmpl/outside.tmpl:2:4: import: attempted access outside of the BaseDir tmpl: open ../config/lib/x.tmpl",
+		},
+		{
+			Name:    "variable",
+			WantErr: "template: tmpl/variable.tmpl:1:27: import expects a quoted file name, got $name",
+		},
+		{
+			Name:    "arity",
+			WantErr: "template: tmpl/arity.tmpl:1:2: import expects one file name",
+		},
+	}
+
+	saved := templating.UserTemplates
+	templating.UserTemplates = fsys.WrapFS{FS: files, BaseDir: "user"}
+	t.Cleanup(func() { templating.UserTemplates = saved })
+
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.Logger(t)
+			cfg := config.Default
+			cfg.TemplatePath = config.FileList{"lib"}
+			cfg.FS = fsys.WrapFS{FS: files, BaseDir: "config"}
+
+			tmpl, data, err := templating.BuildData(&cfg, files, "tmpl/"+c.Name+".tmpl")
+			test.CheckErr(t, err, c.WantErr)
+			if c.WantErr != "" {
+				return
+			}
+
+			var buf bytes.Buffer
+			err = tmpl.Execute(&buf, data)
+			test.CheckErr(t, err, "")
+			test.CheckString(t, "output", c.Want, buf.String())
+		})
+	}
+}
+
+func TestSearchPath_Open(t *testing.T) {
+	first := fstest.MapFS{"a.tmpl": &fstest.MapFile{Data: []byte("first")}}
+	second := fstest.MapFS{
+		"a.tmpl": &fstest.MapFile{Data: []byte("second")},
+		"b.tmpl": &fstest.MapFile{Data: []byte("second")},
+	}
+	sp := templating.SearchPath{first, second}
+
+	for name, want := range map[string]string{"a.tmpl": "first", "b.tmpl": "second"} {
+		data, err := fs.ReadFile(sp, name)
+		test.CheckErr(t, err, "")
+		test.CheckString(t, name, want, string(data))
+	}
+	_, err := sp.Open("c.tmpl")
+	test.CheckErr(t, err, "open c.tmpl: file does not exist")
+}
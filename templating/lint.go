@@ -0,0 +1,369 @@
+package templating
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template/parse"
+	"unicode/utf8"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/warning"
+)
+
+// Kinds of problems reported by [Lint].
+var (
+	ErrUnknownFunc = errors.New("unknown function")
+	ErrNumArgs     = errors.New("wrong number of args")
+	ErrArgType     = errors.New("wrong type for argument")
+	ErrUnknownKey  = errors.New("unknown key")
+	ErrUnusedVar   = errors.New("declared and not used")
+)
+
+// LintError is a problem found by [Lint] at a place in a template.
+// Line and Col are 1-based, and Col counts runes.
+type LintError struct {
+	FileName string
+	Line     int
+	Col      int
+	Err      error
+}
+
+var _ error = LintError{}
+
+func (e LintError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %v", e.FileName, e.Line, e.Col, e.Err)
+}
+
+func (e LintError) Unwrap() error { return e.Err }
+
+// Lint checks text, the template called name, without running it,
+// for problems which would otherwise only show up
+// when the template reaches them:
+//
+//   - calls to unknown functions, with a suggestion
+//     if a known function is spelled similarly
+//   - calls with the wrong number of arguments,
+//     or with a quoted string, number or boolean
+//     where the function takes another type
+//   - references to keys of the data which [BuildData] does not provide,
+//     like `$.dateRnage`, or to parameters like `$.params.city`
+//     which the [FrontMatter] does not declare
+//   - variables which are declared, but never used
+//
+// `$.` is only checked outside of `define` blocks,
+// since inside of them `$` is whatever the block was passed.
+// Imported files are not checked; lint them separately.
+//
+// Each problem is returned as a [LintError] in the warnings.
+// An error is returned if text cannot be parsed.
+func Lint(text []byte, name string) (warning.Warnings, error) {
+	fm, err := ParseFrontMatter(text, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the check for unknown functions,
+	// so that all of them are reported rather than only the first.
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	trees := make(map[string]*parse.Tree)
+	if _, err := tree.Parse(string(text), "", "", trees); err != nil {
+		return nil, err
+	}
+
+	l := linter{
+		text:   string(text),
+		name:   name,
+		funcs:  Funcs(),
+		params: make(map[string]bool),
+	}
+	for _, p := range fm.Params {
+		l.params[p.Name] = true
+	}
+	for _, tName := range slices.Sorted(maps.Keys(trees)) {
+		t := trees[tName]
+		l.isMain = tName == name
+		l.push()
+		l.walk(t.Root)
+		l.pop()
+	}
+
+	slices.SortStableFunc(l.warns, func(a, b lintWarn) int {
+		return int(a.pos - b.pos)
+	})
+	var warns warning.Warnings
+	for _, w := range l.warns {
+		warns.Append(w.err)
+	}
+	return warns, nil
+}
+
+// linter holds the state of [Lint] while it walks the parse trees.
+type linter struct {
+	text   string
+	name   string
+	funcs  map[string]any
+	params map[string]bool
+
+	// isMain is whether `$` is the data,
+	// which is true outside of define blocks.
+	isMain bool
+
+	// scopes holds the variables declared so far,
+	// in the scopes which enclose the node being walked.
+	scopes [][]*lintVar
+
+	warns []lintWarn
+}
+
+// lintVar is a variable declared in a template.
+type lintVar struct {
+	name string
+	pos  parse.Pos
+	used bool
+}
+
+// lintWarn is a problem, and its offset for sorting.
+type lintWarn struct {
+	pos parse.Pos
+	err LintError
+}
+
+// warn records err at the offset pos of the text.
+func (l *linter) warn(pos parse.Pos, err error) {
+	before := l.text[:pos]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	l.warns = append(l.warns, lintWarn{pos: pos, err: LintError{
+		FileName: l.name,
+		Line:     1 + strings.Count(before, "\n"),
+		Col:      1 + utf8.RuneCountInString(before[lineStart:]),
+		Err:      err,
+	}})
+}
+
+// push starts a scope for variables.
+func (l *linter) push() {
+	l.scopes = append(l.scopes, nil)
+}
+
+// pop ends the innermost scope, reporting its unused variables.
+func (l *linter) pop() {
+	for _, v := range l.scopes[len(l.scopes)-1] {
+		// Like in Go, $_ marks a value which is not needed,
+		// as in {{range $_, $e := ...}}.
+		if !v.used && v.name != "$_" {
+			l.warn(v.pos, fmt.Errorf("%s %w", v.name, ErrUnusedVar))
+		}
+	}
+	l.scopes = l.scopes[:len(l.scopes)-1]
+}
+
+// use marks the variable called name as used.
+// The parser has already checked that it was declared.
+func (l *linter) use(name string) {
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		scope := l.scopes[i]
+		for j := len(scope) - 1; j >= 0; j-- {
+			if scope[j].name == name {
+				scope[j].used = true
+				return
+			}
+		}
+	}
+}
+
+func (l *linter) walk(node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, n := range node.Nodes {
+			l.walk(n)
+		}
+
+	case *parse.ActionNode:
+		l.walkPipe(node.Pipe)
+
+	case *parse.TemplateNode:
+		l.walkPipe(node.Pipe)
+
+	case *parse.IfNode:
+		l.walkBranch(&node.BranchNode)
+	case *parse.RangeNode:
+		l.walkBranch(&node.BranchNode)
+	case *parse.WithNode:
+		l.walkBranch(&node.BranchNode)
+	}
+}
+
+// walkBranch walks the parts of an if, range or with action.
+// The variables declared by its pipeline last until its end,
+// and those declared inside of each list last until the end of the list.
+func (l *linter) walkBranch(node *parse.BranchNode) {
+	l.push()
+	defer l.pop()
+	l.walkPipe(node.Pipe)
+	for _, list := range []*parse.ListNode{node.List, node.ElseList} {
+		l.push()
+		l.walk(list)
+		l.pop()
+	}
+}
+
+// walkPipe checks the commands of a pipeline,
+// and then declares its variables.
+func (l *linter) walkPipe(pipe *parse.PipeNode) {
+	if pipe == nil {
+		return
+	}
+	for i, cmd := range pipe.Cmds {
+		l.walkCommand(cmd, i > 0)
+	}
+	if pipe.IsAssign {
+		return
+	}
+	for _, v := range pipe.Decl {
+		scope := &l.scopes[len(l.scopes)-1]
+		*scope = append(*scope, &lintVar{name: v.Ident[0], pos: v.Pos})
+	}
+}
+
+// walkCommand checks a command of a pipeline.
+// If piped, the command is passed the result of the one before it.
+func (l *linter) walkCommand(cmd *parse.CommandNode, piped bool) {
+	args := cmd.Args
+	if ident, ok := args[0].(*parse.IdentifierNode); ok {
+		l.checkCall(ident, args[1:], piped)
+		args = args[1:]
+	}
+	for _, arg := range args {
+		l.walkArg(arg)
+	}
+}
+
+// walkArg checks an operand of a command.
+func (l *linter) walkArg(arg parse.Node) {
+	switch arg := arg.(type) {
+	case *parse.IdentifierNode:
+		// A function called without arguments.
+		l.checkCall(arg, nil, false)
+
+	case *parse.VariableNode:
+		if arg.Ident[0] != "$" {
+			l.use(arg.Ident[0])
+		} else if l.isMain && len(arg.Ident) > 1 {
+			l.checkKeys(arg.Pos, arg.Ident[1:])
+		}
+
+	case *parse.PipeNode:
+		l.walkPipe(arg)
+
+	case *parse.ChainNode:
+		l.walkArg(arg.Node)
+	}
+}
+
+// checkCall checks a call of the function named by ident with the args,
+// and the result of the previous command if piped.
+func (l *linter) checkCall(ident *parse.IdentifierNode, args []parse.Node, piped bool) {
+	fn, ok := l.funcs[ident.Ident]
+	if !ok {
+		fn, ok = builtinFuncs[ident.Ident]
+	}
+	if !ok {
+		names := slices.Collect(maps.Keys(l.funcs))
+		names = slices.AppendSeq(names, maps.Keys(builtinFuncs))
+		l.warn(ident.Pos, fmt.Errorf("%w %q%s",
+			ErrUnknownFunc, ident.Ident, config.DidYouMean(ident.Ident, names)))
+		return
+	}
+
+	typ := reflect.TypeOf(fn)
+	numArgs := len(args)
+	if piped {
+		numArgs++
+	}
+	switch numIn := typ.NumIn(); {
+	case typ.IsVariadic() && numArgs < numIn-1:
+		l.warn(ident.Pos, fmt.Errorf("%w for %s: want at least %d got %d",
+			ErrNumArgs, ident.Ident, numIn-1, numArgs))
+		return
+	case !typ.IsVariadic() && numArgs != numIn:
+		l.warn(ident.Pos, fmt.Errorf("%w for %s: want %d got %d",
+			ErrNumArgs, ident.Ident, numIn, numArgs))
+		return
+	}
+
+	for i, arg := range args {
+		var param reflect.Type
+		if typ.IsVariadic() && i >= typ.NumIn()-1 {
+			param = typ.In(typ.NumIn() - 1).Elem()
+		} else {
+			param = typ.In(i)
+		}
+		if !acceptsLiteral(param, arg) {
+			l.warn(arg.Position(), fmt.Errorf("%w %d of %s: expected %s, got %s",
+				ErrArgType, i+1, ident.Ident, param, arg))
+		}
+	}
+}
+
+// acceptsLiteral reports whether a parameter of type param
+// can be passed arg, if arg is a quoted string, number or boolean.
+// Other kinds of args are only known when the template runs,
+// and are assumed to fit.
+func acceptsLiteral(param reflect.Type, arg parse.Node) bool {
+	if param == reflect.TypeFor[reflect.Value]() ||
+		(param.Kind() == reflect.Interface && param.NumMethod() == 0) {
+		return true
+	}
+	switch arg.(type) {
+	case *parse.StringNode:
+		return param.Kind() == reflect.String
+	case *parse.BoolNode:
+		return param.Kind() == reflect.Bool
+	case *parse.NumberNode:
+		switch param.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Uintptr, reflect.Float32, reflect.Float64,
+			reflect.Complex64, reflect.Complex128:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// checkKeys checks the keys of a reference to the data, like `$.z.Sunset`,
+// given as idents like `z`, `Sunset`.
+// Only the first key is checked, and the parameter after `$.params`.
+func (l *linter) checkKeys(pos parse.Pos, idents []string) {
+	if !slices.Contains(DataKeys, idents[0]) {
+		var options []string
+		for _, key := range DataKeys {
+			options = append(options, "$."+key)
+		}
+		l.warn(pos, fmt.Errorf("%w $.%s%s",
+			ErrUnknownKey, idents[0], config.DidYouMean("$."+idents[0], options)))
+		return
+	}
+
+	if idents[0] != "params" || len(idents) < 2 || l.params[idents[1]] {
+		return
+	}
+	var options []string
+	for _, name := range slices.Sorted(maps.Keys(l.params)) {
+		options = append(options, "$.params."+name)
+	}
+	hint := config.DidYouMean("$.params."+idents[1], options)
+	if hint == "" {
+		hint = "; declare it in the front matter"
+	}
+	l.warn(pos, fmt.Errorf("%w $.params.%s%s", ErrUnknownKey, idents[1], hint))
+}
@@ -0,0 +1,133 @@
+package templating_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestLint(t *testing.T) {
+	cases := []struct {
+		Name string
+		Text string
+		Want string
+		Is   error
+		Err  string
+	}{
+		{Name: "empty"},
+		{
+			Name: "ok",
+			Text: "{{- /*---\nparams:\n  - name: city\n---*/ -}}\n" +
+				`{{$d := hdateFromTime $.now}}{{range $i, $e := $.dateRange.Days}}{{$i}}{{$e}}{{end}}` +
+				`{{$d.Next | printf "%v"}}{{$.params.city}}{{$.z.Sunset.Format $.time.Kitchen}}` +
+				`{{define "x"}}{{$.anything}}{{end}}`,
+		},
+		{
+			Name: "unknown function",
+			Text: "line 1\n  {{hdateFromTim $.now}}",
+			Want: `t.tmpl:2:5: unknown function "hdateFromTim"; did you mean "hdateFromTime"?`,
+			Is:   templating.ErrUnknownFunc,
+		},
+		{
+			Name: "unknown function without suggestion",
+			Text: "{{print (xyzzy)}}",
+			Want: `t.tmpl:1:10: unknown function "xyzzy"`,
+			Is:   templating.ErrUnknownFunc,
+		},
+		{
+			Name: "too many args",
+			Text: "{{hdateFromTime $.now 3}}",
+			Want: "t.tmpl:1:3: wrong number of args for hdateFromTime: want 1 got 2",
+			Is:   templating.ErrNumArgs,
+		},
+		{
+			Name: "too many args piped",
+			Text: "{{$.now | hdateFromTime $.now}}",
+			Want: "t.tmpl:1:11: wrong number of args for hdateFromTime: want 1 got 2",
+			Is:   templating.ErrNumArgs,
+		},
+		{
+			Name: "too few args to builtin",
+			Text: "{{printf}}",
+			Want: "t.tmpl:1:3: wrong number of args for printf: want at least 1 got 0",
+			Is:   templating.ErrNumArgs,
+		},
+		{
+			Name: "function without args as an argument",
+			Text: "{{print hdateFromTime}}",
+			Want: "t.tmpl:1:9: wrong number of args for hdateFromTime: want 1 got 0",
+			Is:   templating.ErrNumArgs,
+		},
+		{
+			Name: "wrong literal type",
+			Text: `{{hdateFromTime "2025-12-14"}}{{printf 5}}`,
+			Want: "t.tmpl:1:17: wrong type for argument 1 of hdateFromTime: expected time.Time, got \"2025-12-14\"\n" +
+				"t.tmpl:1:40: wrong type for argument 1 of printf: expected string, got 5",
+			Is: templating.ErrArgType,
+		},
+		{
+			Name: "unknown key",
+			Text: "{{$.dateRnage}}",
+			Want: `t.tmpl:1:4: unknown key $.dateRnage; did you mean "$.dateRange"?`,
+			Is:   templating.ErrUnknownKey,
+		},
+		{
+			Name: "undeclared param",
+			Text: "{{$.params.city}}",
+			Want: "t.tmpl:1:4: unknown key $.params.city; declare it in the front matter",
+			Is:   templating.ErrUnknownKey,
+		},
+		{
+			Name: "misspelled param",
+			Text: "{{- /*---\nparams:\n  - name: city\n---*/ -}}\n{{$.params.cty}}",
+			Want: `t.tmpl:5:4: unknown key $.params.cty; did you mean "$.params.city"?`,
+			Is:   templating.ErrUnknownKey,
+		},
+		{
+			Name: "unused variables",
+			Text: "{{$a := 1}}{{$b := 2}}{{$b = 3}}{{if true}}{{$c := 1}}{{end}}" +
+				"{{range $i, $e := $.dateRange.Days}}{{$e}}{{end}}",
+			Want: "t.tmpl:1:3: $a declared and not used\n" +
+				"t.tmpl:1:14: $b declared and not used\n" +
+				"t.tmpl:1:46: $c declared and not used\n" +
+				"t.tmpl:1:70: $i declared and not used",
+			Is: templating.ErrUnusedVar,
+		},
+		{
+			Name: "blank variable",
+			Text: "{{range $_, $e := $.dateRange.Days}}{{$e}}{{end}}{{$_ := 1}}",
+		},
+		{
+			Name: "shadowed variable",
+			Text: "{{$a := 1}}{{with $a := 2}}{{$a}}{{end}}",
+			Want: "t.tmpl:1:3: $a declared and not used",
+			Is:   templating.ErrUnusedVar,
+		},
+		{
+			Name: "in define",
+			Text: `{{define "x"}}{{$a := nosuch}}{{end}}`,
+			Want: "t.tmpl:1:17: $a declared and not used\n" +
+				`t.tmpl:1:23: unknown function "nosuch"`,
+		},
+		{Name: "syntax error", Text: "{{if}}", Err: "template: t.tmpl:1: missing value for if"},
+		{Name: "undefined variable", Text: "{{$x}}", Err: `template: t.tmpl:1: undefined variable "$x"`},
+		{
+			Name: "invalid front matter",
+			Text: "{{- /*---\nparams: 5\n---*/ -}}",
+			Err:  "front matter of t.tmpl: json: cannot unmarshal number into Go struct field FrontMatter.params of type []templating.Param",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.Logger(t)
+			warns, err := templating.Lint([]byte(c.Text), "t.tmpl")
+			test.CheckErr(t, err, c.Err)
+			test.CheckErr(t, errors.Join(warns...), c.Want)
+			if c.Is != nil && !errors.Is(warns[0], c.Is) {
+				t.Errorf("expected the first warning to be %v", c.Is)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package templating
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ParamTypes lists the types which template parameters may have.
+// A [Param] without a type is a string.
+var ParamTypes = []string{"string", "int", "bool", "date", "hdate", "duration", "city"}
+
+// paramNameRe matches the names of parameters,
+// which must work as keys in templates, like `$.params.city`.
+var paramNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Param declares a parameter of a template in its [FrontMatter].
+// Values are given on the command line like `--param city=Jerusalem`,
+// and the template gets them converted to its Type in `$.params`.
+type Param struct {
+	Name string `json:"name"`
+
+	// Type is one of the [ParamTypes]; see [Param.Convert].
+	Type string `json:"type"`
+
+	// Default is used if no value is given.
+	// If there is no Default either, the parameter is left out of `$.params`.
+	Default any `json:"default"`
+
+	Description string `json:"description"`
+}
+
+// Convert parses value according to the Type of p:
+//
+//	string     as-is
+//	int        a whole number
+//	bool       true or false
+//	date       a Gregorian date like 2025-12-14, as a [time.Time]
+//	hdate      a Hebrew date like "15 Shevat 5786", or a Gregorian date,
+//	           as an [hdate.HDate]
+//	duration   a duration like 1h30m, as a [time.Duration]
+//	city       a city name, as a [zmanim.Location]
+func (p Param) Convert(value string) (any, error) {
+	switch p.Type {
+	case "", "string":
+		return value, nil
+	case "int":
+		return strconv.Atoi(value)
+	case "bool":
+		return strconv.ParseBool(value)
+	case "date":
+		return time.Parse(time.DateOnly, value)
+	case "hdate":
+		return AnniversaryDate(value)
+	case "duration":
+		return time.ParseDuration(value)
+	case "city":
+		return LookupCity(value)
+	}
+	return nil, fmt.Errorf("unknown type %q; expected one of %q", p.Type, ParamTypes)
+}
+
+// DefaultString returns the Default of p as a string,
+// or the empty string if there is none.
+func (p Param) DefaultString() string {
+	if p.Default == nil {
+		return ""
+	}
+	return fmt.Sprint(p.Default)
+}
+
+// check returns an error if the declaration of p is invalid.
+func (p Param) check() error {
+	if !paramNameRe.MatchString(p.Name) {
+		return fmt.Errorf(
+			"invalid parameter name %q; use letters, digits and underscores", p.Name)
+	}
+	if p.Type != "" && !slices.Contains(ParamTypes, p.Type) {
+		return fmt.Errorf("parameter %s: unknown type %q; expected one of %q",
+			p.Name, p.Type, ParamTypes)
+	}
+	if p.Default != nil {
+		if _, err := p.Convert(p.DefaultString()); err != nil {
+			return fmt.Errorf("parameter %s: invalid default: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// ParamValues converts the values given by name,
+// according to the declared Params of fm.
+// Params without a value get their Default, if any.
+// Values for undeclared names are errors.
+func (fm FrontMatter) ParamValues(values map[string]string) (map[string]any, error) {
+	result := make(map[string]any, len(fm.Params))
+	for _, p := range fm.Params {
+		value, ok := values[p.Name]
+		if !ok {
+			if p.Default == nil {
+				continue
+			}
+			value = p.DefaultString()
+		}
+		converted, err := p.Convert(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parameter %s: %w", p.Name, err)
+		}
+		result[p.Name] = converted
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if !slices.ContainsFunc(fm.Params, func(p Param) bool { return p.Name == name }) {
+			return nil, fmt.Errorf("unknown parameter: %s", name)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package templating_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestParam_Convert(t *testing.T) {
+	cases := []struct {
+		Type  string
+		Value string
+		Want  string
+		Err   string
+	}{
+		{Type: "", Value: "hi", Want: "hi"},
+		{Type: "string", Value: "hi", Want: "hi"},
+		{Type: "int", Value: "42", Want: "42"},
+		{Type: "int", Value: "4.2", Err: `strconv.Atoi: parsing "4.2": invalid syntax`},
+		{Type: "bool", Value: "true", Want: "true"},
+		{Type: "bool", Value: "yes", Err: `strconv.ParseBool: parsing "yes": invalid syntax`},
+		{Type: "date", Value: "2025-12-14", Want: "2025-12-14 00:00:00 +0000 UTC"},
+		{
+			Type:  "date",
+			Value: "12/14/2025",
+			Err:   `parsing time "12/14/2025" as "2006-01-02": cannot parse "12/14/2025" as "2006"`,
+		},
+		{Type: "hdate", Value: "15 Shevat 5786", Want: "15 Sh'vat 5786"},
+		{Type: "hdate", Value: "2026-02-02", Want: "15 Sh'vat 5786"},
+		{Type: "duration", Value: "1h30m", Want: "1h30m0s"},
+		{Type: "duration", Value: "90", Err: `time: missing unit in duration "90"`},
+		{Type: "city", Value: "Jerusalem", Want: "&{Jerusalem IL 31.76904 35.21633 Asia/Jerusalem}"},
+		{Type: "city", Value: "Atlantis", Err: `unknown city "Atlantis"`},
+		{
+			Type:  "datetime",
+			Value: "now",
+			Err:   `unknown type "datetime"; expected one of ["string" "int" "bool" "date" "hdate" "duration" "city"]`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s %s", c.Type, c.Value), func(t *testing.T) {
+			p := templating.Param{Name: "p", Type: c.Type}
+			got, err := p.Convert(c.Value)
+			test.CheckErr(t, err, c.Err)
+			if c.Err != "" {
+				return
+			}
+			test.CheckString(t, "value", c.Want, fmt.Sprint(got))
+		})
+	}
+}
+
+func TestFrontMatter_ParamValues(t *testing.T) {
+	fm := templating.FrontMatter{Params: []templating.Param{
+		{Name: "minutes", Type: "int", Default: 18.0},
+		{Name: "date", Type: "date"},
+	}}
+	cases := []struct {
+		Name   string
+		Values map[string]string
+		Want   string
+		Err    string
+	}{
+		{Name: "defaults", Want: "map[minutes:18]"},
+		{
+			Name:   "given",
+			Values: map[string]string{"minutes": "40", "date": "2025-12-14"},
+			Want:   "map[date:2025-12-14 00:00:00 +0000 UTC minutes:40]",
+		},
+		{
+			Name:   "invalid",
+			Values: map[string]string{"minutes": "forty"},
+			Err:    `invalid value for parameter minutes: strconv.Atoi: parsing "forty": invalid syntax`,
+		},
+		{
+			Name:   "unknown",
+			Values: map[string]string{"minutes": "40", "city": "Jerusalem"},
+			Err:    "unknown parameter: city",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			got, err := fm.ParamValues(c.Values)
+			test.CheckErr(t, err, c.Err)
+			if c.Err != "" {
+				return
+			}
+			test.CheckString(t, "values", c.Want, fmt.Sprint(got))
+		})
+	}
+}
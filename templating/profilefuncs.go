@@ -0,0 +1,59 @@
+package templating
+
+import (
+	"github.com/hebcal/hebcal-go/hebcal"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+)
+
+// ProfileFuncs builds a map of templating functions
+// for switching between the profiles of cfg.
+func ProfileFuncs(
+	cfg *config.Config,
+	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
+) map[string]any {
+	return map[string]any{
+		"useProfile": UseProfile(cfg, opts, extras),
+	}
+}
+
+// UseProfile tells template functions like hebcal, timedEvents and forDate
+// to use the settings of the named profile from cfg.
+// The profile is applied on top of cfg, like [config.Config.WithProfile].
+// Dates selected on opts, like with setDates or setYear, are kept.
+// The extras are replaced with those of the profile's events
+// and yahrzeit files, unless extras is nil.
+// Variables like `$.config` and `$.location` are not changed.
+func UseProfile(
+	cfg *config.Config,
+	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
+) func(name string) (any, error) {
+	return func(name string) (any, error) {
+		withProfile, err := cfg.WithProfile(name)
+		if err != nil {
+			return "", err
+		}
+		normalized, err := withProfile.Normalize()
+		if err != nil {
+			return "", err
+		}
+		newOpts, newExtras, err := normalized.CalOptionsWithExtras()
+		if err != nil {
+			return "", err
+		}
+
+		newOpts.Start = opts.Start
+		newOpts.End = opts.End
+		newOpts.Year = opts.Year
+		newOpts.NumYears = opts.NumYears
+		newOpts.IsHebrewYear = opts.IsHebrewYear
+		*opts = *newOpts
+		if extras != nil {
+			*extras = newExtras
+		}
+		return "", nil
+	}
+}
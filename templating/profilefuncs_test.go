@@ -0,0 +1,112 @@
+package templating_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/hcfiles"
+	"github.com/chaimleib/hebcalfmt/templating"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestUseProfile(t *testing.T) {
+	profiles := map[string]json.RawMessage{
+		"israel":  json.RawMessage(`{"city": "Jerusalem", "il": true}`),
+		"phoenix": json.RawMessage(`{"city": "Phoenix", "candle_lighting_mins": 20}`),
+		"events":  json.RawMessage(`{"events_file": "events.txt"}`),
+	}
+	files := fstest.MapFS{
+		"events.txt": &fstest.MapFile{Data: []byte("every Friday Oneg Shabbat\n")},
+	}
+
+	cases := []struct {
+		Name      string
+		Profile   string
+		Extra     string // added to the profiles under the name "extra"
+		WantCity  string
+		WantIL    bool
+		WantMins  int
+		WantRules int
+		Err       string
+	}{
+		{Name: "israel", Profile: "israel", WantCity: "Jerusalem", WantIL: true, WantMins: 18},
+		{Name: "phoenix", Profile: "phoenix", WantCity: "Phoenix", WantMins: 20},
+		{Name: "events", Profile: "events", WantCity: "New York", WantMins: 18, WantRules: 1},
+		{Name: "unknown", Profile: "INVALID", Err: `unknown profile: "INVALID"`},
+		{
+			Name:    "extends unknown",
+			Profile: "extra",
+			Extra:   `{"extends": "INVALID"}`,
+			Err:     `profile "extra" extends unknown profile: "INVALID"`,
+		},
+		{
+			Name:    "invalid city",
+			Profile: "extra",
+			Extra:   `{"city": "INVALID"}`,
+			Err:     `failed to resolve place configs: unknown city: "INVALID"`,
+		},
+		{
+			Name:    "invalid language",
+			Profile: "extra",
+			Extra:   `{"language": "INVALID"}`,
+			Err:     `unknown language: "INVALID"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.Logger(t)
+			cfg := config.Default
+			cfg.Now = time.Date(2025, time.December, 14, 0, 0, 0, 0, time.UTC)
+			cfg.Profiles = maps.Clone(profiles)
+			cfg.FS = files
+			if c.Extra != "" {
+				cfg.Profiles["extra"] = json.RawMessage(c.Extra)
+			}
+			opts, err := cfg.CalOptions()
+			test.CheckErr(t, err, "")
+			templating.SetYear(opts)(5786)
+			origCity := opts.Location.Name
+
+			var extras hcfiles.Extras
+			got, err := templating.UseProfile(&cfg, opts, &extras)(c.Profile)
+			test.CheckErr(t, err, c.Err)
+			test.CheckComparable[any](t, "result", "", got)
+			if err != nil {
+				test.CheckString(t, "unchanged city", origCity, opts.Location.Name)
+				return
+			}
+			test.CheckString(t, "city", c.WantCity, opts.Location.Name)
+			test.CheckComparable(t, "IL", c.WantIL, opts.IL)
+			test.CheckComparable(t, "CandleLightingMins", c.WantMins, opts.CandleLightingMins)
+			test.CheckComparable(t, "Year", 5786, opts.Year)
+			test.CheckComparable(t, "len(extras.Rules)", c.WantRules, len(extras.Rules))
+		})
+	}
+}
+
+func TestUseProfile_template(t *testing.T) {
+	cfg := config.Default
+	cfg.Now = time.Date(2025, time.December, 14, 0, 0, 0, 0, time.UTC)
+	cfg.Profiles = map[string]json.RawMessage{
+		"phoenix": json.RawMessage(`{"city": "Phoenix"}`),
+	}
+	files := fstest.MapFS{
+		"profile.tmpl": &fstest.MapFile{Data: []byte(
+			`{{$.location.Name}} {{(forDate $.now).TimeZone}}` +
+				`{{useProfile "phoenix"}} {{$.location.Name}} {{(forDate $.now).TimeZone}}`,
+		)},
+	}
+
+	tmpl, data, err := templating.BuildData(&cfg, files, "profile.tmpl")
+	test.CheckErr(t, err, "")
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	test.CheckErr(t, err, "")
+	test.CheckString(t, "output",
+		"New York America/New_York New York America/Phoenix", buf.String())
+}
@@ -5,17 +5,23 @@ import (
 	"io"
 	"io/fs"
 	"maps"
+	"slices"
 	"text/template"
+	"time"
 
 	"github.com/hebcal/hebcal-go/hebcal"
 	"github.com/hebcal/hebcal-go/zmanim"
 
 	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/hcfiles"
 )
 
-// ParseFile opens fpath from the files given and parses it into the tmpl.
+// ParseFile opens fpath from the files given and parses it into the tmpl,
+// after the files it imports from imports; see [ImportFuncs].
+// If imports is nil, importing a file is an error.
 func ParseFile(
 	files fs.FS,
+	imports fs.FS,
 	tmpl *template.Template,
 	fpath string,
 ) (*template.Template, error) {
@@ -29,9 +35,26 @@ func ParseFile(
 	if err != nil {
 		return nil, err
 	}
+	return ParseText(imports, tmpl, string(buf))
+}
 
-	tmpl, err = tmpl.Parse(string(buf))
-	return tmpl, err
+// ParseText parses text into the tmpl, like [ParseFile],
+// for templates which are not in a file.
+func ParseText(
+	imports fs.FS,
+	tmpl *template.Template,
+	text string,
+) (*template.Template, error) {
+	im := importer{
+		imports: imports,
+		tmpl:    tmpl,
+		loaded:  make(map[string]bool),
+		chain:   []string{tmpl.Name()},
+	}
+	if err := im.parse(tmpl.Name(), text); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
 }
 
 // SetFuncMap loads the hebcalfmt templating functions into tmpl's FuncMap.
@@ -40,21 +63,89 @@ func SetFuncMap(
 		Funcs(template.FuncMap) *template.Template
 	},
 	opts *hebcal.CalOptions,
+	extras *hcfiles.Extras,
 ) *template.Template {
 	funcs := make(map[string]any)
 	maps.Insert(funcs, maps.All(CalOptionsFuncs(opts)))
-	maps.Insert(funcs, maps.All(HebcalFuncs(opts)))
+	maps.Insert(funcs, maps.All(HebcalFuncs(opts, extras)))
 	maps.Insert(funcs, maps.All(ZmanimFuncs(opts)))
 	maps.Insert(funcs, maps.All(HDateFuncs))
+	maps.Insert(funcs, maps.All(AnniversaryFuncs))
 	maps.Insert(funcs, maps.All(SedraFuncs))
 	maps.Insert(funcs, maps.All(StringFuncs))
 	maps.Insert(funcs, maps.All(TimeFuncs))
 	maps.Insert(funcs, maps.All(CastFuncs))
 	maps.Insert(funcs, maps.All(EnvFuncs))
 	maps.Insert(funcs, maps.All(CollectionFuncs))
+	maps.Insert(funcs, maps.All(ImportFuncs))
 	return tmpl.Funcs(funcs)
 }
 
+// builtinFuncs stands in for the functions predefined by [text/template],
+// with their signatures, for [Lint].
+// The functions themselves are nil.
+var builtinFuncs = template.FuncMap{
+	"and":      (func(any, ...any) any)(nil),
+	"call":     (func(any, ...any) (any, error))(nil),
+	"eq":       (func(any, ...any) (bool, error))(nil),
+	"ge":       (func(any, any) (bool, error))(nil),
+	"gt":       (func(any, any) (bool, error))(nil),
+	"html":     (func(...any) string)(nil),
+	"index":    (func(any, ...any) (any, error))(nil),
+	"js":       (func(...any) string)(nil),
+	"le":       (func(any, any) (bool, error))(nil),
+	"len":      (func(any) (int, error))(nil),
+	"lt":       (func(any, any) (bool, error))(nil),
+	"ne":       (func(any, any) (bool, error))(nil),
+	"not":      (func(any) bool)(nil),
+	"or":       (func(any, ...any) any)(nil),
+	"print":    (func(...any) string)(nil),
+	"printf":   (func(string, ...any) string)(nil),
+	"println":  (func(...any) string)(nil),
+	"slice":    (func(any, ...any) (any, error))(nil),
+	"urlquery": (func(...any) string)(nil),
+}
+
+// funcRecorder collects the functions passed to Funcs.
+type funcRecorder struct {
+	funcs template.FuncMap
+}
+
+func (r *funcRecorder) Funcs(funcs template.FuncMap) *template.Template {
+	maps.Copy(r.funcs, funcs)
+	return nil
+}
+
+// Funcs returns the functions which [BuildData] adds to templates,
+// built for the default options.
+// Their results depend on the config, but their signatures do not.
+func Funcs() template.FuncMap {
+	r := funcRecorder{funcs: make(template.FuncMap)}
+	var opts hebcal.CalOptions
+	var extras hcfiles.Extras
+	SetFuncMap(&r, &opts, &extras)
+	r.Funcs(ICSFuncs(&opts, time.Time{}))
+	r.Funcs(ProfileFuncs(new(config.Config), &opts, &extras))
+	return r.funcs
+}
+
+// FuncNames returns the names of the functions available to templates
+// from [BuildData], including the builtins of [text/template], sorted.
+func FuncNames() []string {
+	names := slices.Collect(maps.Keys(Funcs()))
+	names = slices.AppendSeq(names, maps.Keys(builtinFuncs))
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// DataKeys are the keys of the data from [BuildData], sorted.
+// See BuildData for what they hold.
+var DataKeys = []string{
+	"calOptions", "config", "configSource", "dateRange", "event", "hdate",
+	"language", "location", "now", "nowInLocation", "params", "sedra",
+	"time", "tz", "z",
+}
+
 // BuildData loads tmplPath from the files and configures it.
 // It returns the tmpl and the data on which it should be executed.
 //
@@ -65,7 +156,8 @@ func SetFuncMap(
 //
 //  2. Builds the FuncMap and adds it to the template.
 //
-//  3. Parses the template.
+//  3. Parses the template, and the files it imports
+//     from the [NewSearchPath].
 //
 //  4. Sets the template ParseName (for runtime debugging messages).
 //
@@ -79,8 +171,15 @@ func SetFuncMap(
 //     This is controlled via the JSON config file, CLI arguments,
 //     and certain config-altering functions which can be called
 //     from the template itself.
-//   - `$.configSource` - the name of the JSON config file,
+//   - `$.config` - the effective [config.Config],
+//     after the config layers, CLI overrides and defaults have been merged.
+//   - `$.configSource` - the [config.Provenance] of `$.config`.
+//     This prints as the names of the config layers which were applied,
 //     or else the empty string if the compiled default config was used.
+//     `{{$.configSource.Of "city"}}` names the layer which set a key.
+//   - `$.params` - the values of the parameters declared
+//     in the [FrontMatter] of the template, converted to their types.
+//     These come from `cfg.Params`, or else from the declared defaults.
 //   - `$.language` - the name of the language to be used.
 //   - `$.dateRange` - the [daterange.DateRange] implied or specified
 //     by the command line arguments.
@@ -100,7 +199,25 @@ func BuildData(
 	files fs.FS,
 	tmplPath string,
 ) (*template.Template, map[string]any, error) {
-	opts, err := cfg.CalOptions()
+	text, err := fs.ReadFile(files, tmplPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return BuildDataText(cfg, files, tmplPath, string(text))
+}
+
+// BuildDataText is like [BuildData],
+// but takes the text of the template instead of reading it from the files,
+// for templates given inline or on stdin.
+// tmplPath names the template in messages,
+// and files it imports are still looked for next to tmplPath in files.
+func BuildDataText(
+	cfg *config.Config,
+	files fs.FS,
+	tmplPath string,
+	text string,
+) (*template.Template, map[string]any, error) {
+	opts, extras, err := cfg.CalOptionsWithExtras()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to build hebcal options from %s: %w",
 			cfg.ConfigSource, err)
@@ -111,18 +228,33 @@ func BuildData(
 	// Set up the Template's FuncMap.
 	// This must be done before parsing the file.
 	tmpl := template.New(tmplPath)
-	tmpl = SetFuncMap(tmpl, opts)
+	tmpl = SetFuncMap(tmpl, opts, &extras)
+	tmpl = tmpl.Funcs(ICSFuncs(opts, cfg.Now))
+	tmpl = tmpl.Funcs(ProfileFuncs(cfg, opts, &extras))
 
-	tmpl, err = ParseFile(files, tmpl, tmplPath)
+	imports := NewSearchPath(cfg, files, tmplPath)
+	tmpl, err = ParseText(imports, tmpl, text)
 	if err != nil {
 		return nil, nil, err
 	}
 	tmpl.ParseName = tmplPath
+
+	fm, err := ParseFrontMatter([]byte(text), tmplPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	params, err := fm.ParamValues(cfg.Params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", tmplPath, err)
+	}
+
 	return tmpl, map[string]any{
 		"now":           cfg.Now,
 		"nowInLocation": cfg.Now.In(z.TimeZone),
 		"calOptions":    opts,
+		"config":        cfg,
 		"configSource":  cfg.ConfigSource,
+		"params":        params,
 		"language":      cfg.Language,
 		"dateRange":     cfg.DateRange,
 		"tz":            z.TimeZone,
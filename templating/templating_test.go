@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"io/fs"
+	"maps"
+	"slices"
 	"testing"
 	"testing/fstest"
 	"text/template"
@@ -71,7 +73,7 @@ func TestParseFile(t *testing.T) {
 			}
 
 			tmpl := template.New(c.Name)
-			tmpl, err := templating.ParseFile(files, tmpl, c.Name)
+			tmpl, err := templating.ParseFile(files, nil, tmpl, c.Name)
 			test.CheckErr(t, err, c.Err)
 		})
 	}
@@ -90,7 +92,7 @@ func TestSetFuncMap(t *testing.T) {
 	var mt MockTemplate
 	var opts hebcal.CalOptions
 
-	tmpl := templating.SetFuncMap(&mt, &opts)
+	tmpl := templating.SetFuncMap(&mt, &opts, nil)
 
 	if tmpl == nil {
 		t.Error("expected a non-nil tmpl")
@@ -105,6 +107,10 @@ func TestBuildData(t *testing.T) {
 		"stub.tmpl":      &fstest.MapFile{Data: []byte("hi")},
 		"invalid.tmpl":   &fstest.MapFile{Data: []byte("{{INVALID")},
 		"readError.tmpl": &fstest.MapFile{},
+		"params.tmpl": &fstest.MapFile{Data: []byte(
+			"{{- /*---\nparams:\n  - name: city\n    type: city\n    default: Phoenix\n" +
+				"  - name: count\n    type: int\n---*/ -}}\n" +
+				"{{$.params.city.Name}} {{with $.params.count}}{{printf \"%03d\" .}}{{else}}none{{end}}")},
 	}
 	cases := []struct {
 		Name     string
@@ -124,12 +130,31 @@ func TestBuildData(t *testing.T) {
 		{
 			Name: "stub.tmpl with invalid config",
 			Cfg: &config.Config{
-				ConfigSource: "test struct",
+				ConfigSource: config.Provenance{Layers: []string{"test struct"}},
 				City:         "Invalid City",
 			},
 			TmplPath: "stub.tmpl",
 			Err:      `failed to build hebcal options from test struct: failed to resolve place configs: unknown city: "Invalid City"`,
 		},
+		{Name: "params.tmpl defaults", TmplPath: "params.tmpl", WantOut: "Phoenix none"},
+		{
+			Name:     "params.tmpl with values",
+			Cfg:      &config.Config{Params: map[string]string{"city": "Jerusalem", "count": "4"}},
+			TmplPath: "params.tmpl",
+			WantOut:  "Jerusalem 004",
+		},
+		{
+			Name:     "params.tmpl with invalid value",
+			Cfg:      &config.Config{Params: map[string]string{"count": "four"}},
+			TmplPath: "params.tmpl",
+			Err:      `params.tmpl: invalid value for parameter count: strconv.Atoi: parsing "four": invalid syntax`,
+		},
+		{
+			Name:     "stub.tmpl with unknown param",
+			Cfg:      &config.Config{Params: map[string]string{"city": "Jerusalem"}},
+			TmplPath: "stub.tmpl",
+			Err:      "stub.tmpl: unknown parameter: city",
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
@@ -159,3 +184,46 @@ func TestBuildData(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildDataText(t *testing.T) {
+	files := fstest.MapFS{
+		"lib.tmpl": &fstest.MapFile{Data: []byte(`{{define "greet"}}Shalom, {{.}}{{end}}`)},
+	}
+	cases := []struct {
+		Name string
+		Text string
+		Want string
+		Err  string
+	}{
+		{Name: "text", Text: "hi", Want: "hi"},
+		{Name: "import", Text: `{{import "lib.tmpl"}}{{template "greet" "world"}}`, Want: "Shalom, world"},
+		{
+			Name: "params",
+			Text: "{{- /*---\nparams:\n  - name: n\n    type: int\n    default: 7\n---*/ -}}\n{{$.params.n}}",
+			Want: "7",
+		},
+		{Name: "invalid", Text: "{{INVALID", Err: `template: <expr>:1: function "INVALID" not defined`},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			test.Logger(t)
+			tmpl, data, err := templating.BuildDataText(new(config.Config), files, "<expr>", c.Text)
+			test.CheckErr(t, err, c.Err)
+			if c.Err != "" {
+				return
+			}
+
+			var buf bytes.Buffer
+			err = tmpl.Execute(&buf, data)
+			test.CheckErr(t, err, "")
+			test.CheckString(t, "output", c.Want, buf.String())
+		})
+	}
+}
+
+func TestDataKeys(t *testing.T) {
+	test.Logger(t)
+	_, data, err := templating.BuildDataText(new(config.Config), fstest.MapFS{}, "<expr>", "")
+	test.CheckErr(t, err, "")
+	test.CheckSlice(t, "DataKeys", slices.Sorted(maps.Keys(data)), templating.DataKeys)
+}
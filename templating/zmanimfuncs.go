@@ -20,7 +20,11 @@ func ZmanimFuncs(opts *hebcal.CalOptions) map[string]any {
 		"newLocation": zmanim.NewLocation,
 
 		// zmanim.Zmanim
-		"forDate":         ForDate(opts.Location),
+		// forDate looks up opts.Location on each call,
+		// in case useProfile has switched it.
+		"forDate": func(d time.Time) (*zmanim.Zmanim, error) {
+			return ForDate(opts.Location)(d)
+		},
 		"forLocationDate": ForLocationDate,
 
 		// molad
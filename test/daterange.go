@@ -74,4 +74,20 @@ func CheckDateRange(t Test, name string, want, got daterange.DateRange) {
 				name, field.Name, field.Want, field.Got)
 		}
 	}
+
+	// Check the ends of spans.
+	for _, field := range []struct {
+		Name      string
+		Want, Got *daterange.DateRange
+	}{
+		{"From", want.From, got.From},
+		{"Until", want.Until, got.Until},
+	} {
+		if (field.Want == nil) != (field.Got == nil) {
+			t.Errorf("%s.%s's nilness did not match - want: %v, got: %v",
+				name, field.Name, field.Want, field.Got)
+		} else if field.Want != nil {
+			CheckDateRange(t, name+"."+field.Name, *field.Want, *field.Got)
+		}
+	}
 }
@@ -215,6 +215,25 @@ func TestCheckDateRange(t *testing.T) {
 			Failed: true,
 			Logs:   "Source.IsHebrewDate's do not match - want: true, got: false\n",
 		},
+		{
+			Name:      "from same",
+			WantInput: daterange.DateRange{From: &daterange.DateRange{Day: 1}},
+			GotInput:  daterange.DateRange{From: &daterange.DateRange{Day: 1}},
+		},
+		{
+			Name:      "from different",
+			WantInput: daterange.DateRange{From: &daterange.DateRange{Day: 1}},
+			GotInput:  daterange.DateRange{From: &daterange.DateRange{Day: 2}},
+			Failed:    true,
+			Logs:      "DateRange.From.Day's did not match - want: 1, got: 2\n",
+		},
+		{
+			Name:      "until nilness different",
+			WantInput: daterange.DateRange{Until: &daterange.DateRange{}},
+			GotInput:  daterange.DateRange{},
+			Failed:    true,
+			Logs:      "DateRange.Until's nilness did not match - want: DateRange<empty>, got: <nil>\n",
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.Name, func(t *testing.T) {
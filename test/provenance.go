@@ -0,0 +1,15 @@
+package test
+
+import "github.com/chaimleib/hebcalfmt/config"
+
+// CheckProvenance compares the Layers and Keys of two [config.Provenance]s.
+func CheckProvenance(
+	t Test,
+	name string,
+	want, got config.Provenance,
+) {
+	t.Helper()
+
+	CheckSlice(t, name+".Layers", want.Layers, got.Layers)
+	CheckMap(t, name+".Keys", want.Keys, got.Keys)
+}
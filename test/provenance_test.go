@@ -0,0 +1,52 @@
+package test_test
+
+import (
+	"testing"
+
+	"github.com/chaimleib/hebcalfmt/config"
+	"github.com/chaimleib/hebcalfmt/test"
+)
+
+func TestCheckProvenance(t *testing.T) {
+	cases := []struct {
+		Name                string
+		WantInput, GotInput config.Provenance
+		Failed              bool
+	}{
+		{Name: "empties"},
+		{
+			Name: "same",
+			WantInput: config.Provenance{
+				Layers: []string{"a.json"},
+				Keys:   map[string]string{"city": "a.json"},
+			},
+			GotInput: config.Provenance{
+				Layers: []string{"a.json"},
+				Keys:   map[string]string{"city": "a.json"},
+			},
+		},
+		{
+			Name:      "different layers",
+			WantInput: config.Provenance{Layers: []string{"a.json"}},
+			GotInput:  config.Provenance{Layers: []string{"b.json"}},
+			Failed:    true,
+		},
+		{
+			Name:      "different keys",
+			WantInput: config.Provenance{Keys: map[string]string{"city": "env"}},
+			GotInput:  config.Provenance{Keys: map[string]string{"city": "flags"}},
+			Failed:    true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			mockT := NewMockT(t)
+			test.CheckProvenance(mockT, "ConfigSource", c.WantInput, c.GotInput)
+
+			if c.Failed != mockT.Failed() {
+				t.Errorf("c.Failed is %v, but t.Failed() is %v\nlogs:\n%s",
+					c.Failed, mockT.Failed(), mockT.buf.String())
+			}
+		})
+	}
+}
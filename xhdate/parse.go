@@ -0,0 +1,232 @@
+package xhdate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hebcal/hdate"
+)
+
+// monthNames maps normalized month names to months.
+// Keys are lowercase, with spaces, hyphens, periods and quote marks removed.
+// See [normalizeMonth].
+var monthNames = map[string]hdate.HMonth{
+	"nisan": hdate.Nisan, "nissan": hdate.Nisan,
+	"iyyar": hdate.Iyyar, "iyar": hdate.Iyyar,
+	"sivan": hdate.Sivan, "siwan": hdate.Sivan,
+	"tamuz": hdate.Tamuz, "tammuz": hdate.Tamuz,
+	"av": hdate.Av, "ab": hdate.Av, "menachemav": hdate.Av, "menahemav": hdate.Av,
+	"elul":    hdate.Elul,
+	"tishrei": hdate.Tishrei, "tishri": hdate.Tishrei,
+	"cheshvan": hdate.Cheshvan, "heshvan": hdate.Cheshvan,
+	"marcheshvan": hdate.Cheshvan, "marheshvan": hdate.Cheshvan,
+	"kislev": hdate.Kislev, "kislew": hdate.Kislev,
+	"chislev": hdate.Kislev, "kisleiv": hdate.Kislev,
+	"tevet": hdate.Tevet, "teves": hdate.Tevet, "teiveis": hdate.Tevet,
+	"teveth": hdate.Tevet, "tebet": hdate.Tevet, "tebeth": hdate.Tevet,
+	"shvat": hdate.Shvat, "shevat": hdate.Shvat, "shebat": hdate.Shvat,
+	// A bare Adar is assumed to be Adar II, like [hdate.MonthFromName].
+	"adar":  hdate.Adar2,
+	"adar1": hdate.Adar1, "adari": hdate.Adar1, "adara": hdate.Adar1,
+	"adarrishon": hdate.Adar1, "adaraleph": hdate.Adar1, "adaralef": hdate.Adar1,
+	"adar2": hdate.Adar2, "adarii": hdate.Adar2, "adarb": hdate.Adar2,
+	"adarbet": hdate.Adar2, "adarbeis": hdate.Adar2, "adarbeit": hdate.Adar2,
+	"adarsheni": hdate.Adar2, "adarsheini": hdate.Adar2,
+
+	"ניסן": hdate.Nisan,
+	"אייר": hdate.Iyyar, "איר": hdate.Iyyar,
+	"סיון": hdate.Sivan, "סיוון": hdate.Sivan,
+	"תמוז": hdate.Tamuz,
+	"אב":   hdate.Av, "מנחםאב": hdate.Av,
+	"אלול": hdate.Elul,
+	"תשרי": hdate.Tishrei,
+	"חשון": hdate.Cheshvan, "חשוון": hdate.Cheshvan,
+	"מרחשון": hdate.Cheshvan, "מרחשוון": hdate.Cheshvan,
+	"כסלו": hdate.Kislev, "כסליו": hdate.Kislev,
+	"טבת":  hdate.Tevet,
+	"שבט":  hdate.Shvat,
+	"אדר":  hdate.Adar2,
+	"אדרא": hdate.Adar1, "אדר1": hdate.Adar1, "אדרראשון": hdate.Adar1,
+	"אדרב": hdate.Adar2, "אדר2": hdate.Adar2, "אדרשני": hdate.Adar2,
+}
+
+// normalizeMonth lowercases a month name
+// and removes spaces, hyphens, periods and quote marks,
+// so that "Adar II", "adar-ii" and "AdarII" all compare equal,
+// as do "אדר ב׳" and "אדר ב".
+func normalizeMonth(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.', '\'', '"', '’', '”', '׳', '״':
+			return -1
+		}
+		return r
+	}, strings.ToLower(s))
+}
+
+// ParseMonth parses the name of a Hebrew month.
+//
+// Transliterations are accepted in their common spellings,
+// like "Shevat", "Shvat", "Teves", "Iyar" or "Marcheshvan",
+// and other spellings and abbreviations are passed to [hdate.MonthFromName].
+// Hebrew-script names are accepted with or without the prefix ב,
+// like "בשבט" or "אדר ב׳".
+// Adar I and II may be written as "Adar I", "Adar 1", "Adar א׳" and so on.
+// A bare "Adar" is taken to be Adar II,
+// which callers should correct to Adar I in non-leap years.
+func ParseMonth(s string) (hdate.HMonth, error) {
+	if m, ok := lookupMonth(s); ok {
+		return m, nil
+	}
+
+	// hdate.MonthFromName is lax about Latin spellings and abbreviations,
+	// like "Kisleiv" or "Ti".
+	if norm := normalizeMonth(s); !HasHebrewLetters(norm) {
+		if m, err := hdate.MonthFromName(norm); err == nil {
+			return m, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown Hebrew month: %q", s)
+}
+
+// lookupMonth is the strict part of [ParseMonth].
+// It only accepts the spellings in monthNames.
+func lookupMonth(s string) (hdate.HMonth, bool) {
+	norm := normalizeMonth(s)
+	if m, ok := monthNames[norm]; ok {
+		return m, true
+	}
+
+	// In Hebrew, "in Shevat" is commonly written attached, as in ט״ו בשבט.
+	if rest, ok := strings.CutPrefix(norm, "ב"); ok {
+		if m, ok := monthNames[rest]; ok {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// gematriyaValues maps each Hebrew letter, including final forms,
+// to its numeric value.
+var gematriyaValues = map[rune]int{
+	'א': 1, 'ב': 2, 'ג': 3, 'ד': 4, 'ה': 5, 'ו': 6, 'ז': 7, 'ח': 8, 'ט': 9,
+	'י': 10, 'כ': 20, 'ך': 20, 'ל': 30, 'מ': 40, 'ם': 40, 'נ': 50, 'ן': 50,
+	'ס': 60, 'ע': 70, 'פ': 80, 'ף': 80, 'צ': 90, 'ץ': 90,
+	'ק': 100, 'ר': 200, 'ש': 300, 'ת': 400,
+}
+
+// isGeresh reports whether r is a geresh or gershayim,
+// or one of the ASCII or typographic quotes commonly typed in their place.
+func isGeresh(r rune) bool {
+	switch r {
+	case '׳', '״', '\'', '"', '’', '”':
+		return true
+	}
+	return false
+}
+
+// HasHebrewLetters reports whether s contains any letter of the Hebrew alphabet.
+func HasHebrewLetters(s string) bool {
+	return strings.ContainsFunc(s, func(r rune) bool {
+		return r >= 'א' && r <= 'ת'
+	})
+}
+
+// ParseGematriya parses a number written in Hebrew letters,
+// like "ט״ו" or "תשפ\"ו".
+// Geresh and gershayim marks are optional,
+// and may be typed as ASCII quotes.
+// A leading letter followed by a geresh counts thousands,
+// so "ה׳תשפ״ו" is 5786.
+func ParseGematriya(s string) (int, error) {
+	runes := []rune(s)
+	var total, letters int
+	for i, r := range runes {
+		if isGeresh(r) {
+			continue
+		}
+		v, ok := gematriyaValues[r]
+		if !ok {
+			return 0, fmt.Errorf("invalid gematriya %q: unexpected %q", s, r)
+		}
+		letters++
+		thousands := i+2 < len(runes) &&
+			(runes[i+1] == '׳' || runes[i+1] == '\'')
+		if letters == 1 && thousands {
+			v *= 1000
+		}
+		total += v
+	}
+	if letters == 0 {
+		return 0, fmt.Errorf("invalid gematriya %q: no Hebrew letters", s)
+	}
+	return total, nil
+}
+
+// ParseNumber parses a number written either in digits or in gematriya.
+// See [ParseGematriya].
+func ParseNumber(s string) (int, error) {
+	if HasHebrewLetters(s) {
+		return ParseGematriya(s)
+	}
+	return strconv.Atoi(s)
+}
+
+// ParseYear parses a Hebrew year written either in digits or in gematriya.
+// A gematriya year with the thousands omitted, like "תשפ״ו",
+// is taken to be in the sixth millennium, so that "תשפ״ו" is 5786.
+func ParseYear(s string) (int, error) {
+	year, err := ParseNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	if year < 1000 && HasHebrewLetters(s) {
+		year += 5000
+	}
+	return year, nil
+}
+
+// IsMonthName reports whether s is the name of a Hebrew month
+// in one of the spellings known to [ParseMonth], not counting the
+// abbreviations and other spellings passed to [hdate.MonthFromName].
+func IsMonthName(s string) bool {
+	_, ok := lookupMonth(s)
+	return ok
+}
+
+// SplitDayMonth divides the words of a Hebrew date, without its year,
+// into the day and the month.
+// The day may come either first, as in "15 Shevat" or "ט״ו בשבט",
+// or last, as in "Shevat 15".
+// The month may span several words, as in "Adar II".
+//
+// Words which spell out a month, as judged by [IsMonthName], decide the order.
+// Failing that, whichever end is a number is taken as the day,
+// so that errors are reported against the word that was meant as the month.
+func SplitDayMonth(words []string) (day, month string) {
+	if len(words) == 0 {
+		return "", ""
+	}
+	last := len(words) - 1
+	dayFirst := func() (string, string) {
+		return words[0], strings.Join(words[1:], " ")
+	}
+	dayLast := func() (string, string) {
+		return words[last], strings.Join(words[:last], " ")
+	}
+
+	switch {
+	case len(words) == 1:
+		return dayLast()
+	case IsMonthName(strings.Join(words[1:], " ")):
+		return dayFirst()
+	case IsMonthName(strings.Join(words[:last], " ")):
+		return dayLast()
+	}
+	if _, err := ParseNumber(words[0]); err == nil {
+		return dayFirst()
+	}
+	return dayLast()
+}
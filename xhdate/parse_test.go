@@ -0,0 +1,212 @@
+package xhdate_test
+
+import (
+	"testing"
+
+	"github.com/hebcal/hdate"
+
+	"github.com/chaimleib/hebcalfmt/test"
+	"github.com/chaimleib/hebcalfmt/xhdate"
+)
+
+func TestParseMonth(t *testing.T) {
+	cases := []struct {
+		Input string
+		Want  hdate.HMonth
+		Err   string
+	}{
+		{Input: "Nisan", Want: hdate.Nisan},
+		{Input: "Nissan", Want: hdate.Nisan},
+		{Input: "Iyar", Want: hdate.Iyyar},
+		{Input: "Menachem Av", Want: hdate.Av},
+		{Input: "Tishri", Want: hdate.Tishrei},
+		{Input: "Ti", Want: hdate.Tishrei},
+		{Input: "Heshvan", Want: hdate.Cheshvan},
+		{Input: "Mar-Cheshvan", Want: hdate.Cheshvan},
+		{Input: "Kisleiv", Want: hdate.Kislev},
+		{Input: "Teves", Want: hdate.Tevet},
+		{Input: "Sh'vat", Want: hdate.Shvat},
+		{Input: "Shevat", Want: hdate.Shvat},
+		{Input: "Adar", Want: hdate.Adar2},
+		{Input: "Adar I", Want: hdate.Adar1},
+		{Input: "adar-1", Want: hdate.Adar1},
+		{Input: "Adar Sheni", Want: hdate.Adar2},
+		{Input: "ניסן", Want: hdate.Nisan},
+		{Input: "אייר", Want: hdate.Iyyar},
+		{Input: "סיוון", Want: hdate.Sivan},
+		{Input: "מנחם אב", Want: hdate.Av},
+		{Input: "מרחשוון", Want: hdate.Cheshvan},
+		{Input: "כסליו", Want: hdate.Kislev},
+		{Input: "שבט", Want: hdate.Shvat},
+		{Input: "בשבט", Want: hdate.Shvat},
+		{Input: "אדר", Want: hdate.Adar2},
+		{Input: "אדר א׳", Want: hdate.Adar1},
+		{Input: "באדר ב", Want: hdate.Adar2},
+		{Input: `אדר ב"`, Want: hdate.Adar2},
+		{Input: "אדר ראשון", Want: hdate.Adar1},
+
+		{Input: "", Err: `unknown Hebrew month: ""`},
+		{Input: "November", Err: `unknown Hebrew month: "November"`},
+		{Input: "בשבע", Err: `unknown Hebrew month: "בשבע"`},
+		{Input: "ב", Err: `unknown Hebrew month: "ב"`},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			got, err := xhdate.ParseMonth(c.Input)
+			test.CheckErr(t, err, c.Err)
+			test.CheckComparable(t, "month", c.Want, got)
+		})
+	}
+}
+
+func TestIsMonthName(t *testing.T) {
+	cases := []struct {
+		Input string
+		Want  bool
+	}{
+		{"Shevat", true},
+		{"בשבט", true},
+		{"Adar II", true},
+		{"Ti", false}, // abbreviations only count for ParseMonth
+		{"15", false},
+		{"II 14", false},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			test.CheckComparable(t, "IsMonthName", c.Want, xhdate.IsMonthName(c.Input))
+		})
+	}
+}
+
+func TestParseGematriya(t *testing.T) {
+	cases := []struct {
+		Input string
+		Want  int
+		Err   string
+	}{
+		{Input: "א", Want: 1},
+		{Input: "ט״ו", Want: 15},
+		{Input: `ט"ז`, Want: 16},
+		{Input: "טו", Want: 15},
+		{Input: "ל׳", Want: 30},
+		{Input: "ל'", Want: 30},
+		{Input: "תשפ״ו", Want: 786},
+		{Input: "ה׳תשפ״ו", Want: 5786},
+		{Input: `ה'תשפ"ו`, Want: 5786},
+		{Input: "תתקע״ה", Want: 975},
+		{Input: "ךםןףץ", Want: 280},
+
+		{Input: "", Err: `invalid gematriya "": no Hebrew letters`},
+		{Input: "״", Err: `invalid gematriya "״": no Hebrew letters`},
+		{Input: "ט5", Err: `invalid gematriya "ט5": unexpected '5'`},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			got, err := xhdate.ParseGematriya(c.Input)
+			test.CheckErr(t, err, c.Err)
+			test.CheckComparable(t, "number", c.Want, got)
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	cases := []struct {
+		Input string
+		Want  int
+		Err   string
+	}{
+		{Input: "15", Want: 15},
+		{Input: "ט״ו", Want: 15},
+		{Input: "x", Err: `strconv.Atoi: parsing "x": invalid syntax`},
+		{Input: "טx", Err: `invalid gematriya "טx": unexpected 'x'`},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			got, err := xhdate.ParseNumber(c.Input)
+			test.CheckErr(t, err, c.Err)
+			test.CheckComparable(t, "number", c.Want, got)
+		})
+	}
+}
+
+func TestParseYear(t *testing.T) {
+	cases := []struct {
+		Input string
+		Want  int
+		Err   string
+	}{
+		{Input: "5786", Want: 5786},
+		{Input: "786", Want: 786},
+		{Input: "תשפ״ו", Want: 5786},
+		{Input: "ה׳תשפ״ו", Want: 5786},
+		{Input: "ד׳תשפ״ו", Want: 4786},
+		{Input: "year", Err: `strconv.Atoi: parsing "year": invalid syntax`},
+	}
+	for _, c := range cases {
+		t.Run(c.Input, func(t *testing.T) {
+			got, err := xhdate.ParseYear(c.Input)
+			test.CheckErr(t, err, c.Err)
+			test.CheckComparable(t, "year", c.Want, got)
+		})
+	}
+}
+
+func TestSplitDayMonth(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Input     []string
+		WantDay   string
+		WantMonth string
+	}{
+		{Name: "empty"},
+		{Name: "one word", Input: []string{"15"}, WantDay: "15"},
+		{
+			Name:    "day first",
+			Input:   []string{"15", "Shevat"},
+			WantDay: "15", WantMonth: "Shevat",
+		},
+		{
+			Name:    "day last",
+			Input:   []string{"Shevat", "15"},
+			WantDay: "15", WantMonth: "Shevat",
+		},
+		{
+			Name:    "gematriya day first",
+			Input:   []string{"ג", "אב"},
+			WantDay: "ג", WantMonth: "אב",
+		},
+		{
+			Name:    "gematriya day last",
+			Input:   []string{"אב", "ג"},
+			WantDay: "ג", WantMonth: "אב",
+		},
+		{
+			Name:    "two-word month first",
+			Input:   []string{"Adar", "II", "14"},
+			WantDay: "14", WantMonth: "Adar II",
+		},
+		{
+			Name:    "abbreviated month",
+			Input:   []string{"1", "Ti"},
+			WantDay: "1", WantMonth: "Ti",
+		},
+		{
+			Name:    "unknown month",
+			Input:   []string{"bad", "month", "16"},
+			WantDay: "16", WantMonth: "bad month",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			day, month := xhdate.SplitDayMonth(c.Input)
+			test.CheckString(t, "day", c.WantDay, day)
+			test.CheckString(t, "month", c.WantMonth, month)
+		})
+	}
+}
+
+func TestHasHebrewLetters(t *testing.T) {
+	test.CheckComparable(t, "Shevat", false, xhdate.HasHebrewLetters("Shevat"))
+	test.CheckComparable(t, "״", false, xhdate.HasHebrewLetters("״"))
+	test.CheckComparable(t, "שבט", true, xhdate.HasHebrewLetters("שבט"))
+}
@@ -4,7 +4,6 @@ package xhdate
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/hebcal/hdate"
@@ -20,7 +19,10 @@ func Equal(a, b hdate.HDate) bool {
 }
 
 // Parse parses a string in DD MMMM YYYY or MMMM DD YYYY format into an HDate.
-// MMMM is the name of the Hebrew month, like "Adar II", "Adar 2", or "Tishrei".
+// MMMM is the name of the Hebrew month, like "Adar II", "Adar 2", "Tishrei",
+// or "בשבט"; see [ParseMonth] for the accepted spellings.
+// DD and YYYY may be written in digits or in gematriya,
+// so that "ט״ו בשבט תשפ״ו" and "15 Shevat 5786" are the same date.
 func Parse(s string) (hdate.HDate, error) {
 	var rv hdate.HDate
 
@@ -33,28 +35,20 @@ func Parse(s string) (hdate.HDate, error) {
 		return rv, fmt.Errorf("too few words in a Hebrew date: %q", s)
 	}
 
-	year, err := strconv.Atoi(parts[length-1])
+	year, err := ParseYear(parts[length-1])
 	if err != nil {
-		return rv, fmt.
-			Errorf("could not parse last word of Hebrew date as year: %q", s)
+		return rv, fmt.Errorf("invalid year in Hebrew date %q: %w", s, err)
 	}
 
-	var monthParts []string
-	day, err := strconv.Atoi(parts[0])
+	dayStr, monthStr := SplitDayMonth(parts[:length-1])
+	day, err := ParseNumber(dayStr)
 	if err != nil {
-		day, err = strconv.Atoi(parts[length-2])
-		if err != nil {
-			return rv, fmt.Errorf("could not parse day from Hebrew date: %q", s)
-		}
-		monthParts = parts[0 : length-2]
-	} else {
-		monthParts = parts[1 : length-1]
+		return rv, fmt.Errorf("invalid day in Hebrew date %q: %w", s, err)
 	}
 
-	monthStr := strings.Join(monthParts, " ")
-	month, err := hdate.MonthFromName(monthStr)
+	month, err := ParseMonth(monthStr)
 	if err != nil {
-		return rv, fmt.Errorf("failed to parse month from Hebrew date: %q", s)
+		return rv, fmt.Errorf("invalid month in Hebrew date %q: %w", s, err)
 	}
 
 	if day < 1 || day > hdate.DaysInMonth(month, year) {
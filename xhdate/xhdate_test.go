@@ -83,6 +83,16 @@ func TestParse(t *testing.T) {
 		{Input: "Adar 2 14 5787", Want: purimLeap},
 		{Input: "14 Adar II 5787", Want: purimLeap},
 		{Input: "Adar II 14 5787", Want: purimLeap},
+		{Input: "15 Shevat 5786", Want: hdate.New(5786, hdate.Shvat, 15)},
+		{Input: "ט״ו בשבט תשפ״ו", Want: hdate.New(5786, hdate.Shvat, 15)},
+		{Input: `ט"ו בשבט ה'תשפ"ו`, Want: hdate.New(5786, hdate.Shvat, 15)},
+		{Input: "טו שבט 5786", Want: hdate.New(5786, hdate.Shvat, 15)},
+		{Input: "שבט ט״ו תשפ״ו", Want: hdate.New(5786, hdate.Shvat, 15)},
+		{Input: "ג אב תשפ״ו", Want: hdate.New(5786, hdate.Av, 3)},
+		{Input: "אב ג תשפ״ו", Want: hdate.New(5786, hdate.Av, 3)},
+		{Input: "י״ד אדר ב׳ תשפ״ז", Want: purimLeap},
+		{Input: "י״ד באדר תשפ״ה", Want: purim},
+		{Input: "1 Heshvan 5786", Want: hdate.New(5786, hdate.Cheshvan, 1)},
 
 		{Input: "bad date", Err: `too few words in a Hebrew date: "bad date"`},
 		{
@@ -91,15 +101,27 @@ func TestParse(t *testing.T) {
 		},
 		{
 			Input: "bad month 16 5789",
-			Err:   `failed to parse month from Hebrew date: "bad month 16 5789"`,
+			Err:   `invalid month in Hebrew date "bad month 16 5789": unknown Hebrew month: "bad month"`,
 		},
 		{
 			Input: "Tishrei 1 badyear",
-			Err:   `could not parse last word of Hebrew date as year: "Tishrei 1 badyear"`,
+			Err:   `invalid year in Hebrew date "Tishrei 1 badyear": strconv.Atoi: parsing "badyear": invalid syntax`,
 		},
 		{
 			Input: "Av badday 5234",
-			Err:   `could not parse day from Hebrew date: "Av badday 5234"`,
+			Err:   `invalid day in Hebrew date "Av badday 5234": strconv.Atoi: parsing "badday": invalid syntax`,
+		},
+		{
+			Input: "טx בשבט תשפ״ו",
+			Err:   `invalid day in Hebrew date "טx בשבט תשפ״ו": invalid gematriya "טx": unexpected 'x'`,
+		},
+		{
+			Input: "ט״ו בשבט תשפ״x",
+			Err:   `invalid year in Hebrew date "ט״ו בשבט תשפ״x": invalid gematriya "תשפ״x": unexpected 'x'`,
+		},
+		{
+			Input: "ט״ו בשבע תשפ״ו",
+			Err:   `invalid month in Hebrew date "ט״ו בשבע תשפ״ו": unknown Hebrew month: "בשבע"`,
 		},
 		{
 			Input: "5775 Tishrei 1",